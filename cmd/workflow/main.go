@@ -0,0 +1,100 @@
+// YAMLで定義したワークフローを実行し、最終出力をJSONで表示するコマンド
+//
+// 使い方:
+//
+//	workflow [--max-concurrent N] [--trace FILE] WORKFLOW.yaml
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime/trace"
+
+	"github.com/momiom/workflow/registry"
+
+	"gopkg.in/yaml.v3"
+)
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "workflow:", err)
+		os.Exit(1)
+	}
+}
+
+// runはコマンドライン引数argsに従ってワークフローを実行し、最終出力をstdoutに書き出します。
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("workflow", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	maxConcurrent := flags.Int("max-concurrent", 0, "maximum number of nodes to run concurrently (overrides max_concurrent in the file)")
+	traceFile := flags.String("trace", "", "write a runtime trace to `file`")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("exactly one workflow file is required")
+	}
+
+	def, err := loadDefinition(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *maxConcurrent > 0 {
+		def.MaxConcurrent = *maxConcurrent
+	}
+
+	if *traceFile != "" {
+		f, err := os.Create(*traceFile)
+		if err != nil {
+			return fmt.Errorf("failed to create trace file: %w", err)
+		}
+		defer f.Close()
+		if err := trace.Start(f); err != nil {
+			return fmt.Errorf("failed to start trace: %w", err)
+		}
+		defer trace.Stop()
+	}
+
+	workflow, err := registry.Default().Build(def)
+	if err != nil {
+		return err
+	}
+	// 実行の監視は不要なので、状態と入出力のイベントは読み捨てる
+	events := workflow.GetEvents()
+	go func() {
+		for range events {
+		}
+	}()
+
+	_, finalOutputs, err := workflow.Execute(ctx, def.DAGInputs())
+	if err != nil {
+		return fmt.Errorf("failed to execute workflow: %w", err)
+	}
+
+	enc := json.NewEncoder(stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(finalOutputs)
+}
+
+// loadDefinitionはYAMLファイルからワークフローの定義を読み込みます。
+func loadDefinition(path string) (*registry.Definition, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open workflow file: %w", err)
+	}
+	defer f.Close()
+
+	var def registry.Definition
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&def); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow file %s: %w", path, err)
+	}
+	return &def, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tracePath := filepath.Join(t.TempDir(), "trace.out")
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"--max-concurrent", "1", "--trace", tracePath, "testdata/sample.yaml"}
+	if err := run(context.Background(), args, &stdout, &stderr); err != nil {
+		t.Fatalf("unexpected error: %v (stderr: %s)", err, stderr.String())
+	}
+
+	var finalOutputs map[string][]string
+	if err := json.Unmarshal(stdout.Bytes(), &finalOutputs); err != nil {
+		t.Fatalf("failed to decode stdout %q: %v", stdout.String(), err)
+	}
+	if len(finalOutputs) != 1 || !slices.Equal(finalOutputs["first"], []string{"hello world"}) {
+		t.Fatalf("expected {first: [hello world]}, got %v", finalOutputs)
+	}
+
+	if info, err := os.Stat(tracePath); err != nil || info.Size() == 0 {
+		t.Fatalf("expected trace file to be written, got %v", err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"No workflow file", nil},
+		{"Missing file", []string{filepath.Join(dir, "missing.yaml")}},
+		{"Unknown node type", []string{write("unknown.yaml", "nodes:\n  - id: a\n    type: nope\n")}},
+		{"Unknown field", []string{write("field.yaml", "nodez: []\n")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if err := run(context.Background(), tt.args, &stdout, &stderr); err == nil {
+				t.Fatalf("expected error, got nil (stdout: %s)", stdout.String())
+			}
+		})
+	}
+}
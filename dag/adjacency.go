@@ -0,0 +1,48 @@
+package dag
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/momiom/workflow/node"
+
+	"gonum.org/v1/gonum/graph/topo"
+)
+
+// FromAdjacencyは後続ノードの隣接リストとノードのファクトリーからDAGを構築します。
+// adjのキーが全ノードの集合で、値はそのノードの後続ノードです。後続ノードを持たないノードも
+// 空のスライスでキーに含める必要があり、キーにないノードを参照するエッジはエラーになります。
+// ノードとエッジはNodeIDの昇順に追加されるため、入力の連結順序は決定的です。
+// 同時実行数の上限はノード数となり、optsで他の設定を追加できます。
+func FromAdjacency(adj map[NodeID][]NodeID, factory func(NodeID) (node.Node, error), opts ...Option) (*DAG, error) {
+	ids := make([]NodeID, 0, len(adj))
+	for id := range adj {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	dag := NewDAG(max(len(ids), 1), opts...)
+	for _, id := range ids {
+		n, err := factory(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create node %s: %w", id, err)
+		}
+		dag.AddNode(id, n)
+	}
+
+	for _, from := range ids {
+		for _, to := range adj[from] {
+			if _, ok := adj[to]; !ok {
+				return nil, fmt.Errorf("edge %s -> %s references undefined node %s", from, to, to)
+			}
+			if err := dag.AddEdge(from, to); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if _, err := topo.Sort(dag.graph); err != nil {
+		return nil, fmt.Errorf("graph is not acyclic: %w", err)
+	}
+	return dag, nil
+}
@@ -0,0 +1,91 @@
+package dag_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestFromAdjacency(t *testing.T) {
+	// ノードIDと入力を組み合わせた文字列を出力するノードを生成する
+	factory := func(id dag.NodeID) (node.Node, error) {
+		return node.NewTextNode(string(id), func(inputs []string) (string, error) {
+			return fmt.Sprintf("%s(%s)", id, strings.Join(inputs, " ")), nil
+		}), nil
+	}
+
+	tests := []struct {
+		name                 string
+		adj                  map[dag.NodeID][]dag.NodeID
+		expectedFinalOutputs map[dag.NodeID][]string
+		expectError          bool
+	}{
+		{
+			name: "Diamond",
+			adj: map[dag.NodeID][]dag.NodeID{
+				"a": {"b", "c"},
+				"b": {"d"},
+				"c": {"d"},
+				"d": {},
+			},
+			expectedFinalOutputs: map[dag.NodeID][]string{
+				"d": {"d(b(a(x)) c(a(x)))"},
+			},
+		},
+		{
+			name: "Undefined node",
+			adj: map[dag.NodeID][]dag.NodeID{
+				"a": {"b"},
+			},
+			expectError: true,
+		},
+		{
+			name: "Cycle",
+			adj: map[dag.NodeID][]dag.NodeID{
+				"a": {"b"},
+				"b": {"a"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow, err := dag.FromAdjacency(tt.adj, factory)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("expected error: %v, got: %v", tt.expectError, err)
+			}
+			if tt.expectError {
+				return
+			}
+
+			drainChannels(workflow)
+			_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"a": {"x"}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(finalOutputs) != len(tt.expectedFinalOutputs) {
+				t.Fatalf("expected %v, got %v", tt.expectedFinalOutputs, finalOutputs)
+			}
+			for id, expected := range tt.expectedFinalOutputs {
+				if got := finalOutputs[id]; !slices.Equal(got, expected) {
+					t.Fatalf("expected %v for node %s, got %v", expected, id, got)
+				}
+			}
+		})
+	}
+}
+
+func TestFromAdjacencyFactoryError(t *testing.T) {
+	factory := func(id dag.NodeID) (node.Node, error) {
+		return nil, fmt.Errorf("unknown node %s", id)
+	}
+	if _, err := dag.FromAdjacency(map[dag.NodeID][]dag.NodeID{"a": {}}, factory); err == nil {
+		t.Fatalf("expected factory error")
+	}
+}
@@ -0,0 +1,145 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/momiom/workflow/node"
+)
+
+// BuilderはノードとエッジをメソッドチェーンでまとめてDAGに追加するビルダーです。
+// 途中で発生したエラーは蓄積され、Buildでまとめて返されます。
+//
+//	workflow, err := dag.NewBuilder(2).
+//		Node("a", a).
+//		Node("b", b).
+//		Edge("a", "b").
+//		Build()
+type Builder struct {
+	dag  *DAG
+	errs []error
+}
+
+// NewBuilderは新しいBuilderを作成します。引数はNewDAGと同じです。
+func NewBuilder(maxConcurrent int, opts ...Option) *Builder {
+	return &Builder{dag: NewDAG(maxConcurrent, opts...)}
+}
+
+// Nodeはノードを追加します。同じIDのノードを既に追加していた場合はエラーとなります。
+func (b *Builder) Node(id NodeID, n node.Node) *Builder {
+	if _, exists := b.dag.nodes[id]; exists {
+		b.errs = append(b.errs, fmt.Errorf("node %s already exists", id))
+		return b
+	}
+	b.dag.AddNode(id, n)
+	return b
+}
+
+// Edgeはエッジを追加します。存在しないノードを指定した場合などはエラーとなります。
+func (b *Builder) Edge(from NodeID, to NodeID) *Builder {
+	if err := b.dag.AddEdge(from, to); err != nil {
+		b.errs = append(b.errs, err)
+	}
+	return b
+}
+
+// Buildは構築したDAGを返します。
+// 追加の途中でエラーが発生していた場合や、グラフが循環している場合は、全てのエラーをまとめて返します。
+func (b *Builder) Build() (*DAG, error) {
+	errs := b.errs
+	if _, err := b.dag.plan(); err != nil {
+		errs = append(errs, fmt.Errorf("graph has a cycle: %w", err))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return b.dag, nil
+}
+
+// 複数のノードをまとめてDAGに追加するメソッド
+// AddNodeと異なり、既に存在するIDのノードは置き換えずにエラーとします。
+// いずれかのノードを追加できない場合は全てのエラーをまとめて返し、どのノードも追加しません。
+func (dag *DAG) AddNodes(nodes map[NodeID]node.Node) error {
+	ids := make([]NodeID, 0, len(nodes))
+	var errs []error
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	// エラーの順序と追加の順序を決定的にする
+	slices.Sort(ids)
+	for _, id := range ids {
+		if _, exists := dag.nodes[id]; exists {
+			errs = append(errs, fmt.Errorf("node %s already exists", id))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		dag.AddNode(id, nodes[id])
+	}
+	return nil
+}
+
+// 複数のエッジをまとめてDAGに追加するメソッド
+// 各エッジは{依存元, 依存先}の組で指定し、指定した順に追加します。
+// いずれかのエッジを追加できない場合や、追加するとグラフが循環する場合は全てのエラーをまとめて返し、どのエッジも追加しません。
+func (dag *DAG) AddEdges(edges [][2]NodeID) error {
+	var errs []error
+	batch := make([]edgeKey, 0, len(edges))
+	for _, edge := range edges {
+		key := edgeKey{from: edge[0], to: edge[1]}
+		if err := dag.checkEdge(key.from, key.to); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if slices.Contains(batch, key) {
+			errs = append(errs, fmt.Errorf("edge %s -> %s already exists", key.from, key.to))
+			continue
+		}
+		batch = append(batch, key)
+	}
+	if len(errs) == 0 && dag.createsCycle(batch) {
+		errs = append(errs, errors.New("edges would create a cycle"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	for _, edge := range batch {
+		if err := dag.AddEdge(edge.from, edge.to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createsCycleは既存のエッジにextraを加えたグラフが循環するかを返すメソッド
+// 入力次数が0のノードから順に取り除き、取り除けないノードが残る場合は循環しています。
+func (dag *DAG) createsCycle(extra []edgeKey) bool {
+	successors := make(map[NodeID][]NodeID, len(dag.nodes))
+	inDegree := make(map[NodeID]int, len(dag.nodes))
+	for _, edge := range append(dag.sortedEdges(), extra...) {
+		successors[edge.from] = append(successors[edge.from], edge.to)
+		inDegree[edge.to]++
+	}
+	var queue []NodeID
+	for id := range dag.nodes {
+		if inDegree[id] == 0 {
+			queue = append(queue, id)
+		}
+	}
+	removed := 0
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		removed++
+		for _, to := range successors[id] {
+			inDegree[to]--
+			if inDegree[to] == 0 {
+				queue = append(queue, to)
+			}
+		}
+	}
+	return removed < len(dag.nodes)
+}
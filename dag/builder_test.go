@@ -0,0 +1,145 @@
+package dag_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestBuilder(t *testing.T) {
+	join := func(id string) node.Node {
+		return node.NewTextNode(id, func(inputs []string) (string, error) {
+			return strings.Join(inputs, "+") + ">" + id, nil
+		})
+	}
+
+	t.Run("Diamond", func(t *testing.T) {
+		workflow, err := dag.NewBuilder(2).
+			Node("a", join("a")).
+			Node("b", join("b")).
+			Node("c", join("c")).
+			Node("d", join("d")).
+			Edge("a", "b").
+			Edge("a", "c").
+			Edge("b", "d").
+			Edge("c", "d").
+			Build()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		drainChannels(workflow)
+
+		_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"a": {"in"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := []string{"in>a>b+in>a>c>d"}
+		if !slices.Equal(finalOutputs["d"], expected) {
+			t.Fatalf("expected %v, got %v", expected, finalOutputs["d"])
+		}
+	})
+
+	t.Run("Errors are reported by Build", func(t *testing.T) {
+		_, err := dag.NewBuilder(2).
+			Node("a", join("a")).
+			Node("b", join("b")).
+			Node("a", join("a")).
+			Edge("a", "b").
+			Edge("b", "a").
+			Edge("a", "missing").
+			Build()
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		for _, want := range []string{"node a already exists", "node missing does not exist", "graph has a cycle"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Fatalf("expected error to contain %q, got %v", want, err)
+			}
+		}
+	})
+}
+
+func TestDAGAddNodes(t *testing.T) {
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("a", node.NewIdentityNode("a"))
+
+	// 1つでも既存のIDがあれば、どのノードも追加しない
+	err := workflow.AddNodes(map[dag.NodeID]node.Node{
+		"a": node.NewIdentityNode("a"),
+		"b": node.NewIdentityNode("b"),
+	})
+	if err == nil || err.Error() != "node a already exists" {
+		t.Fatalf("expected an error for node a, got %v", err)
+	}
+	if _, ok := workflow.GetNode("b"); ok {
+		t.Fatal("expected no nodes to be added on error")
+	}
+
+	if err := workflow.AddNodes(map[dag.NodeID]node.Node{
+		"b": node.NewIdentityNode("b"),
+		"c": node.NewIdentityNode("c"),
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, id := range []dag.NodeID{"b", "c"} {
+		if _, ok := workflow.GetNode(id); !ok {
+			t.Fatalf("expected node %s to be added", id)
+		}
+	}
+}
+
+func TestDAGAddEdges(t *testing.T) {
+	tests := []struct {
+		name          string
+		edges         [][2]dag.NodeID
+		expectedError string
+		expected      [][2]dag.NodeID
+	}{
+		{
+			name:     "Diamond",
+			edges:    [][2]dag.NodeID{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}},
+			expected: [][2]dag.NodeID{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}},
+		},
+		{
+			name:          "Reports all invalid edges",
+			edges:         [][2]dag.NodeID{{"a", "b"}, {"a", "x"}, {"c", "c"}, {"a", "b"}},
+			expectedError: "node x does not exist\nedge c -> c is a self loop\nedge a -> b already exists",
+		},
+		{
+			name:          "Cycle",
+			edges:         [][2]dag.NodeID{{"a", "b"}, {"b", "c"}, {"c", "a"}},
+			expectedError: "edges would create a cycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := dag.NewDAG(1)
+			for _, id := range []dag.NodeID{"a", "b", "c", "d"} {
+				workflow.AddNode(id, node.NewIdentityNode(string(id)))
+			}
+
+			err := workflow.AddEdges(tt.edges)
+			if tt.expectedError != "" {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Fatalf("expected %q, got %v", tt.expectedError, err)
+				}
+				// エラーの場合はどのエッジも追加しない
+				if edges := workflow.Edges(); len(edges) != 0 {
+					t.Fatalf("expected no edges to be added on error, got %v", edges)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if edges := workflow.Edges(); !slices.Equal(edges, tt.expected) {
+				t.Fatalf("expected edges %v, got %v", tt.expected, edges)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package dag
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/momiom/workflow/node"
+)
+
+// Cacheはノードの出力をノードIDと入力から算出したキーで保存するキャッシュです。
+// 複数のノードから並行して呼び出されるため、実装は並行に安全である必要があります。
+type Cache interface {
+	Get(key string) ([]string, bool)
+	Set(key string, outputs []string)
+}
+
+// WithCacheはノードの出力をcacheに保存し、同じノードに同じ入力が与えられた場合は
+// ノードを実行せずに保存された出力を使用するよう設定します。キャッシュの出力を使用したノードの状態はCachedとなります。
+// LLMの呼び出しのように高価で、入力が同じなら同じ出力を返してよいノードに適しています。
+// キーごとの出力や名前付きの出力を返すノード（node.KeyedOutputer、node.NamedOutputer）はキャッシュされません。
+func WithCache(cache Cache) Option {
+	return func(dag *DAG) {
+		dag.cache = cache
+	}
+}
+
+// ノードの出力をキャッシュするキーを返すメソッド
+// キャッシュが設定されていないか、ノードがキャッシュできない場合はfalseを返します。
+func (dag *DAG) cacheKey(id NodeID, n node.Node, inputs []string) (string, bool) {
+	if dag.cache == nil {
+		return "", false
+	}
+	switch n.(type) {
+	case node.KeyedOutputer, node.NamedOutputer:
+		return "", false
+	}
+	h := sha256.New()
+	writeLenPrefixed(h, string(id))
+	fmt.Fprintf(h, "%d;", len(inputs))
+	for _, input := range inputs {
+		writeLenPrefixed(h, input)
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// LRUCacheは保存できる件数に上限のあるメモリ上のキャッシュです。
+// 上限を超えると最も長く使われていないエントリを削除します。
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // 最近使われた順のエントリ（先頭が最新）
+	entries  map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	outputs []string
+}
+
+// NewLRUCacheは最大capacity件を保存するLRUCacheを作成します。1未満の値は1として扱います。
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Getはkeyに対応する出力を返し、そのエントリを最近使われたものとします。
+func (c *LRUCache) Get(key string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).outputs, true
+}
+
+// Setはkeyに対応する出力を保存します。上限を超えた場合は最も長く使われていないエントリを削除します。
+func (c *LRUCache) Set(key string, outputs []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*lruEntry).outputs = outputs
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, outputs: outputs})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Lenは保存されているエントリの数を返します。
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,73 @@
+package dag_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGWithCache(t *testing.T) {
+	var count atomic.Int32
+	workflow := dag.NewDAG(1, dag.WithCache(dag.NewLRUCache(10)))
+	workflow.AddNode("llm", node.NewTextNode("llm", func(inputs []string) (string, error) {
+		count.Add(1)
+		return strings.ToUpper(strings.Join(inputs, " ")), nil
+	}))
+
+	runs := []struct {
+		input         string
+		expectedCount int32
+		expectedState dag.NodeStatus
+	}{
+		{"hello", 1, dag.Completed},
+		{"hello", 1, dag.Cached},
+		{"goodbye", 2, dag.Completed},
+	}
+
+	for i, run := range runs {
+		statuses := recordStatuses(workflow)
+		_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"llm": {run.input}})
+		if err != nil {
+			t.Fatalf("run %d: unexpected error: %v", i, err)
+		}
+		expected := []string{strings.ToUpper(run.input)}
+		if !slices.Equal(finalOutputs["llm"], expected) {
+			t.Fatalf("run %d: expected %v, got %v", i, expected, finalOutputs["llm"])
+		}
+		if got := count.Load(); got != run.expectedCount {
+			t.Fatalf("run %d: expected %d executions, got %d", i, run.expectedCount, got)
+		}
+		if got := statuses()["llm"]; got != run.expectedState {
+			t.Fatalf("run %d: expected %s, got %s", i, run.expectedState, got)
+		}
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	cache := dag.NewLRUCache(2)
+	cache.Set("a", []string{"1"})
+	cache.Set("b", []string{"2"})
+
+	// aを参照したため、次に追加するとbが削除される
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	cache.Set("c", []string{"3"})
+
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Fatalf("expected %s to be cached", key)
+		}
+	}
+	if cache.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", cache.Len())
+	}
+}
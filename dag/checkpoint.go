@@ -0,0 +1,81 @@
+package dag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// checkpointは完了したノードの出力と状態を保存したものです。
+type checkpoint struct {
+	Outputs      map[NodeID][]string            `json:"outputs"`
+	KeyedOutputs map[NodeID]map[string][]string `json:"keyed_outputs,omitempty"`
+	Status       map[NodeID]NodeStatus          `json:"status"`
+}
+
+// completedはチェックポイントで完了していたノードをID順に返します。
+func (cp *checkpoint) completed() []NodeID {
+	var ids []NodeID
+	for id, status := range cp.Status {
+		if status == Completed || status == Cached {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// 実行の終了時にノードの出力を記録するメソッド
+func (dag *DAG) recordOutputs(outputs map[NodeID][]string, keyedOutputs map[NodeID]map[string][]string) {
+	dag.statusMu.Lock()
+	defer dag.statusMu.Unlock()
+	dag.lastOutputs = maps.Clone(outputs)
+	dag.lastKeyed = maps.Clone(keyedOutputs)
+}
+
+// 直近の実行で完了したノードの出力と状態をwに書き出すメソッド
+// 実行がエラーで終了した場合も、それまでに完了したノードが保存されます。
+// 保存した状態はResumeFromに渡して実行を再開できます。
+func (dag *DAG) SaveState(w io.Writer) error {
+	dag.statusMu.Lock()
+	cp := checkpoint{
+		Outputs:      make(map[NodeID][]string),
+		KeyedOutputs: make(map[NodeID]map[string][]string),
+		Status:       make(map[NodeID]NodeStatus),
+	}
+	for id, status := range dag.nodeStatus {
+		if status != Completed && status != Cached {
+			continue
+		}
+		cp.Status[id] = status
+		cp.Outputs[id] = dag.lastOutputs[id]
+		if keyed, ok := dag.lastKeyed[id]; ok {
+			cp.KeyedOutputs[id] = keyed
+		}
+	}
+	dag.statusMu.Unlock()
+
+	if err := json.NewEncoder(w).Encode(cp); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return nil
+}
+
+// SaveStateで保存した状態から実行を再開するメソッド
+// 保存時に完了していたノードは実行せずに保存された出力を依存先ノードに渡し、状態をCachedとします。
+// 残りのノードはRunと同様に実行されます。
+func (dag *DAG) ResumeFrom(ctx context.Context, r io.Reader, inputs map[NodeID][]string) (*ExecuteResult, error) {
+	var cp checkpoint
+	if err := json.NewDecoder(r).Decode(&cp); err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+	for id := range cp.Status {
+		if _, ok := dag.nodeMap[id]; !ok {
+			return nil, fmt.Errorf("node %s in saved state does not exist", id)
+		}
+	}
+	return dag.run(ctx, inputs, &cp)
+}
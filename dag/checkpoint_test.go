@@ -0,0 +1,82 @@
+package dag_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGResumeFrom(t *testing.T) {
+	var counts [4]atomic.Int32
+	var failC atomic.Bool
+	failC.Store(true)
+
+	workflow := dag.NewDAG(1)
+	for i, id := range []dag.NodeID{"a", "b", "c", "d"} {
+		workflow.AddNode(id, node.NewTextNode(string(id), func(inputs []string) (string, error) {
+			counts[i].Add(1)
+			if id == "c" && failC.Load() {
+				return "", errors.New("c is temporarily unavailable")
+			}
+			return strings.Join(inputs, " ") + " " + string(id), nil
+		}))
+	}
+	for _, edge := range [][]dag.NodeID{{"a", "b"}, {"b", "c"}, {"c", "d"}} {
+		if err := workflow.AddEdge(edge[0], edge[1]); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+	}
+	inputs := map[dag.NodeID][]string{"a": {"start"}}
+
+	// cが失敗するため、チェーンの前半だけが完了する
+	drainChannels(workflow)
+	if _, err := workflow.Run(context.Background(), inputs); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	var state bytes.Buffer
+	if err := workflow.SaveState(&state); err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+
+	// 再開時は完了済みのaとbを実行せず、cとdだけを実行する
+	failC.Store(false)
+	statuses := recordStatuses(workflow)
+	result, err := workflow.ResumeFrom(context.Background(), &state, inputs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, expected := range []int32{1, 1, 2, 1} {
+		if got := counts[i].Load(); got != expected {
+			t.Fatalf("node %d: expected %d executions, got %d", i, expected, got)
+		}
+	}
+	got := statuses()
+	for id, expected := range map[dag.NodeID]dag.NodeStatus{"a": dag.Cached, "b": dag.Cached, "c": dag.Completed, "d": dag.Completed} {
+		if got[id] != expected {
+			t.Fatalf("node %s: expected %s, got %s", id, expected, got[id])
+		}
+	}
+	if final := result.FinalOutputs["d"]; len(final) != 1 || final[0] != "start a b c d" {
+		t.Fatalf("expected [start a b c d], got %v", final)
+	}
+}
+
+func TestDAGResumeFromUnknownNode(t *testing.T) {
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("a", node.NewTextNode("a", func(inputs []string) (string, error) {
+		return "a", nil
+	}))
+
+	state := strings.NewReader(`{"outputs":{"missing":["x"]},"status":{"missing":"Completed"}}`)
+	if _, err := workflow.ResumeFrom(context.Background(), state, nil); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,84 @@
+package dag
+
+import (
+	"maps"
+	"slices"
+
+	"golang.org/x/time/rate"
+
+	"github.com/momiom/workflow/node"
+)
+
+// DAGを複製するメソッド
+// グラフの構造と設定を複製し、状態とチャネルは新しく作成します。複製したDAGは元のDAGと並行して実行できます。
+// node.Clonerを実装するノードは複製し、実装していないノードは元のDAGと共有します。
+// 共有したノードは複数のDAGから同時に実行されるため、実行間で状態を持たずに並行して呼び出せる必要があります。
+// キャッシュとExecutorは元のDAGと共有し、レートリミッターは同じ設定で新しく作成します。
+// 実行中に呼び出すことはできません。
+func (dag *DAG) Clone() *DAG {
+	dag.log().Debug("Cloning DAG")
+
+	clone := NewDAG(dag.maxConcurrent)
+	for id, gn := range dag.nodes {
+		clone.graph.AddNode(gn)
+		clone.nodes[id] = gn
+		clone.graphIDs[gn.id] = id
+		clone.nodeMap[id] = cloneNode(dag.nodeMap[id])
+		clone.nodeStatus[id] = Pending
+	}
+	for edges := dag.graph.WeightedEdges(); edges.Next(); {
+		e := edges.WeightedEdge()
+		clone.graph.SetWeightedEdge(clone.graph.NewWeightedEdge(e.From(), e.To(), e.Weight()))
+	}
+	clone.nextGraphID = dag.nextGraphID
+	clone.inDegree = maps.Clone(dag.inDegree)
+	for id, preds := range dag.predecessors {
+		clone.predecessors[id] = slices.Clone(preds)
+	}
+	clone.edgeOutputs = maps.Clone(dag.edgeOutputs)
+	clone.edgeTransforms = maps.Clone(dag.edgeTransforms)
+	clone.edgeSelections = maps.Clone(dag.edgeSelections)
+	clone.optionalEdges = maps.Clone(dag.optionalEdges)
+	clone.failureEdges = maps.Clone(dag.failureEdges)
+	clone.nodeGroups = maps.Clone(dag.nodeGroups)
+	clone.groupLimits = maps.Clone(dag.groupLimits)
+	clone.priorities = maps.Clone(dag.priorities)
+	clone.taggedInputs = maps.Clone(dag.taggedInputs)
+	clone.dedupInputs = maps.Clone(dag.dedupInputs)
+	clone.skipOnEmpty = maps.Clone(dag.skipOnEmpty)
+	clone.constantInputs = maps.Clone(dag.constantInputs)
+	clone.errorHandlers = maps.Clone(dag.errorHandlers)
+	clone.disabled = maps.Clone(dag.disabled)
+	clone.nodeLogLevels = maps.Clone(dag.nodeLogLevels)
+
+	clone.failFast = dag.failFast
+	clone.deterministic = dag.deterministic
+	clone.dropDisabled = dag.dropDisabled
+	clone.secrets = dag.secrets
+	clone.workflowTimeout = dag.workflowTimeout
+	clone.tracer = dag.tracer
+	clone.retryAttempts = dag.retryAttempts
+	clone.retryBackoff = dag.retryBackoff
+	if dag.rateLimiter != nil {
+		clone.rateLimiter = rate.NewLimiter(dag.rateLimiter.Limit(), dag.rateLimiter.Burst())
+	}
+	clone.cache = dag.cache
+	clone.storeFactory = dag.storeFactory
+	clone.executor = dag.executor
+	clone.maxTotalOutputBytes = dag.maxTotalOutputBytes
+	clone.logger = dag.logger
+	clone.rootInputProvider = dag.rootInputProvider
+	clone.lazyInputs = maps.Clone(dag.lazyInputs)
+	clone.stopCondition = dag.stopCondition
+	clone.finalAggregator = dag.finalAggregator
+	clone.middlewares = slices.Clone(dag.middlewares)
+	return clone
+}
+
+// cloneNodeはnode.Clonerを実装するノードを複製し、実装していないノードはそのまま返します。
+func cloneNode(n node.Node) node.Node {
+	if c, ok := n.(node.Cloner); ok {
+		return c.Clone()
+	}
+	return n
+}
@@ -0,0 +1,67 @@
+package dag_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGClone(t *testing.T) {
+	template := dag.NewDAG(2)
+	template.AddNode("upper", node.NewTextNode("upper", func(inputs []string) (string, error) {
+		return strings.ToUpper(strings.Join(inputs, " ")), nil
+	}))
+	template.AddNode("exclaim", node.NewTextNode("exclaim", func(inputs []string) (string, error) {
+		return inputs[0] + "!", nil
+	}))
+	if err := template.AddEdge("upper", "exclaim"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	inputs := []string{"hello", "goodbye", "thanks"}
+	clones := make([]*dag.DAG, len(inputs))
+	for i := range clones {
+		clones[i] = template.Clone()
+	}
+
+	// 複製したDAGは並行して実行しても互いの入出力に影響しない
+	var wg sync.WaitGroup
+	results := make([]map[dag.NodeID][]string, len(inputs))
+	errs := make([]error, len(inputs))
+	for i, workflow := range clones {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			drainChannels(workflow)
+			_, results[i], errs[i] = workflow.Execute(context.Background(), map[dag.NodeID][]string{"upper": {inputs[i]}})
+		}()
+	}
+	wg.Wait()
+
+	for i, input := range inputs {
+		if errs[i] != nil {
+			t.Fatalf("clone %d: unexpected error: %v", i, errs[i])
+		}
+		expected := []string{strings.ToUpper(input) + "!"}
+		if !slices.Equal(results[i]["exclaim"], expected) {
+			t.Fatalf("clone %d: expected %v, got %v", i, expected, results[i]["exclaim"])
+		}
+	}
+
+	// 複製後に元のDAGを変更しても複製には影響しない
+	template.AddNode("extra", node.NewIdentityNode("extra"))
+	if err := template.AddEdge("exclaim", "extra"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if _, ok := clones[0].GetNode("extra"); ok {
+		t.Fatal("expected clone to be unaffected by changes to the template")
+	}
+	if leaves := clones[0].GetLeafNodes(); !slices.Equal(leaves, []dag.NodeID{"exclaim"}) {
+		t.Fatalf("expected clone leaves [exclaim], got %v", leaves)
+	}
+}
@@ -0,0 +1,32 @@
+package dag
+
+import "slices"
+
+// constInputsはSetConstantInputsで設定したノードの前後に置く入力です。
+type constInputs struct {
+	before []string
+	after  []string
+}
+
+// ノードが常に受け取る固定の入力を設定するメソッド
+// beforeは収集した入力（外部入力と依存元ノードの出力）の前に、afterは後ろに置かれます。
+// LLMNodeへの指示文のように、依存元に関わらず同じ文字列を渡す場合に使用します。
+// 固定の入力は、依存元が出力を持たないノードのスキップやWithSkipOnEmptyInputの判定には含まれません。
+// beforeとafterの両方が空の場合は設定を削除します。
+func (dag *DAG) SetConstantInputs(id NodeID, before, after []string) {
+	if len(before) == 0 && len(after) == 0 {
+		delete(dag.constantInputs, id)
+		return
+	}
+	dag.constantInputs[id] = constInputs{before: slices.Clone(before), after: slices.Clone(after)}
+}
+
+// withConstantInputsはノードidの固定の入力をinputsの前後に置いた新しいスライスを返すメソッド
+// 固定の入力が設定されていない場合はinputsをそのまま返します。
+func (dag *DAG) withConstantInputs(id NodeID, inputs []string) []string {
+	c, ok := dag.constantInputs[id]
+	if !ok {
+		return inputs
+	}
+	return slices.Concat(c.before, inputs, c.after)
+}
@@ -0,0 +1,77 @@
+package dag_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGSetConstantInputs(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   []string
+		after    []string
+		expected []string
+	}{
+		{"Without constants", nil, nil, []string{"external", "upstream"}},
+		{"Prefix before predecessor outputs", []string{"Summarize the following:"}, nil, []string{"Summarize the following:", "external", "upstream"}},
+		{"Prefix and suffix", []string{"begin"}, []string{"end"}, []string{"begin", "external", "upstream", "end"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := dag.NewDAG(1)
+			workflow.AddNode("upstream", node.NewIdentityNode("upstream"))
+			workflow.AddNode("prompt", node.NewIdentityNode("prompt"))
+			if err := workflow.AddEdge("upstream", "prompt"); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+			workflow.SetConstantInputs("prompt", tt.before, tt.after)
+
+			drainChannels(workflow)
+			_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{
+				"upstream": {"upstream"},
+				"prompt":   {"external"},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := finalOutputs["prompt"]; !slices.Equal(got, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDAGValidateConstantInputs(t *testing.T) {
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("source", node.NewTextNode("source", func(inputs []string) (string, error) {
+		return "text", nil
+	}))
+	workflow.AddNode("llm", node.NewLLMNode("llm", &validateMockLLMClient{}))
+	if err := workflow.AddEdge("source", "llm"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if err := workflow.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 固定の入力も入力の数に含まれるため、依存元の出力と合わせて2つとなる
+	workflow.SetConstantInputs("llm", []string{"Summarize the following:"}, nil)
+	for name, validate := range map[string]func() error{
+		"Validate": workflow.Validate,
+		"DryRun": func() error {
+			_, err := workflow.DryRun(map[dag.NodeID][]string{"source": {"x"}})
+			return err
+		},
+	} {
+		var invalid *dag.InvalidNodeError
+		if err := validate(); !errors.As(err, &invalid) || invalid.ID != "llm" {
+			t.Fatalf("%s: expected InvalidNodeError for llm, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package dag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/momiom/workflow/node"
+)
+
+// ContentKeyerはノードの設定内容を表す文字列を返すインターフェースです。
+// ContentIDはノードがこのインターフェースを実装している場合、その値をIDの算出に含めます。
+type ContentKeyer interface {
+	ContentKey() string
+}
+
+// ContentIDはノードの型・名前・設定内容と依存元ノードのIDから決定的なNodeIDを算出します。
+// 構造が同一のノードには同一のIDが割り当てられるため、キャッシュや重複排除に利用できます。
+// 依存元の順序は入力の順序に影響するため、IDの算出にもその順序が含まれます。
+//
+// IDはSHA-256の先頭128ビットを16進数で表したもので、構造が異なるノード同士が衝突することは
+// 実用上ありません。一方で構造が同一のノードは意図的に同じIDとなるため、AddNodeで
+// 既存のIDを上書きしないよう、追加前に登録済みかどうかを呼び出し側で確認してください。
+func ContentID(n node.Node, preds ...NodeID) NodeID {
+	h := sha256.New()
+	writeLenPrefixed(h, fmt.Sprintf("%T", n))
+	writeLenPrefixed(h, n.Name())
+	if k, ok := n.(ContentKeyer); ok {
+		writeLenPrefixed(h, k.ContentKey())
+	}
+	for _, pred := range preds {
+		writeLenPrefixed(h, string(pred))
+	}
+	return NodeID(hex.EncodeToString(h.Sum(nil)[:16]))
+}
+
+// writeLenPrefixedはハッシュを算出する要素sを長さ付きでwに書き込む関数
+// 各要素を長さ付きで書き込み、区切りの曖昧さによる衝突を防ぐ
+func writeLenPrefixed(w io.Writer, s string) {
+	fmt.Fprintf(w, "%d:%s;", len(s), s)
+}
@@ -0,0 +1,56 @@
+package dag_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+type keyedNode struct {
+	*node.CollectNode
+	key string
+}
+
+func (n *keyedNode) ContentKey() string { return n.key }
+
+func TestContentID(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+
+	base := dag.ContentID(node.NewTextNode("text", join), "a", "b")
+
+	tests := []struct {
+		name       string
+		id         dag.NodeID
+		expectSame bool
+	}{
+		{"Structurally identical node", dag.ContentID(node.NewTextNode("text", join), "a", "b"), true},
+		{"Different name", dag.ContentID(node.NewTextNode("other", join), "a", "b"), false},
+		{"Different predecessors", dag.ContentID(node.NewTextNode("text", join), "a", "c"), false},
+		{"Different predecessor order", dag.ContentID(node.NewTextNode("text", join), "b", "a"), false},
+		{"Different type", dag.ContentID(node.NewCollectNode("text"), "a", "b"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.id == base) != tt.expectSame {
+				t.Fatalf("expected same: %v, base: %s, got: %s", tt.expectSame, base, tt.id)
+			}
+		})
+	}
+
+	t.Run("Content key", func(t *testing.T) {
+		a := dag.ContentID(&keyedNode{node.NewCollectNode("collect"), "v1"})
+		b := dag.ContentID(&keyedNode{node.NewCollectNode("collect"), "v1"})
+		c := dag.ContentID(&keyedNode{node.NewCollectNode("collect"), "v2"})
+		if a != b {
+			t.Fatalf("expected identical IDs for identical content keys, got %s and %s", a, b)
+		}
+		if a == c {
+			t.Fatalf("expected different IDs for different content keys, got %s", a)
+		}
+	})
+}
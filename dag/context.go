@@ -0,0 +1,67 @@
+package dag
+
+import "context"
+
+type previousResultKey struct{}
+
+type previousOutputKey struct{}
+
+type previousOutput struct {
+	outputs []string
+	ok      bool
+}
+
+// WithPreviousResultは前回の実行結果（ノードごとの出力）をコンテキストに設定します。
+// このコンテキストでExecuteを呼ぶと、各ノードはPreviousResultで自身の前回の出力を参照できます。
+func WithPreviousResult(ctx context.Context, outputs map[NodeID][]string) context.Context {
+	return context.WithValue(ctx, previousResultKey{}, outputs)
+}
+
+// PreviousResultは実行中のノードの前回の出力を返します。
+// 前回の実行結果が設定されていない場合や、前回そのノードが出力を持たなかった場合はfalseを返します。
+func PreviousResult(ctx context.Context) ([]string, bool) {
+	prev, ok := ctx.Value(previousOutputKey{}).(previousOutput)
+	if !ok {
+		return nil, false
+	}
+	return prev.outputs, prev.ok
+}
+
+// withNodePreviousOutputは前回の実行結果からノードidの出力を取り出し、ノード用のコンテキストに設定します。
+func withNodePreviousOutput(ctx context.Context, id NodeID) context.Context {
+	outputs, ok := ctx.Value(previousResultKey{}).(map[NodeID][]string)
+	if !ok {
+		return ctx
+	}
+	prev, exists := outputs[id]
+	return context.WithValue(ctx, previousOutputKey{}, previousOutput{outputs: prev, ok: exists})
+}
+
+type metadataKey struct{}
+
+// Metadataはリクエスト単位のメタデータ（トレースID、認証情報、ユーザー情報など）です。
+type Metadata map[string]any
+
+// WithMetadataはコンテキストにメタデータを追加します。
+// メタデータは全て1つのキーの下に保持されるため、ノード同士でコンテキストのキーが衝突することはありません。
+// 既存のメタデータは複製されるため、親のコンテキストから見える値は変わりません。
+//
+// 呼び出し側でWithMetadataを使って値を設定したコンテキストでExecuteを呼び、ノードの
+// Executeの中でMetadataFromを使って値を読み出します。キーには"trace_id"のように
+// 用途が分かる名前を使用してください。
+func WithMetadata(ctx context.Context, key string, val any) context.Context {
+	md := MetadataFrom(ctx)
+	md[key] = val
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// MetadataFromはコンテキストに設定されたメタデータの複製を返します。
+// メタデータが設定されていない場合は空のMetadataを返します。
+func MetadataFrom(ctx context.Context) Metadata {
+	md, _ := ctx.Value(metadataKey{}).(Metadata)
+	cloned := make(Metadata, len(md)+1)
+	for k, v := range md {
+		cloned[k] = v
+	}
+	return cloned
+}
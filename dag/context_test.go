@@ -0,0 +1,61 @@
+package dag_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+)
+
+// metadataNodeはコンテキストのメタデータから値を読み出して出力するノードです。
+type metadataNode struct {
+	key     string
+	outputs []string
+}
+
+func (n *metadataNode) Execute(ctx context.Context) error {
+	val, ok := dag.MetadataFrom(ctx)[n.key]
+	if !ok {
+		return fmt.Errorf("metadata %s is not set", n.key)
+	}
+	n.outputs = []string{fmt.Sprint(val)}
+	return nil
+}
+
+func (n *metadataNode) Name() string              { return "metadata" }
+func (n *metadataNode) SetInputs(inputs []string) {}
+func (n *metadataNode) GetOutputs() []string      { return n.outputs }
+func (n *metadataNode) Reset()                    { n.outputs = nil }
+
+func TestMetadata(t *testing.T) {
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("reader", &metadataNode{key: "trace_id"})
+
+	ctx := dag.WithMetadata(context.Background(), "trace_id", "abc123")
+	ctx = dag.WithMetadata(ctx, "user", "alice")
+
+	drainChannels(workflow)
+	_, finalOutputs, err := workflow.Execute(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := finalOutputs["reader"]; len(got) != 1 || got[0] != "abc123" {
+		t.Fatalf("expected [abc123], got %v", got)
+	}
+}
+
+func TestMetadataIsolation(t *testing.T) {
+	parent := dag.WithMetadata(context.Background(), "key", "parent")
+	child := dag.WithMetadata(parent, "key", "child")
+
+	if got := dag.MetadataFrom(parent)["key"]; got != "parent" {
+		t.Fatalf("expected parent metadata to be unchanged, got %v", got)
+	}
+	if got := dag.MetadataFrom(child)["key"]; got != "child" {
+		t.Fatalf("expected child metadata, got %v", got)
+	}
+	if md := dag.MetadataFrom(context.Background()); md == nil || len(md) != 0 {
+		t.Fatalf("expected empty metadata, got %v", md)
+	}
+}
@@ -0,0 +1,74 @@
+package dag
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// recordTimingはノードの状態の変化から実行に掛かった時間を記録するメソッド
+// Runningになってから、Completed、Error、Skipped、Cachedのいずれかになるまでを実行時間とします。
+// 呼び出し側でstatusMuを保持している必要があります。
+func (dag *DAG) recordTiming(id NodeID, status NodeStatus) {
+	switch status {
+	case Running:
+		dag.startTimes[id] = time.Now()
+	case Completed, Error, Skipped, Cached:
+		if start, ok := dag.startTimes[id]; ok {
+			dag.durations[id] = time.Since(start)
+			delete(dag.startTimes, id)
+		}
+	}
+}
+
+// 直近の実行で最も時間の掛かった依存関係の経路（クリティカルパス）と、その所要時間を返すメソッド
+// 経路の所要時間は経路上のノードの実行時間の合計です。実行されなかったノードの実行時間は0として扱い、
+// 任意のエッジは依存先ノードを待たせないため経路に含めません。
+// 実行全体を短縮するには、この経路上のノードを高速化する必要があります。
+// 実行前に呼び出した場合やグラフが循環している場合はnilと0を返します。
+func (dag *DAG) CriticalPath() ([]NodeID, time.Duration) {
+	order, err := dag.plan()
+	if err != nil {
+		return nil, 0
+	}
+
+	dag.statusMu.Lock()
+	durations := maps.Clone(dag.durations)
+	dag.statusMu.Unlock()
+	if len(durations) == 0 {
+		return nil, 0
+	}
+
+	// トポロジカル順に、各ノードで終わる経路の最長の所要時間と直前のノードを求める
+	total := make(map[NodeID]time.Duration, len(order))
+	prev := make(map[NodeID]NodeID, len(order))
+	var last NodeID
+	for _, id := range order {
+		var longest time.Duration
+		for _, fromID := range dag.predecessors[id] {
+			if dag.optionalEdges[edgeKey{from: fromID, to: id}] {
+				continue
+			}
+			if _, ok := prev[id]; !ok || total[fromID] > longest {
+				longest = total[fromID]
+				prev[id] = fromID
+			}
+		}
+		total[id] = longest + durations[id]
+		if last == "" || total[id] > total[last] {
+			last = id
+		}
+	}
+
+	var path []NodeID
+	for id := last; ; {
+		path = append(path, id)
+		fromID, ok := prev[id]
+		if !ok {
+			break
+		}
+		id = fromID
+	}
+	slices.Reverse(path)
+	return path, total[last]
+}
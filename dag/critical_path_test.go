@@ -0,0 +1,51 @@
+package dag_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGCriticalPath(t *testing.T) {
+	sleepy := func(name string, d time.Duration) node.Node {
+		return node.NewTextNode(name, func(inputs []string) (string, error) {
+			time.Sleep(d)
+			return strings.Join(inputs, " "), nil
+		})
+	}
+
+	// a -> b -> d と a -> c -> d のうち、bを経由する経路が遅い
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("a", sleepy("a", 10*time.Millisecond))
+	workflow.AddNode("b", sleepy("b", 80*time.Millisecond))
+	workflow.AddNode("c", sleepy("c", 10*time.Millisecond))
+	workflow.AddNode("d", sleepy("d", 10*time.Millisecond))
+	for _, edge := range [][2]dag.NodeID{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}} {
+		if err := workflow.AddEdge(edge[0], edge[1]); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+	}
+
+	if path, total := workflow.CriticalPath(); path != nil || total != 0 {
+		t.Fatalf("expected no critical path before running, got %v (%s)", path, total)
+	}
+
+	drainChannels(workflow)
+	if _, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"a": {"hello"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path, total := workflow.CriticalPath()
+	expected := []dag.NodeID{"a", "b", "d"}
+	if !slices.Equal(path, expected) {
+		t.Fatalf("expected critical path %v, got %v", expected, path)
+	}
+	if total < 100*time.Millisecond {
+		t.Fatalf("expected the critical path to take at least 100ms, got %s", total)
+	}
+}
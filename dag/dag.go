@@ -2,14 +2,23 @@
 package dag
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"math"
 	"runtime/trace"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/momiom/workflow/node"
 
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 	"gonum.org/v1/gonum/graph"
 	"gonum.org/v1/gonum/graph/simple"
 	"gonum.org/v1/gonum/graph/topo"
@@ -21,9 +30,12 @@ type NodeStatus string
 
 const (
 	Pending   NodeStatus = "Pending"
+	Ready     NodeStatus = "Ready" // 依存元ノードが全て終わり、実行枠の獲得を待っている
 	Running   NodeStatus = "Running"
 	Completed NodeStatus = "Completed"
 	Error     NodeStatus = "Error"
+	Skipped   NodeStatus = "Skipped"
+	Cached    NodeStatus = "Cached" // チェックポイントやキャッシュの出力を使用したため実行しなかった
 )
 
 type NodeState struct {
@@ -32,59 +44,377 @@ type NodeState struct {
 }
 
 type NodeIO struct {
-	ID      NodeID
-	Inputs  []string
-	Outputs []string
+	ID       NodeID
+	Inputs   []string
+	Outputs  []string
+	Metadata map[string]any // node.MetadataProviderを実装するノードが返したメタデータ
 }
 
+type edgeKey struct {
+	from NodeID
+	to   NodeID
+}
+
+// graphNodeはグラフ上のノードです。
+// グラフ上のIDはDAGごとに単調に増やして割り当て、ノードを削除しても再利用しません。
+type graphNode struct {
+	id   int64
+	name NodeID
+}
+
+func (n graphNode) ID() int64 { return n.id }
+
 type DAG struct {
-	graph         *simple.DirectedGraph
-	nodes         map[NodeID]graph.Node
-	nodeMap       map[NodeID]node.Node
-	inDegree      map[NodeID]int
-	nodeStatus    map[NodeID]NodeStatus
-	statusMu      sync.Mutex
-	statusChan    chan NodeState
-	ioChan        chan NodeIO
-	maxConcurrent int
-}
-
-func NewDAG(maxConcurrent int) *DAG {
-	return &DAG{
-		graph:         simple.NewDirectedGraph(),
-		nodes:         make(map[NodeID]graph.Node),
-		nodeMap:       make(map[NodeID]node.Node),
-		inDegree:      make(map[NodeID]int),
-		nodeStatus:    make(map[NodeID]NodeStatus),
-		statusChan:    make(chan NodeState),
-		ioChan:        make(chan NodeIO),
-		maxConcurrent: maxConcurrent,
+	graph            *simple.WeightedDirectedGraph
+	nodes            map[NodeID]graphNode
+	graphIDs         map[int64]NodeID // グラフ上のIDからNodeIDへの逆引き
+	nextGraphID      int64            // 次に割り当てるグラフ上のID
+	nodeMap          map[NodeID]node.Node
+	inDegree         map[NodeID]int
+	predecessors     map[NodeID][]NodeID                          // エッジを追加した順に並べた依存元ノード
+	edgeOutputs      map[edgeKey]string                           // エッジごとに渡す出力のキー
+	edgeTransforms   map[edgeKey]func([]string) ([]string, error) // エッジごとに出力に適用する変換
+	edgeSelections   map[edgeKey]OutputSelection                  // エッジごとに渡す出力の選択
+	optionalEdges    map[edgeKey]bool                             // 依存先ノードの実行を待たせないエッジ
+	nodeStatus       map[NodeID]NodeStatus
+	startOrder       []NodeID                       // 直近の実行でノードが開始した順序
+	lastOutputs      map[NodeID][]string            // 直近の実行でのノードの出力
+	lastKeyed        map[NodeID]map[string][]string // 直近の実行でのノードのキーごとの出力
+	ioHistory        []NodeIO                       // 直近の実行での全ノードの入出力
+	startTimes       map[NodeID]time.Time           // 直近の実行でノードがRunningになった時刻
+	durations        map[NodeID]time.Duration       // 直近の実行でノードの実行に掛かった時間
+	statusMu         sync.Mutex
+	chanMu           sync.Mutex // 各チャネルの作成と差し替えを保護する
+	statusChan       chan NodeState
+	ioChan           chan NodeIO
+	statusSubscribed bool                   // 現在の実行でstatusChanが取得されたか
+	ioSubscribed     bool                   // 現在の実行でioChanが取得されたか
+	channelsInUse    bool                   // 実行中のためチャネルを作り直せないか
+	streamChans      map[NodeID]chan string // ノードごとのストリーミング出力のチャネル
+	eventChan        chan Event             // 状態変更と入出力のイベントのチャネル（購読されていない場合はnil）
+	resultChan       chan NodeResult        // ExecuteStreamでノードの結果を送るチャネル（使用されていない場合はnil）
+	maxConcurrent    int
+	failFast         bool
+	deterministic    bool          // ノードを安定したトポロジカル順に1つずつ実行するか
+	retryAttempts    int           // 一時的なエラーの場合にノードを実行する最大回数
+	retryBackoff     time.Duration // 最初の再試行までの待機時間
+	rateLimiter      *rate.Limiter
+	nodeGroups       map[NodeID]string       // ノードが所属するリソースグループ
+	groupLimits      map[string]int          // リソースグループごとの同時実行数の上限
+	priorities       map[NodeID]int          // ノードの実行の優先度
+	taggedInputs     map[NodeID]bool         // 依存元のIDを前置した入力を受け取るノード
+	dedupInputs      map[NodeID]bool         // 重複を取り除いた入力を受け取るノード
+	skipOnEmpty      map[NodeID]bool         // 入力が空の場合に実行せずにスキップするノード
+	constantInputs   map[NodeID]constInputs  // 収集した入力の前後に置く固定の入力
+	errorHandlers    map[NodeID]ErrorHandler // ノードが失敗したときに呼び出すエラーハンドラー
+	disabled         map[NodeID]bool         // 実行せずにスキップするノード
+	dropDisabled     bool                    // 無効にしたノードが入力を依存先ノードに渡さないか
+	secrets          node.Secrets            // 実行中のノードに渡す秘密情報
+	tracer           oteltrace.Tracer        // スパンを作成するOpenTelemetryのトレーサー（nilの場合は作成しない）
+	failureEdges     map[edgeKey]bool        // 依存元が失敗した場合だけ依存先ノードを実行するエッジ
+	workflowTimeout  time.Duration           // 実行全体の制限時間（0以下は無制限）
+	lazyInputs       map[NodeID]InputFunc    // ノードの実行直前に入力を計算する関数
+	cache            Cache                   // ノードの出力のキャッシュ（nilの場合はキャッシュしない）
+	storeFactory     func() Store            // 実行ごとに出力のストアを作成する関数（nilの場合はメモリ上に保持する）
+	executor         Executor                // ノードを実行するExecutor（nilの場合はノードごとにゴルーチンを起動する）
+	runMu            sync.Mutex              // activeRunとpausedを保護する
+	activeRun        *activeRun              // 実行中の実行（実行中でない場合はnil）
+	paused           bool                    // Pauseにより新しいノードの開始を止めているか
+
+	maxTotalOutputBytes int // 全ノードの出力の合計バイト数の上限（0以下は無制限）
+
+	logger        *slog.Logger
+	nodeLogLevels map[NodeID]slog.Level
+
+	rootInputProvider func(NodeID) ([]string, bool)
+	stopCondition     func(outputs map[NodeID][]string) bool
+	middlewares       []Middleware // ノードの実行を包むミドルウェア（追加した順）
+	finalAggregator   func(finalOutputs map[NodeID][]string) []string
+}
+
+// ErrOutputLimitExceededはノードの出力の合計サイズが上限を超えたことを示すエラーです。
+var ErrOutputLimitExceeded = errors.New("total output size limit exceeded")
+
+// ErrWorkflowTimeoutはWithWorkflowTimeoutで設定した実行全体の制限時間を超えたことを示すエラーです。
+var ErrWorkflowTimeout = errors.New("workflow timeout exceeded")
+
+// ErrEmptyDAGはノードを1つも持たないDAGを実行しようとしたことを示すエラーです。
+var ErrEmptyDAG = errors.New("cannot execute empty DAG")
+
+// NewDAGは同時にmaxConcurrent個までのノードを実行する新しいDAGを作成します。
+// maxConcurrentが0以下の場合、同時に実行するノードの数を制限しません。
+func NewDAG(maxConcurrent int, opts ...Option) *DAG {
+	dag := &DAG{
+		graph:          simple.NewWeightedDirectedGraph(0, math.Inf(1)),
+		nodes:          make(map[NodeID]graphNode),
+		graphIDs:       make(map[int64]NodeID),
+		nodeMap:        make(map[NodeID]node.Node),
+		inDegree:       make(map[NodeID]int),
+		predecessors:   make(map[NodeID][]NodeID),
+		edgeOutputs:    make(map[edgeKey]string),
+		edgeTransforms: make(map[edgeKey]func([]string) ([]string, error)),
+		edgeSelections: make(map[edgeKey]OutputSelection),
+		optionalEdges:  make(map[edgeKey]bool),
+		failureEdges:   make(map[edgeKey]bool),
+		nodeLogLevels:  make(map[NodeID]slog.Level),
+		nodeGroups:     make(map[NodeID]string),
+		groupLimits:    make(map[string]int),
+		priorities:     make(map[NodeID]int),
+		taggedInputs:   make(map[NodeID]bool),
+		dedupInputs:    make(map[NodeID]bool),
+		skipOnEmpty:    make(map[NodeID]bool),
+		constantInputs: make(map[NodeID]constInputs),
+		errorHandlers:  make(map[NodeID]ErrorHandler),
+		disabled:       make(map[NodeID]bool),
+		nodeStatus:     make(map[NodeID]NodeStatus),
+		startTimes:     make(map[NodeID]time.Time),
+		durations:      make(map[NodeID]time.Duration),
+		statusChan:     make(chan NodeState),
+		ioChan:         make(chan NodeIO),
+		streamChans:    make(map[NodeID]chan string),
+		maxConcurrent:  maxConcurrent,
+	}
+	for _, opt := range opts {
+		opt(dag)
 	}
+	return dag
 }
 
 // ノードをDAGに追加するメソッド
+// 同じIDのノードが既に存在する場合は、エッジを保ったままノードの実装だけを置き換えます。
 func (dag *DAG) AddNode(id NodeID, n node.Node) {
-	slog.Debug("Adding node", "id", id, "node", n)
-	node := dag.graph.NewNode()
-	dag.graph.AddNode(node)
-	dag.nodes[id] = node
+	dag.log().Debug("Adding node", "node", id, "type", fmt.Sprintf("%T", n))
 	dag.nodeMap[id] = n
-	dag.inDegree[id] = 0
 	dag.nodeStatus[id] = Pending
+	if _, exists := dag.nodes[id]; exists {
+		return
+	}
+
+	gn := graphNode{id: dag.nextGraphID, name: id}
+	dag.nextGraphID++
+	dag.graph.AddNode(gn)
+	dag.nodes[id] = gn
+	dag.graphIDs[gn.id] = id
+	dag.inDegree[id] = 0
 }
 
+// ノードとそのノードに接続する全てのエッジをDAGから削除するメソッド
+// ノードに設定した優先度やリソースグループなども削除されます。実行中に呼び出すことはできません。
+func (dag *DAG) RemoveNode(id NodeID) error {
+	dag.log().Debug("Removing node", "node", id)
+
+	gn, ok := dag.nodes[id]
+	if !ok {
+		return fmt.Errorf("node %s does not exist", id)
+	}
+
+	// 依存先ノードの入力次数と依存元の一覧からこのノードを取り除く
+	for _, to := range graph.NodesOf(dag.graph.From(gn.id)) {
+		toID := dag.graphIDs[to.ID()]
+		edge := edgeKey{from: id, to: toID}
+		if !dag.optionalEdges[edge] {
+			dag.inDegree[toID]--
+		}
+		dag.predecessors[toID] = slices.DeleteFunc(dag.predecessors[toID], func(from NodeID) bool {
+			return from == id
+		})
+		dag.deleteEdge(edge)
+	}
+	for _, fromID := range dag.predecessors[id] {
+		dag.deleteEdge(edgeKey{from: fromID, to: id})
+	}
+
+	dag.graph.RemoveNode(gn.id)
+	delete(dag.nodes, id)
+	delete(dag.graphIDs, gn.id)
+	delete(dag.nodeMap, id)
+	delete(dag.inDegree, id)
+	delete(dag.predecessors, id)
+	delete(dag.nodeStatus, id)
+	delete(dag.priorities, id)
+	delete(dag.taggedInputs, id)
+	delete(dag.dedupInputs, id)
+	delete(dag.skipOnEmpty, id)
+	delete(dag.constantInputs, id)
+	delete(dag.errorHandlers, id)
+	delete(dag.disabled, id)
+	delete(dag.nodeGroups, id)
+	delete(dag.nodeLogLevels, id)
+	return nil
+}
+
+// deleteEdgeはエッジに設定した情報を削除します。グラフ上のエッジは削除しません。
+func (dag *DAG) deleteEdge(edge edgeKey) {
+	delete(dag.edgeOutputs, edge)
+	delete(dag.edgeTransforms, edge)
+	delete(dag.edgeSelections, edge)
+	delete(dag.optionalEdges, edge)
+	delete(dag.failureEdges, edge)
+}
+
+// ノードidを取得するメソッド
+func (dag *DAG) GetNode(id NodeID) (node.Node, bool) {
+	n, ok := dag.nodeMap[id]
+	return n, ok
+}
+
+// GetNodeAsはノードidを型Tとして取得します。
+// ノードが存在しないか、型Tではない場合はfalseを返します。
+func GetNodeAs[T node.Node](dag *DAG, id NodeID) (T, bool) {
+	n, ok := dag.GetNode(id)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	typed, ok := n.(T)
+	return typed, ok
+}
+
+// 次回（実行中であれば現在）の実行の状態変更を受け取るチャネルを返すメソッド
+// チャネルは実行の終了時に閉じられ、次の実行用に新しいチャネルが用意されます。
+// 実行を開始する前に呼び出しても実行中に呼び出しても、同じ実行では同じチャネルを返します。
+// チャネルは全ノードのReady、Running、終了の状態を保持できるバッファを持つため、実行中に遅れて購読しても
+// それまでの状態変更を受け取れます。
+// GetEventsを購読した実行では、このメソッドを呼び出していない限り状態変更は送られません。
+//
+// Deprecated: 入出力との前後関係を保って受け取れるGetEventsを使用してください。
 func (dag *DAG) GetStatusChan() <-chan NodeState {
+	dag.chanMu.Lock()
+	defer dag.chanMu.Unlock()
+	if !dag.statusSubscribed {
+		dag.resizeChannels()
+	}
+	dag.statusSubscribed = true
 	return dag.statusChan
 }
 
+// 次回（実行中であれば現在）の実行の入出力を受け取るチャネルを返すメソッド
+// チャネルは実行の終了時に閉じられ、次の実行用に新しいチャネルが用意されます。
+// 実行を開始する前に呼び出しても実行中に呼び出しても、同じ実行では同じチャネルを返します。
+// チャネルは全ノードの入出力を保持できるバッファを持ちます。
+// GetEventsを購読した実行では、このメソッドを呼び出していない限り入出力は送られません。
+//
+// Deprecated: 状態変更との前後関係を保って受け取れるGetEventsを使用してください。
 func (dag *DAG) GetIOChan() <-chan NodeIO {
+	dag.chanMu.Lock()
+	defer dag.chanMu.Unlock()
+	if !dag.ioSubscribed {
+		dag.resizeChannels()
+	}
+	dag.ioSubscribed = true
 	return dag.ioChan
 }
 
+// 次回（実行中であれば現在）の実行でノードidがストリーミングする部分的な出力を受け取るチャネルを返すメソッド
+// ストリーミングに対応したノード（StreamingLLMClientを使うLLMNodeなど）の出力の断片が順に送られます。
+// チャネルを取得していないノードの断片は破棄されます。チャネルは実行の終了時に閉じられます。
+func (dag *DAG) GetStreamChan(id NodeID) <-chan string {
+	dag.chanMu.Lock()
+	defer dag.chanMu.Unlock()
+	ch, ok := dag.streamChans[id]
+	if !ok {
+		ch = make(chan string)
+		dag.streamChans[id] = ch
+	}
+	return ch
+}
+
+// ノードidのストリーミング出力をチャネルに送るようコンテキストを設定するメソッド
+func (dag *DAG) withStream(ctx context.Context, id NodeID) context.Context {
+	dag.chanMu.Lock()
+	ch, ok := dag.streamChans[id]
+	dag.chanMu.Unlock()
+	if !ok {
+		return ctx
+	}
+	return node.WithStream(ctx, func(chunk string) {
+		select {
+		case ch <- chunk:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// statusesPerNodeは状態変更のチャネルのバッファをノード1つあたりいくつ確保するかです。
+// 再試行などがない場合にノードが通知するReady、Running、終了の状態を保持できる大きさです。
+// 再試行やSpawnerで追加したノードによりバッファが埋まった場合、購読されていないチャネルへの通知は破棄されます。
+const statusesPerNode = 3
+
+// resizeChannelsは購読されていない状態変更と入出力のチャネルを、現在のノード数に見合うバッファを持つチャネルに作り直すメソッド
+// チャネルを作成した後に追加したノードの分のバッファを確保するため、購読と実行の開始の前に呼び出します。
+// 実行中はチャネルに状態変更が溜まっているため作り直しません。
+// 呼び出し側でchanMuを保持している必要があります。
+func (dag *DAG) resizeChannels() {
+	if dag.channelsInUse {
+		return
+	}
+	n := dag.nodeCount()
+	if !dag.statusSubscribed && cap(dag.statusChan) < statusesPerNode*n {
+		dag.statusChan = make(chan NodeState, statusesPerNode*n)
+	}
+	if !dag.ioSubscribed && cap(dag.ioChan) < n {
+		dag.ioChan = make(chan NodeIO, n)
+	}
+}
+
+// nodeCountはノードの数を返すメソッド
+// 実行中にSpawnerで追加したノードを含めるため、statusMuを保持して読み出します。
+func (dag *DAG) nodeCount() int {
+	dag.statusMu.Lock()
+	defer dag.statusMu.Unlock()
+	return len(dag.nodes)
+}
+
+// 現在の実行のチャネルを閉じ、次の実行用のチャネルを用意するメソッド
+// Executeの終了時に必ず呼ばれるため、途中でエラーを返した場合も購読側のループは終了します。
+func (dag *DAG) resetChannels() {
+	dag.chanMu.Lock()
+	defer dag.chanMu.Unlock()
+	close(dag.statusChan)
+	close(dag.ioChan)
+	for _, ch := range dag.streamChans {
+		close(ch)
+	}
+	if dag.eventChan != nil {
+		close(dag.eventChan)
+		dag.eventChan = nil
+	}
+	// ExecuteStreamのチャネルは実行のエラーを送った後にExecuteStreamが閉じる
+	dag.resultChan = nil
+	n := dag.nodeCount()
+	dag.statusChan = make(chan NodeState, statusesPerNode*n)
+	dag.ioChan = make(chan NodeIO, n)
+	dag.statusSubscribed = false
+	dag.ioSubscribed = false
+	dag.channelsInUse = false
+	dag.streamChans = make(map[NodeID]chan string)
+}
+
 // エッジ（依存関係）をDAGに追加するメソッド
+// エッジの重みは0になります。
 func (dag *DAG) AddEdge(from NodeID, to NodeID) error {
-	slog.Debug("Adding edge", "from", from, "to", to)
+	return dag.AddWeightedEdge(from, to, 0)
+}
+
+// 重み付きのエッジをDAGに追加するメソッド
+// 重みはエッジで渡すデータの見込みのサイズなどを表し、スケジューリングの手掛かりとして使用するためのものです。
+// 現在の実行の動作には影響しません。設定した重みはEdgeWeightで取得できます。
+func (dag *DAG) AddWeightedEdge(from NodeID, to NodeID, weight float64) error {
+	dag.log().Debug("Adding edge", "from", from, "to", to, "weight", weight)
 
+	if err := dag.checkEdge(from, to); err != nil {
+		return err
+	}
+
+	fromNode, toNode := dag.nodes[from], dag.nodes[to]
+	dag.graph.SetWeightedEdge(dag.graph.NewWeightedEdge(fromNode, toNode, weight))
+	dag.inDegree[to]++
+	dag.predecessors[to] = append(dag.predecessors[to], from)
+	return nil
+}
+
+// checkEdgeはエッジfrom -> toを追加できるかを検証するメソッド
+func (dag *DAG) checkEdge(from NodeID, to NodeID) error {
 	fromNode, ok := dag.nodes[from]
 	if !ok {
 		return fmt.Errorf("node %s does not exist", from)
@@ -94,167 +424,1075 @@ func (dag *DAG) AddEdge(from NodeID, to NodeID) error {
 		return fmt.Errorf("node %s does not exist", to)
 	}
 
-	dag.graph.SetEdge(dag.graph.NewEdge(fromNode, toNode))
-	dag.inDegree[to]++
+	if from == to {
+		return fmt.Errorf("edge %s -> %s is a self loop", from, to)
+	}
+	// 同じエッジを重ねて追加すると入力次数が合わなくなるため、エラーとする
+	if dag.graph.HasEdgeFromTo(fromNode.ID(), toNode.ID()) {
+		return fmt.Errorf("edge %s -> %s already exists", from, to)
+	}
+	return nil
+}
+
+// エッジの重みを返すメソッド
+// エッジが存在しない場合はfalseを返します。
+func (dag *DAG) EdgeWeight(from NodeID, to NodeID) (float64, bool) {
+	fromNode, ok := dag.nodes[from]
+	if !ok {
+		return 0, false
+	}
+	toNode, ok := dag.nodes[to]
+	if !ok {
+		return 0, false
+	}
+	if !dag.graph.HasEdgeFromTo(fromNode.ID(), toNode.ID()) {
+		return 0, false
+	}
+	return dag.graph.WeightedEdge(fromNode.ID(), toNode.ID()).Weight(), true
+}
+
+// 全ノードの出力の合計バイト数の上限を設定するメソッド
+// 保持している出力の合計がnを超えると、そのノードはエラーとなり実行全体が中断されます。
+// 0以下を指定すると上限はなくなります。
+func (dag *DAG) SetMaxTotalOutputBytes(n int) {
+	dag.maxTotalOutputBytes = n
+}
+
+// ルートノードの既定の入力を返す関数を設定するメソッド
+// Executeに渡したinputsにルートノードの入力が含まれていない場合、providerが返す入力を使用します。
+// providerがfalseを返した場合、そのノードには入力が与えられません。
+// 似たルートノードを多数ファンアウトさせる場合に、入力のマップを繰り返し書く手間を省けます。
+func (dag *DAG) SetRootInputProvider(provider func(NodeID) ([]string, bool)) {
+	dag.rootInputProvider = provider
+}
+
+// ルートノードの入力を補った入力のマップを返すメソッド
+// 呼び出し側のマップは変更しません。
+func (dag *DAG) resolveInputs(inputs map[NodeID][]string) map[NodeID][]string {
+	if dag.rootInputProvider == nil {
+		return inputs
+	}
+	resolved := maps.Clone(inputs)
+	if resolved == nil {
+		resolved = make(map[NodeID][]string)
+	}
+	for id, n := range dag.nodes {
+		if _, exists := resolved[id]; exists || dag.graph.To(n.ID()).Len() > 0 {
+			continue
+		}
+		if provided, ok := dag.rootInputProvider(id); ok {
+			resolved[id] = provided
+		}
+	}
+	return resolved
+}
+
+// 実行を途中で終了する条件を設定するメソッド
+// conditionは各ノードの完了後にその時点までの出力を引数として呼ばれ、trueを返すと以降のノードは
+// 開始されません。実行中のノードは最後まで実行され、RunはStatusがRunStoppedの結果を返します。
+// outputsは実行中に更新されるため、condition内で保持したり変更したりしないでください。
+func (dag *DAG) SetStopCondition(condition func(outputs map[NodeID][]string) bool) {
+	dag.stopCondition = condition
+}
+
+// ノードの有効・無効を切り替えるメソッド
+// 無効にしたノードはエッジを残したまま実行されずにSkippedとなり、受け取った入力をそのまま出力として
+// 依存先ノードに渡します。WithDropDisabledOutputsを設定した場合は何も渡さないため、他に入力を持たない
+// 依存先ノードもスキップされます。ノードは追加した時点で有効です。
+func (dag *DAG) SetEnabled(id NodeID, enabled bool) {
+	if enabled {
+		delete(dag.disabled, id)
+		return
+	}
+	dag.disabled[id] = true
+}
+
+// 変換関数付きのエッジをDAGに追加するメソッド
+// fromの出力はtransformで変換されてからtoの入力に連結されます。2つのノードの間の簡単な変換のために
+// ノードを追加する手間を省けます。transformがエラーを返した場合、toのノードはエラーとなります。
+// transformは入力の収集中に呼ばれるため、時間のかかる処理は通常のノードとして追加してください。
+func (dag *DAG) AddEdgeWithTransform(from NodeID, to NodeID, transform func([]string) ([]string, error)) error {
+	if err := dag.AddEdge(from, to); err != nil {
+		return err
+	}
+	dag.edgeTransforms[edgeKey{from: from, to: to}] = transform
 	return nil
 }
 
-// 出次数が0のノード（リーフノード）を取得するメソッド
+// 任意のエッジをDAGに追加するメソッド
+// 通常のエッジと異なり、toのノードはfromのノードの完了を待たずに実行可能になります。
+// toの入力を収集する時点でfromが完了していればfromの出力が渡され、まだ完了していなければ
+// （実行中や実行枠を待っている場合を含む）fromの出力なしでtoが実行されます。どちらになるかは
+// 実行のタイミングによって決まるため、fromの出力がなくても動作するノードにだけ使用してください。
+// fromのノード自体は通常どおり実行され、Runはfromの終了も待ちます。
+func (dag *DAG) AddOptionalEdge(from NodeID, to NodeID) error {
+	if err := dag.AddEdge(from, to); err != nil {
+		return err
+	}
+	dag.inDegree[to]--
+	dag.optionalEdges[edgeKey{from: from, to: to}] = true
+	return nil
+}
+
+// 失敗のエッジをDAGに追加するメソッド
+// toのノードはfromのノードが失敗した場合だけ実行され、fromのエラーのメッセージを入力として受け取ります。
+// fromが成功した場合、このエッジからは何も渡されないため、他に入力を持たないtoはスキップされます。
+// 失敗のエッジを持つノードのエラーは実行全体のエラーとならず、fromの通常の依存先ノードは
+// fromの出力を受け取れないため同様にスキップされます。エラーの補償処理などの経路を組むために使用します。
+func (dag *DAG) AddFailureEdge(from NodeID, to NodeID) error {
+	if err := dag.AddEdge(from, to); err != nil {
+		return err
+	}
+	dag.failureEdges[edgeKey{from: from, to: to}] = true
+	return nil
+}
+
+// hasFailureEdgesはノードidが失敗のエッジの依存元かどうかを返すメソッド
+func (dag *DAG) hasFailureEdges(id NodeID) bool {
+	for edge := range dag.failureEdges {
+		if edge.from == id {
+			return true
+		}
+	}
+	return false
+}
+
+// エッジで渡す出力をキーで指定するメソッド
+// fromのノードはnode.KeyedOutputerを実装している必要があります。
+// キーを指定したエッジには、fromのGetKeyedOutputsのうちそのキーの出力だけが渡されます。
+// fromがそのキーの出力を返さなかった場合、このエッジからは何も渡されません。
+// どのエッジにも指定されていないキーの出力は、キーを指定していないエッジにGetOutputsとして渡る分を除き破棄されます。
+func (dag *DAG) SetEdgeOutput(from NodeID, to NodeID, key string) error {
+	dag.log().Debug("Setting edge output", "from", from, "to", to, "key", key)
+
+	fromNode, ok := dag.nodes[from]
+	if !ok {
+		return fmt.Errorf("node %s does not exist", from)
+	}
+	toNode, ok := dag.nodes[to]
+	if !ok {
+		return fmt.Errorf("node %s does not exist", to)
+	}
+	if !dag.graph.HasEdgeFromTo(fromNode.ID(), toNode.ID()) {
+		return fmt.Errorf("edge %s -> %s does not exist", from, to)
+	}
+	if _, ok := dag.nodeMap[from].(node.KeyedOutputer); !ok {
+		return fmt.Errorf("node %s does not provide keyed outputs", from)
+	}
+
+	dag.edgeOutputs[edgeKey{from: from, to: to}] = key
+	return nil
+}
+
+// 入次数が0のノード（ルートノード）をID順に取得するメソッド
+// 実行中に更新される入力次数ではなくグラフの構造から求めるため、実行中に呼び出しても同じ結果を返します。
+// 外部からの入力を与える必要があるノードを知るために使用できます。
+func (dag *DAG) GetRootNodes() []NodeID {
+	dag.log().Debug("Getting root nodes")
+
+	var rootNodes []NodeID
+	for id, n := range dag.nodes {
+		if dag.graph.To(n.ID()).Len() == 0 {
+			rootNodes = append(rootNodes, id)
+		}
+	}
+	slices.Sort(rootNodes)
+	return rootNodes
+}
+
+// 出次数が0のノード（リーフノード）をID順に取得するメソッド
+// GetRootNodesと同様に、グラフの構造から求めます。
 func (dag *DAG) GetLeafNodes() []NodeID {
-	slog.Debug("Getting leaf nodes")
+	dag.log().Debug("Getting leaf nodes")
 
 	var leafNodes []NodeID
-	nodes := dag.graph.Nodes()
-	for nodes.Next() {
-		node := nodes.Node()
-		if len(graph.NodesOf(dag.graph.From(node.ID()))) == 0 {
-			for id, n := range dag.nodes {
-				if n.ID() == node.ID() {
-					leafNodes = append(leafNodes, id)
-					break
-				}
-			}
+	for id, n := range dag.nodes {
+		if dag.graph.From(n.ID()).Len() == 0 {
+			leafNodes = append(leafNodes, id)
 		}
 	}
+	slices.Sort(leafNodes)
 	return leafNodes
 }
 
+// 全てのエッジを(依存元, 依存先)の組として、依存元、依存先のNodeIDの昇順に返すメソッド
+// 任意のエッジや変換関数付きのエッジなど、追加した方法に関わらず全てのエッジを含みます。
+func (dag *DAG) Edges() [][2]NodeID {
+	edges := dag.sortedEdges()
+	pairs := make([][2]NodeID, len(edges))
+	for i, edge := range edges {
+		pairs[i] = [2]NodeID{edge.from, edge.to}
+	}
+	return pairs
+}
+
+// sortedEdgesは全てのエッジを依存元、依存先のNodeIDの昇順に返すメソッド
+func (dag *DAG) sortedEdges() []edgeKey {
+	var edges []edgeKey
+	for to, froms := range dag.predecessors {
+		for _, from := range froms {
+			edges = append(edges, edgeKey{from: from, to: to})
+		}
+	}
+	slices.SortFunc(edges, func(a, b edgeKey) int {
+		return cmp.Or(strings.Compare(string(a.from), string(b.from)), strings.Compare(string(a.to), string(b.to)))
+	})
+	return edges
+}
+
 func (dag *DAG) updateNodeStatus(id NodeID, status NodeStatus) {
+	dag.nodeLogger(id).Debug("Node status changed", "status", status)
 	dag.statusMu.Lock()
-	defer dag.statusMu.Unlock()
 	dag.nodeStatus[id] = status
-	dag.statusChan <- NodeState{ID: id, Status: status}
+	dag.recordTiming(id, status)
+	// 購読側が状態を参照できるよう、送信中はロックを保持しない
+	dag.statusMu.Unlock()
+	dag.chanMu.Lock()
+	statusChan, subscribed := dag.statusChan, dag.statusSubscribed
+	legacy := subscribed || (dag.eventChan == nil && dag.resultChan == nil)
+	dag.chanMu.Unlock()
+	if legacy {
+		sendLegacy(statusChan, NodeState{ID: id, Status: status}, subscribed)
+	}
+	dag.emitEvent(StatusEvent{ID: id, Status: status, Timestamp: time.Now()})
+}
+
+func (dag *DAG) notifyNodeIO(id NodeID, inputs, outputs []string, metadata map[string]any) {
+	dag.statusMu.Lock()
+	dag.ioHistory = append(dag.ioHistory, NodeIO{ID: id, Inputs: slices.Clone(inputs), Outputs: slices.Clone(outputs), Metadata: maps.Clone(metadata)})
+	dag.statusMu.Unlock()
+	dag.chanMu.Lock()
+	ioChan, subscribed := dag.ioChan, dag.ioSubscribed
+	legacy := subscribed || (dag.eventChan == nil && dag.resultChan == nil)
+	dag.chanMu.Unlock()
+	if legacy {
+		sendLegacy(ioChan, NodeIO{ID: id, Inputs: inputs, Outputs: outputs, Metadata: metadata}, subscribed)
+	}
+	dag.emitEvent(IOEvent{ID: id, Inputs: inputs, Outputs: outputs, Metadata: metadata, Timestamp: time.Now()})
+	dag.emitResult(NodeResult{ID: id, Outputs: outputs})
+}
+
+// sendLegacyは状態変更や入出力のチャネルに値を送る関数
+// 購読されていないチャネルは誰も受信しないため、バッファが埋まっている場合は待たずに破棄します。
+func sendLegacy[T any](ch chan T, v T, subscribed bool) {
+	if subscribed {
+		ch <- v
+		return
+	}
+	select {
+	case ch <- v:
+	default:
+	}
 }
 
-func (dag *DAG) notifyNodeIO(id NodeID, inputs, outputs []string) {
-	dag.ioChan <- NodeIO{ID: id, Inputs: inputs, Outputs: outputs}
+// 状態がPendingのままのノードをNodeIDの昇順に返すメソッド
+func (dag *DAG) pendingNodes() []NodeID {
+	dag.statusMu.Lock()
+	defer dag.statusMu.Unlock()
+	var pending []NodeID
+	for id, status := range dag.nodeStatus {
+		if status == Pending {
+			pending = append(pending, id)
+		}
+	}
+	slices.Sort(pending)
+	return pending
+}
+
+// 全ノードの状態を実行前のPendingに戻すメソッド
+func (dag *DAG) resetNodeStatus() {
+	dag.statusMu.Lock()
+	defer dag.statusMu.Unlock()
+	for id := range dag.nodeStatus {
+		dag.nodeStatus[id] = Pending
+	}
+	dag.startOrder = nil
+	dag.ioHistory = nil
+	dag.startTimes = make(map[NodeID]time.Time)
+	dag.durations = make(map[NodeID]time.Duration)
+}
+
+// 直近（実行中であれば現在）の実行での全ノードの入出力を、ノードが完了した順に返すメソッド
+// GetIOChanと異なりチャネルを読むゴルーチンを用意する必要がなく、実行後に何度でも参照できます。
+// 返されるスライスは呼び出し時点の写しです。
+func (dag *DAG) IOHistory() []NodeIO {
+	dag.statusMu.Lock()
+	defer dag.statusMu.Unlock()
+	return slices.Clone(dag.ioHistory)
+}
+
+// ノードの開始を記録するメソッド
+func (dag *DAG) recordStart(id NodeID) {
+	dag.statusMu.Lock()
+	defer dag.statusMu.Unlock()
+	dag.startOrder = append(dag.startOrder, id)
+}
+
+// 全ノードのうち処理を終えたノードの割合を0から1の範囲で返すメソッド
+// Completed、Skipped、Cachedのノードを処理済みとして数えます。実行中にも呼び出すことができます。
+// ノードが1つもない場合は0を返します。
+func (dag *DAG) Progress() float64 {
+	dag.statusMu.Lock()
+	defer dag.statusMu.Unlock()
+	if len(dag.nodeStatus) == 0 {
+		return 0
+	}
+	done := 0
+	for _, status := range dag.nodeStatus {
+		switch status {
+		case Completed, Skipped, Cached:
+			done++
+		}
+	}
+	return float64(done) / float64(len(dag.nodeStatus))
+}
+
+// 直近の実行でノードが開始した順序を返すメソッド
+// 並列に実行されるため、トポロジカルソートの順序とは必ずしも一致しません。
+// 実行中に呼び出した場合は、その時点までに開始したノードを返します。
+func (dag *DAG) ExecutionOrder() []NodeID {
+	dag.statusMu.Lock()
+	defer dag.statusMu.Unlock()
+	return slices.Clone(dag.startOrder)
+}
+
+// gonumのノードIDに対応するNodeIDを返すメソッド
+func (dag *DAG) nodeID(graphID int64) (NodeID, bool) {
+	id, ok := dag.graphIDs[graphID]
+	return id, ok
+}
+
+// collectInputsはノードに渡す入力を組み立てます。
+// 外部から与えられた入力（inputs[id]）を先頭に置き、その後ろに依存元ノードの出力を
+// AddEdgeで追加した順に連結します。ルート以外のノードにも定数や設定値を注入できます。
+// SetEdgeOutputでキーが指定されたエッジからは、依存元のキーごとの出力のうちそのキーの分だけを受け取ります。
+// AddEdgeWithTransformで変換が指定されたエッジからは、依存元の出力を変換した結果を受け取ります。
+// AddOptionalEdgeで追加したエッジからは、入力の収集時点で依存元が完了している場合だけ出力を受け取ります。
+// WithTaggedInputsが設定されたノードは、依存元の出力を依存元のIDを前置した形で受け取ります。
+// WithDedupInputsが設定されたノードは、重複を取り除いた入力を受け取ります。
+// AddFailureEdgeで追加したエッジからは、依存元が失敗した場合だけそのエラーのメッセージを受け取ります。
+func (dag *DAG) collectInputs(id NodeID, inputs map[NodeID][]string, outputs *runOutputs, keyedOutputs map[NodeID]map[string][]string, failures map[NodeID]error) ([]string, error) {
+	var nodeInputs []string
+	if input, exists := inputs[id]; exists {
+		nodeInputs = append(nodeInputs, input...)
+	}
+	for _, fromID := range dag.predecessors[id] {
+		edge := edgeKey{from: fromID, to: id}
+		var output []string
+		var exists bool
+		if dag.failureEdges[edge] {
+			if err, failed := failures[fromID]; failed {
+				output, exists = []string{err.Error()}, true
+			}
+		} else if key, keyed := dag.edgeOutputs[edge]; keyed {
+			output, exists = keyedOutputs[fromID][key]
+		} else {
+			output, exists = outputs.get(fromID)
+		}
+		if !exists {
+			continue
+		}
+		if selection, ok := dag.edgeSelections[edge]; ok {
+			output = selectOutputs(output, selection)
+		}
+		if transform, ok := dag.edgeTransforms[edge]; ok {
+			transformed, err := transform(slices.Clone(output))
+			if err != nil {
+				return nil, fmt.Errorf("transform on edge %s -> %s failed: %w", fromID, id, err)
+			}
+			output = transformed
+		}
+		if dag.taggedInputs[id] {
+			output = tagInputs(fromID, output)
+		}
+		nodeInputs = append(nodeInputs, output...)
+	}
+	if dag.dedupInputs[id] {
+		nodeInputs = dedup(nodeInputs)
+	}
+	return nodeInputs, nil
+}
+
+// starvedはノードが外部入力を持たず、依存元の全てが出力を持たない（スキップした、または
+// 出力が空だった）かどうかを返します。このようなノードは実行されずにスキップされます。
+// 任意のエッジの依存元しか持たないノードは、ルートノードと同様にスキップされません。
+// AddFailureEdgeで追加したエッジの依存元は、失敗した場合に出力を持つものとして扱います。
+func (dag *DAG) starved(id NodeID, inputs map[NodeID][]string, outputs *runOutputs, failures map[NodeID]error) bool {
+	if len(inputs[id]) > 0 {
+		return false
+	}
+	required := false
+	for _, fromID := range dag.predecessors[id] {
+		if dag.failureEdges[edgeKey{from: fromID, to: id}] {
+			if _, failed := failures[fromID]; failed {
+				return false
+			}
+			required = true
+			continue
+		}
+		if output, _ := outputs.get(fromID); len(output) > 0 {
+			return false
+		}
+		if !dag.optionalEdges[edgeKey{from: fromID, to: id}] {
+			required = true
+		}
+	}
+	// 任意のエッジしか持たないノードはルートノードと同様に扱う
+	return required
 }
 
 // DAGを実行するメソッド
+// 全ノードの出力とリーフノードの出力を返します。詳細な実行結果が必要な場合はRunを使用してください。
+// ノードの失敗により実行がエラーとなった場合も、それまでに完了したノードの出力をエラーとともに返します。
 func (dag *DAG) Execute(ctx context.Context, inputs map[NodeID][]string) (map[NodeID][]string, map[NodeID][]string, error) {
-	slog.Debug("Executing DAG")
+	result, err := dag.Run(ctx, inputs)
+	if result == nil {
+		return nil, nil, err
+	}
+	return result.Outputs, result.FinalOutputs, err
+}
+
+// DAGを実行し、リーフノードの出力をまとめた結果を返すメソッド
+// まとめ方はWithFinalAggregatorで設定します。設定しない場合はリーフノードの出力をNodeIDの昇順に並べたものです。
+func (dag *DAG) ExecuteAggregated(ctx context.Context, inputs map[NodeID][]string) ([]string, error) {
+	result, err := dag.Run(ctx, inputs)
+	if result == nil {
+		return nil, err
+	}
+	return result.Aggregated, err
+}
+
+// DAGを実行し、リーフノードの出力をreducerで1つの結果にまとめるメソッド
+// reducerにはリーフノードの出力をNodeIDの昇順に並べたものを渡します。WithFinalAggregatorの設定は使用しません。
+// 実行がエラーとなった場合はreducerを呼び出さずにエラーを返します。
+func (dag *DAG) ExecuteAndReduce(ctx context.Context, inputs map[NodeID][]string, reducer func([]string) (string, error)) (string, error) {
+	result, err := dag.Run(ctx, inputs)
+	if err != nil {
+		return "", err
+	}
+	reduced, err := reducer(result.flattenFinal())
+	if err != nil {
+		return "", fmt.Errorf("failed to reduce outputs: %w", err)
+	}
+	return reduced, nil
+}
+
+// DAGを実行し、実行結果を返すメソッド
+// 各ノードの入力は外部入力、依存元ノードの出力（エッジ追加順）の順に連結されます。
+// 外部入力がなく、依存元の全てがスキップしたか出力が空だったノードはSkippedとなります。
+// ノードの失敗によりエラーを返す場合も、StatusがRunFailedの結果にそれまでに完了したノードの出力を含めて返します。
+// ノードが失敗していなくても、キャンセルにより実行しなかったノードがある場合はキャンセルのエラーを返します。
+// 依存元の失敗などにより実行可能にならなかったノードは、警告のログを出力してExecuteResultのUnreachedで報告します。
+// ノードがない場合はErrEmptyDAGを返します。グラフが循環しているなど、実行を開始できなかった場合の結果はnilです。
+// 同じDAGに対して繰り返し呼び出すことができますが、並行して呼び出すことはできません。
+func (dag *DAG) Run(ctx context.Context, inputs map[NodeID][]string) (*ExecuteResult, error) {
+	return dag.run(ctx, inputs, nil)
+}
+
+// DAGを実行するメソッド
+// cpがnilでない場合、チェックポイントで完了していたノードは実行せずに保存された出力を使用します。
+func (dag *DAG) run(ctx context.Context, inputs map[NodeID][]string, cp *checkpoint) (*ExecuteResult, error) {
+	dag.log().Debug("Executing DAG")
+	defer dag.resetChannels()
+
+	// ノードがない場合は何も実行されないため、呼び出し側の誤りとして扱う
+	if len(dag.nodes) == 0 {
+		return nil, ErrEmptyDAG
+	}
+	// 購読されていないチャネルのバッファを実行するノードの数に合わせ、実行の終了まで作り直さない
+	dag.chanMu.Lock()
+	dag.resizeChannels()
+	dag.channelsInUse = true
+	dag.chanMu.Unlock()
+	// 全てのノードが依存元を持つ場合、開始できるノードがない
+	if len(dag.GetRootNodes()) == 0 {
+		dag.log().Warn("DAG has no root nodes; no node can start")
+	}
 
 	// トポロジカルソートでノードの実行順序を決定
 	sorted, err := topo.Sort(dag.graph)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	// ゴルーチンを起動する前に、全てのノードが正しく実行可能になることを確かめる
+	if err := dag.checkSchedulable(sorted); err != nil {
+		return nil, err
+	}
+
+	outputs := newRunOutputs(dag.newStore())             // ノードの出力を保持するストア
+	keyedOutputs := make(map[NodeID]map[string][]string) // ノードのキーごとの出力を保持するマップ
+	finalOutputs := make(map[NodeID][]string)            // 最終出力を保持するマップ
+	metadata := make(map[NodeID]map[string]any)          // ノードが返したメタデータを保持するマップ
+	var mu sync.Mutex                                    // 同期用のミューテックス
+	cond := sync.NewCond(&mu)                            // 実行可能なノードや実行枠の変化を通知する条件変数
+	queue := &readyQueue{}                               // 実行枠を待っているノードのキュー
+	running := 0                                         // 実行枠を獲得して実行中のノードの数
+	var execErr error                                    // 実行エラーを保持する変数
+	failures := make(map[NodeID]error)                   // 失敗のエッジで処理するノードのエラー
+	var totalOutputBytes int                             // 保持している出力の合計バイト数
+	var stopped bool                                     // 停止条件を満たしたかどうか
+	var aborted bool                                     // ノードがnode.ErrAbortを返したかどうか
+	var shutdown bool                                    // Shutdownにより新しいノードの開始を止めたかどうか
+	var cancelled bool                                   // キャンセルによりノードを実行せずにスキップしたかどうか
+	var spawned []NodeID                                 // 実行中にSpawnerで追加したノード
+	inDegree := maps.Clone(dag.inDegree)                 // 実行ごとに減算する入力次数
+	inputs = dag.resolveInputs(inputs)
+	dag.resetNodeStatus()
+	// 前回の実行の入出力が残らないよう、全てのノードを初期状態に戻す
+	for _, n := range dag.nodeMap {
+		n.Reset()
+	}
+	// node.Lifecycleを実装するノードを開始し、実行の終了時に停止する
+	stopNodes, err := dag.startNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stopNodes()
+	defer func() { dag.recordOutputs(outputs.snapshot(), keyedOutputs) }()
+	// Spawnerで追加したノードは実行の終了時に取り除く
+	defer func() { dag.removeSpawned(spawned) }()
+
+	// チェックポイントで完了していたノードの出力を復元
+	cached := make(map[NodeID]bool)
+	if cp != nil {
+		for _, id := range cp.completed() {
+			cached[id] = true
+			outputs.put(id, cp.Outputs[id])
+			if keyed, ok := cp.KeyedOutputs[id]; ok {
+				keyedOutputs[id] = keyed
+			}
+			for _, output := range cp.Outputs[id] {
+				totalOutputBytes += len(output)
+			}
+		}
+	}
+
+	// 決定的な実行モードでは、安定したトポロジカル順での位置を優先度としてノードを1つずつ実行する
+	maxConcurrent := dag.maxConcurrent
+	var rank map[NodeID]int // 安定したトポロジカル順でのノードの位置（決定的な実行モードの場合のみ）
+	if dag.deterministic {
+		order, err := dag.plan()
+		if err != nil {
+			return nil, err
+		}
+		rank = make(map[NodeID]int, len(order))
+		for i, id := range order {
+			rank[id] = i
+		}
+		maxConcurrent = 1
+	}
+	// ノードの優先度を返す関数
+	priority := func(id NodeID) int {
+		if rank != nil {
+			return -rank[id]
+		}
+		return dag.priorities[id]
+	}
+	// 決定的な実行モードで、実行可能になったノードを安定したトポロジカル順に並べる関数
+	sortReady := func(ids []NodeID) {
+		if rank != nil {
+			slices.SortFunc(ids, func(a, b NodeID) int { return rank[a] - rank[b] })
+		}
+	}
+
+	sem := newLimiter(maxConcurrent)       // 同時実行数を制限するセマフォ
+	groupSems := make(map[string]*limiter) // リソースグループごとのセマフォ
+	if rank == nil {
+		for group, n := range dag.groupLimits {
+			groupSems[group] = newLimiter(n)
+		}
+	}
+	// ノードが使用するセマフォを選択する関数
+	// 上限が設定されたグループに所属するノードはグループのセマフォを、それ以外は全体のセマフォを使用する
+	limiterFor := func(id NodeID) *limiter {
+		if l, ok := groupSems[dag.nodeGroups[id]]; ok {
+			return l
+		}
+		return sem
+	}
+
+	// WithWorkflowTimeoutが設定されている場合は、実行全体に期限を設定する
+	if dag.workflowTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeoutCause(ctx, dag.workflowTimeout, ErrWorkflowTimeout)
+		defer cancelTimeout()
+	}
+	// fail-fastモードなど実行全体を中断する場合にこのコンテキストをキャンセルする
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// WithSecretsで設定した秘密情報をノードに渡す
+	if dag.secrets != nil {
+		ctx = node.WithSecrets(ctx, dag.secrets)
+	}
+
+	// Shutdownから新しいノードの開始を止められるよう、実行を登録する
+	run := &activeRun{
+		done:   make(chan struct{}),
+		cancel: cancel,
+		drain: func() {
+			mu.Lock()
+			defer mu.Unlock()
+			shutdown = true
+			cond.Broadcast()
+		},
+		wake: func() {
+			mu.Lock()
+			defer mu.Unlock()
+			cond.Broadcast()
+		},
 	}
+	dag.setActiveRun(run)
+	defer dag.clearActiveRun(run)
 
-	outputs := make(map[NodeID][]string)      // ノードの出力を保持するマップ
-	finalOutputs := make(map[NodeID][]string) // 最終出力を保持するマップ
-	var mu sync.Mutex                         // 同期用のミューテックス
-	var wg sync.WaitGroup                     // 並列処理の待機グループ
-	var execErr error                         // 実行エラーを保持する変数
+	// 依存先ノードの入力次数を更新し、実行可能になったノードをキューに追加する関数
+	// Readyの状態を通知してからキューに追加するため、RunningがReadyより先に通知されることはない
+	markCancelled := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		cancelled = true
+	}
 
-	sem := make(chan struct{}, dag.maxConcurrent) // セマフォとしてチャネルを使用
+	var scheduleSuccessors func(ctx context.Context, id NodeID)
+	scheduleSuccessors = func(ctx context.Context, id NodeID) {
+		var ready, skipped []NodeID
+		mu.Lock()
+		for _, toNode := range graph.NodesOf(dag.graph.From(dag.nodes[id].ID())) {
+			toID, _ := dag.nodeID(toNode.ID())
+			// 任意のエッジは入力次数に数えていない
+			if dag.optionalEdges[edgeKey{from: id, to: toID}] {
+				continue
+			}
+			inDegree[toID]--
+			if inDegree[toID] != 0 || stopped || shutdown {
+				continue
+			}
+			if ctx.Err() != nil {
+				skipped = append(skipped, toID)
+				continue
+			}
+			ready = append(ready, toID)
+		}
+		mu.Unlock()
+		// キャンセルされた後に実行可能になったノードは実行せずにスキップし、その依存先ノードも同様にスキップする
+		for _, toID := range skipped {
+			dag.nodeLogger(toID).Debug("Execution cancelled before node became ready")
+			markCancelled()
+			dag.updateNodeStatus(toID, Skipped)
+			scheduleSuccessors(ctx, toID)
+		}
+		if len(ready) == 0 {
+			return
+		}
+		sortReady(ready)
 
-	var execNode func(ctx context.Context, id NodeID)
+		for _, toID := range ready {
+			dag.updateNodeStatus(toID, Ready)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, toID := range ready {
+			queue.push(toID, priority(toID))
+		}
+	}
+
+	// ノードの失敗を記録する関数
+	// abortがtrueの場合は実行全体を中断する
+	fail := func(ctx context.Context, id NodeID, err error, abort bool) {
+		mu.Lock()
+		// 最初のエラーを保持し、キャンセルによる後続のエラーで上書きしない
+		if execErr == nil {
+			execErr = err
+		}
+		mu.Unlock()
+		if abort {
+			cancel()
+		}
+		dag.updateNodeStatus(id, Error)
+		dag.emitResult(NodeResult{ID: id, Err: err})
+		recordSpanError(ctx, err)
+		trace.Log(ctx, "error", err.Error())
+	}
 
 	// ノードを実行する関数
-	execNode = func(ctx context.Context, id NodeID) {
-		slog.Debug("Start execNode", "id", id)
-		defer slog.Debug("End execNode", "id", id)
+	// 呼び出し時点でノードは実行枠を獲得済み
+	execNode := func(ctx context.Context, id NodeID) {
+		logger := dag.nodeLogger(id)
+		logger.Debug("Start execNode")
+		defer logger.Debug("End execNode")
+
+		// 待機中に実行が中断された場合はノードを実行せずにスキップする
+		if ctx.Err() != nil {
+			logger.Debug("Execution cancelled before node start")
+			markCancelled()
+			dag.updateNodeStatus(id, Skipped)
+			return
+		}
+		mu.Lock()
+		halted := stopped || shutdown
+		mu.Unlock()
+		if halted {
+			logger.Debug("Execution stopped before node start")
+			return
+		}
+
+		// WithTracerが設定されている場合はノードのスパンを作成する
+		ctx, endSpan := dag.startNodeSpan(ctx, id)
+		defer endSpan()
+
+		// チェックポイントから復元したノードは実行しない
+		if cached[id] {
+			logger.Debug("Node restored from checkpoint")
+			dag.updateNodeStatus(id, Cached)
+			scheduleSuccessors(ctx, id)
+			return
+		}
+
+		// 無効にしたノードは実行せず、設定に応じて入力をそのまま依存先ノードに渡す
+		if dag.disabled[id] {
+			logger.Debug("Node disabled")
+			mu.Lock()
+			nodeInputs, err := dag.collectInputs(id, inputs, outputs, keyedOutputs, failures)
+			if err == nil && !dag.dropDisabled && len(nodeInputs) > 0 {
+				outputs.put(id, nodeInputs)
+			}
+			mu.Unlock()
+			if err != nil {
+				logger.Debug("Edge transform failed", "error", err)
+				fail(ctx, id, err, dag.failFast)
+				return
+			}
+			dag.updateNodeStatus(id, Skipped)
+			scheduleSuccessors(ctx, id)
+			return
+		}
 
-		defer wg.Done()
-		sem <- struct{}{} // セマフォのロックを取得
-		defer func() {
-			<-sem // セマフォのロックを解放
-		}()
+		// レートリミッターが設定されている場合は実行枠が空くまで待機
+		if dag.rateLimiter != nil {
+			if err := dag.rateLimiter.Wait(ctx); err != nil {
+				logger.Debug("Rate limiter wait failed", "error", err)
+				fail(ctx, id, err, dag.failFast)
+				return
+			}
+		}
+
+		// レートリミッターなどで待機している間に実行が中断された場合も、ノードを実行せずにスキップする
+		if ctx.Err() != nil {
+			logger.Debug("Execution cancelled before node execution")
+			markCancelled()
+			dag.updateNodeStatus(id, Skipped)
+			return
+		}
 
 		// ノードの状態を更新
+		dag.recordStart(id)
 		dag.updateNodeStatus(id, Running)
 
 		// ノードごとにトレースイベントを開始
 		trace.WithRegion(ctx, fmt.Sprintf("Node %s", id), func() {
+			// 実行中に追加されるノードがあるため、ノードの一覧はロックを保持して参照する
+			mu.Lock()
 			n := dag.nodeMap[id]
+			mu.Unlock()
 
-			// 初期入力と依存ノードからの入力を収集
-			var nodeInputs []string
-			if input, exists := inputs[id]; exists {
-				nodeInputs = append(nodeInputs, input...)
-			}
-
-			for _, fromNode := range graph.NodesOf(dag.graph.To(dag.nodes[id].ID())) {
-				for fromID, n := range dag.nodes {
-					if n.ID() == fromNode.ID() {
-						if output, exists := outputs[fromID]; exists {
-							nodeInputs = append(nodeInputs, output...)
-						}
-						break
-					}
-				}
-			}
-			n.SetInputs(nodeInputs)
-			slog.Debug("Node inputs", "id", id, "inputs", nodeInputs)
-
-			// ノードを実行
-			slog.Debug("Executing node", "id", id)
-			if err := n.Execute(); err != nil {
-				slog.Debug("Error executing node", "id", id, "error", err)
-				mu.Lock()
-				execErr = err
-				mu.Unlock()
-				dag.updateNodeStatus(id, Error)
-				trace.Log(ctx, "error", err.Error())
+			// SetLazyInputsで設定した入力を計算する
+			nodeExternal, err := dag.withLazyInput(ctx, id, inputs)
+			if err != nil {
+				logger.Debug("Lazy input failed", "error", err)
+				fail(ctx, id, err, dag.failFast)
 				return
 			}
 
-			// ノードの出力を収集
-			nodeOutputs := n.GetOutputs()
+			// 初期入力と依存ノードからの入力を収集
 			mu.Lock()
-			outputs[id] = nodeOutputs
+			nodeInputs, err := dag.collectInputs(id, nodeExternal, outputs, keyedOutputs, failures)
+			starved := dag.starved(id, nodeExternal, outputs, failures)
 			mu.Unlock()
-			slog.Debug("Node outputs", "id", id, "outputs", nodeOutputs)
 
-			// ノードの状態と入出力を更新
-			dag.updateNodeStatus(id, Completed)
-			dag.notifyNodeIO(id, nodeInputs, nodeOutputs)
+			// 依存元が全て出力を持たない場合は、空の入力で実行せずにスキップする
+			if starved {
+				logger.Debug("Node skipped because predecessors produced no outputs")
+				dag.updateNodeStatus(id, Skipped)
+				scheduleSuccessors(ctx, id)
+				return
+			}
+			if err != nil {
+				logger.Debug("Edge transform failed", "error", err)
+				fail(ctx, id, err, dag.failFast)
+				return
+			}
+			// WithSkipOnEmptyInputを設定したノードは、入力が空の場合にエラーとせずスキップする
+			if dag.skipOnEmpty[id] && emptyInputs(nodeInputs) {
+				logger.Debug("Node skipped because its inputs are empty")
+				dag.updateNodeStatus(id, Skipped)
+				scheduleSuccessors(ctx, id)
+				return
+			}
+			nodeInputs = dag.withConstantInputs(id, nodeInputs)
+			n.SetInputs(nodeInputs)
+			logger.Debug("Node inputs", "inputs", nodeInputs)
+
+			// キャッシュに同じ入力での出力があればノードを実行しない
+			key, cacheable := dag.cacheKey(id, n, nodeInputs)
+			var nodeOutputs []string
+			var nodeMetadata map[string]any
+			var spawner *Spawner
+			var hit bool
+			var abort bool
+			if cacheable {
+				nodeOutputs, hit = dag.cache.Get(key)
+				nodeOutputs = slices.Clone(nodeOutputs)
+			}
+			if hit {
+				logger.Debug("Node outputs served from cache")
+			} else {
+				// ノードを実行
+				logger.Debug("Executing node")
+				// 実行中のノードが依存先ノードを追加できるよう、Spawnerをコンテキストに設定する
+				spawner = &Spawner{parent: id, exists: func(other NodeID) bool {
+					mu.Lock()
+					defer mu.Unlock()
+					_, ok := dag.nodes[other]
+					return ok
+				}}
+				nodeCtx := context.WithValue(dag.withStream(withNodePreviousOutput(ctx, id), id), spawnerKey{}, spawner)
+				err := dag.executeWithHandler(nodeCtx, id, n)
+				if errors.Is(err, node.ErrSkip) {
+					// スキップしたノードは出力を持たないが、依存先ノードの実行は継続する
+					logger.Debug("Node skipped")
+					dag.updateNodeStatus(id, Skipped)
+					scheduleSuccessors(ctx, id)
+					return
+				}
+				// 実行全体を打ち切るノードは完了したものとして出力を記録する
+				if errors.Is(err, node.ErrAbort) {
+					logger.Debug("Node aborted the execution")
+					abort = true
+					err = nil
+				}
+				if err != nil && dag.hasFailureEdges(id) {
+					// 失敗のエッジを持つノードのエラーは実行全体のエラーとせず、依存先ノードに処理させる
+					logger.Debug("Error handled by failure edges", "error", err)
+					mu.Lock()
+					failures[id] = err
+					mu.Unlock()
+					dag.updateNodeStatus(id, Error)
+					dag.emitResult(NodeResult{ID: id, Err: err})
+					recordSpanError(ctx, err)
+					scheduleSuccessors(ctx, id)
+					return
+				}
+				if err != nil {
+					logger.Debug("Error executing node", "error", err)
+					fail(ctx, id, err, dag.failFast)
+					return
+				}
 
-			// 依存先ノードの入力次数を更新し、実行可能になったノードを実行
+				// ノードの出力とメタデータを収集
+				nodeOutputs = n.GetOutputs()
+				nodeMetadata = metadataOf(n)
+				if cacheable && !abort {
+					dag.cache.Set(key, slices.Clone(nodeOutputs))
+				}
+			}
 			mu.Lock()
-			for _, toNode := range graph.NodesOf(dag.graph.From(dag.nodes[id].ID())) {
-				for toID, n := range dag.nodes {
-					if n.ID() == toNode.ID() {
-						dag.inDegree[toID]--
-						if dag.inDegree[toID] == 0 {
-							wg.Add(1)
-							go execNode(ctx, toID)
-						}
-						break
-					}
+			size := 0
+			for _, output := range nodeOutputs {
+				size += len(output)
+			}
+			if dag.maxTotalOutputBytes > 0 && totalOutputBytes+size > dag.maxTotalOutputBytes {
+				mu.Unlock()
+				err := fmt.Errorf("%w: node %s produced %d bytes, total would be %d of %d", ErrOutputLimitExceeded, id, size, totalOutputBytes+size, dag.maxTotalOutputBytes)
+				logger.Debug("Output limit exceeded", "error", err)
+				// 出力サイズの上限はサーバーを保護するためのものなので、fail-fastの設定に関わらず中断する
+				fail(ctx, id, err, true)
+				return
+			}
+			// Spawnerで要求されたノードとエッジを追加し、このノードの依存先としてスケジュールする
+			if spawner != nil {
+				added, err := dag.applySpawned(spawner, inDegree)
+				spawned = append(spawned, added...)
+				if err != nil {
+					mu.Unlock()
+					logger.Debug("Failed to add spawned nodes", "error", err)
+					fail(ctx, id, err, dag.failFast)
+					return
 				}
 			}
+			totalOutputBytes += size
+			outputs.put(id, nodeOutputs)
+			if keyed := keyedOutputsOf(n); keyed != nil && !hit {
+				keyedOutputs[id] = keyed
+			}
+			if nodeMetadata != nil {
+				metadata[id] = nodeMetadata
+			}
+			if abort {
+				aborted = true
+				stopped = true
+			}
+			if dag.stopCondition != nil && !stopped && dag.stopCondition(outputs.snapshot()) {
+				logger.Debug("Stop condition met")
+				stopped = true
+			}
 			mu.Unlock()
+			logger.Debug("Node outputs", "outputs", nodeOutputs)
+
+			// 入出力を通知してからノードの状態を更新
+			dag.notifyNodeIO(id, nodeInputs, nodeOutputs, nodeMetadata)
+			if hit {
+				dag.updateNodeStatus(id, Cached)
+			} else {
+				dag.updateNodeStatus(id, Completed)
+			}
+
+			scheduleSuccessors(ctx, id)
 		})
 	}
 
 	// トレースタスクを作成して実行を開始
 	ctx, task := trace.NewTask(ctx, "DAG Execution")
 	defer task.End()
+	ctx, endRunSpan := dag.startRunSpan(ctx)
 
 	// 入力次数が0のノード（実行可能なノード）から実行を開始
+	// 起動したノードが入力次数を減算し始める前に、実行可能なノードを確定させる
+	var roots []NodeID
 	for _, n := range sorted {
-		for id, node := range dag.nodes {
-			if node.ID() == n.ID() && dag.inDegree[id] == 0 {
-				wg.Add(1)
-				go execNode(ctx, id)
-			}
+		if id, ok := dag.nodeID(n.ID()); ok && inDegree[id] == 0 {
+			roots = append(roots, id)
 		}
 	}
 
-	wg.Wait()
+	// 実行が中断された場合に、実行枠を待っているディスパッチャーを起こす
+	stopWake := context.AfterFunc(ctx, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		cond.Broadcast()
+	})
+	defer stopWake()
 
-	close(dag.statusChan)
-	close(dag.ioChan)
+	// 実行可能なノードを優先度の高い順に実行枠へ割り当てる
+	// 実行枠を獲得したノードだけをExecutorに渡し、全てのノードが終わるまで待機する
+	executor := dag.executor
+	if executor == nil {
+		executor = goExecutor{}
+	}
+	sortReady(roots)
+	for _, id := range roots {
+		dag.updateNodeStatus(id, Ready)
+	}
+	mu.Lock()
+	for _, id := range roots {
+		queue.push(id, priority(id))
+	}
+	for {
+		// Pauseで一時停止している間は新しいノードを開始しない
+		for ctx.Err() == nil && !stopped && !shutdown && !dag.isPaused() {
+			item, ok := queue.pop(limiterFor)
+			if !ok {
+				break
+			}
+			l := limiterFor(item.id)
+			var wait time.Duration
+			if item.blocked {
+				wait = time.Since(item.readyAt)
+			}
+			l.record(item.blocked, wait)
+			running++
+			// Executorはワーカーの空きを待つ場合があるため、ロックを解放してから渡す
+			mu.Unlock()
+			executor.Go(func() {
+				execNode(ctx, item.id)
+				mu.Lock()
+				defer mu.Unlock()
+				l.release()
+				running--
+				cond.Broadcast()
+			})
+			mu.Lock()
+		}
+		halted := ctx.Err() != nil || stopped || shutdown
+		// 中断した場合はキューに残っているノードを実行せずに、実行中のノードの終了を待つ
+		if running == 0 && (halted || queue.len() == 0) {
+			break
+		}
+		cond.Wait()
+	}
+	// キャンセルにより実行枠を獲得できなかったノードは、実行せずにスキップしたものとする
+	var unstarted []NodeID
+	if ctx.Err() != nil {
+		for _, item := range queue.items {
+			unstarted = append(unstarted, item.id)
+		}
+	}
+	cancelled = cancelled || len(unstarted) > 0
+	mu.Unlock()
+	for _, id := range unstarted {
+		dag.nodeLogger(id).Debug("Execution cancelled while waiting for a slot")
+		dag.updateNodeStatus(id, Skipped)
+	}
 
-	if execErr != nil {
-		return nil, nil, execErr
+	// 依存元が失敗した、または実行が中断されたため実行可能にならなかったノードを報告する
+	unreached := dag.pendingNodes()
+	if len(unreached) > 0 {
+		dag.log().Warn("Nodes never became runnable", "nodes", unreached)
 	}
 
 	// リーフノードの出力を収集
+	// 出力を持つのは完了したノード（キャッシュの出力を使用したノードと、入力を渡した無効なノードを含む）だけなので、
+	// スキップしたノードや途中で停止したため実行されなかったノードは含まれない
+	allOutputs := outputs.snapshot()
 	for _, id := range dag.GetLeafNodes() {
-		finalOutputs[id] = outputs[id]
+		if output, exists := allOutputs[id]; exists {
+			finalOutputs[id] = output
+		}
 	}
 
-	return outputs, finalOutputs, nil
+	// 制限時間を超えた場合は、キャンセルにより失敗したノードのエラーよりも制限時間のエラーを優先する
+	if errors.Is(context.Cause(ctx), ErrWorkflowTimeout) {
+		if execErr != nil {
+			execErr = fmt.Errorf("%w: %w", ErrWorkflowTimeout, execErr)
+		} else {
+			execErr = ErrWorkflowTimeout
+		}
+	}
+	// ノードが失敗していなくても、キャンセルにより実行しなかったノードがある場合はキャンセルのエラーを返す
+	if execErr == nil && cancelled {
+		execErr = context.Cause(ctx)
+	}
+
+	status := RunCompleted
+	switch {
+	case execErr != nil:
+		status = RunFailed
+	case shutdown:
+		status = RunShutdown
+	case aborted:
+		status = RunAborted
+	case stopped:
+		status = RunStopped
+	}
+
+	endRunSpan(status, execErr)
+
+	groupStats := make(map[string]LimiterStats, len(groupSems))
+	for group, l := range groupSems {
+		groupStats[group] = l.Stats()
+	}
+
+	result := &ExecuteResult{
+		Status:            status,
+		Outputs:           allOutputs,
+		FinalOutputs:      finalOutputs,
+		LimiterStats:      sem.Stats(),
+		GroupLimiterStats: groupStats,
+		Unreached:         unreached,
+		Metadata:          metadata,
+	}
+	if dag.finalAggregator != nil {
+		result.Aggregated = dag.finalAggregator(finalOutputs)
+	} else {
+		result.Aggregated = result.flattenFinal()
+	}
+	return result, execErr
 }
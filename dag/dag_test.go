@@ -2,21 +2,65 @@ package dag_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/momiom/workflow/dag"
 	"github.com/momiom/workflow/node"
+
+	"golang.org/x/time/rate"
 )
 
 type MockLLMClient struct{}
 
-func (c *MockLLMClient) GenerateResponse(prompt string) (string, error) {
+func (c *MockLLMClient) GenerateResponse(ctx context.Context, prompt string) (string, error) {
 	return "mock response: " + prompt, nil
 }
 
+// drainChannelsは状態と入出力のチャネルを読み捨て、送信側がブロックしないようにします。
+// チャネルは呼び出し時点で取得するため、直後に開始した実行のチャネルを確実に読みます。
+func drainChannels(workflow *dag.DAG) {
+	statusChan, ioChan := workflow.GetStatusChan(), workflow.GetIOChan()
+	go func() {
+		for range statusChan {
+		}
+	}()
+	go func() {
+		for range ioChan {
+		}
+	}()
+}
+
+// recordStatusesは状態と入出力のチャネルを読み、ノードごとの最後の状態を記録します。
+// 返される関数は実行の終了後に呼び出し、チャネルが閉じられるまで待ってから記録を返します。
+func recordStatuses(workflow *dag.DAG) func() map[dag.NodeID]dag.NodeStatus {
+	statuses := make(map[dag.NodeID]dag.NodeStatus)
+	statusChan, ioChan := workflow.GetStatusChan(), workflow.GetIOChan()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for state := range statusChan {
+			statuses[state.ID] = state.Status
+		}
+	}()
+	go func() {
+		for range ioChan {
+		}
+	}()
+	return func() map[dag.NodeID]dag.NodeStatus {
+		<-done
+		return statuses
+	}
+}
+
 func TestDAG(t *testing.T) {
 	// テキストプロセッサ関数
 	textProcessor := func(inputs []string) (string, error) {
@@ -153,6 +197,7 @@ func TestDAG(t *testing.T) {
 				}
 			}
 
+			drainChannels(workflow)
 			nodeOutputs, finalOutputs, err := workflow.Execute(ctx, tt.inputs)
 			if (err != nil) != tt.expectError {
 				t.Fatalf("expected error: %v, got: %v", tt.expectError, err)
@@ -190,3 +235,1862 @@ func TestDAG(t *testing.T) {
 		})
 	}
 }
+
+func TestDAGInputsToIntermediateNode(t *testing.T) {
+	upper := func(inputs []string) (string, error) {
+		return strings.ToUpper(strings.Join(inputs, " ")), nil
+	}
+
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("first", node.NewTextNode("first", upper))
+	workflow.AddNode("second", node.NewTextNode("second", upper))
+	workflow.AddNode("collect", node.NewCollectNode("collect"))
+	if err := workflow.AddEdge("second", "collect"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if err := workflow.AddEdge("first", "collect"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	inputs := map[dag.NodeID][]string{
+		"first":   {"a"},
+		"second":  {"b"},
+		"collect": {"config"},
+	}
+
+	drainChannels(workflow)
+	_, finalOutputs, err := workflow.Execute(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 外部入力が先頭、その後ろに依存元の出力がエッジの追加順に並ぶ
+	expected := `["config","B","A"]`
+	if got := finalOutputs["collect"]; len(got) != 1 || got[0] != expected {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
+
+// changeDetectNodeは入力が前回の出力と同じ場合にスキップするノードです。
+type changeDetectNode struct {
+	inputs  []string
+	outputs []string
+}
+
+func (n *changeDetectNode) Execute(ctx context.Context) error {
+	if prev, ok := dag.PreviousResult(ctx); ok && slices.Equal(prev, n.inputs) {
+		return node.ErrSkip
+	}
+	n.outputs = n.inputs
+	return nil
+}
+
+func (n *changeDetectNode) Name() string              { return "changeDetect" }
+func (n *changeDetectNode) SetInputs(inputs []string) { n.inputs = inputs }
+func (n *changeDetectNode) GetOutputs() []string      { return n.outputs }
+func (n *changeDetectNode) Reset()                    { n.inputs, n.outputs = nil, nil }
+
+func TestDAGPreviousResult(t *testing.T) {
+	run := func(ctx context.Context, value string) (map[dag.NodeID][]string, map[dag.NodeID]dag.NodeStatus) {
+		workflow := dag.NewDAG(1)
+		workflow.AddNode("detect", &changeDetectNode{})
+
+		statuses := recordStatuses(workflow)
+		outputs, _, err := workflow.Execute(ctx, map[dag.NodeID][]string{"detect": {value}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return outputs, statuses()
+	}
+
+	// 初回は前回の結果がないため実行される
+	first, statuses := run(context.Background(), "42")
+	if statuses["detect"] != dag.Completed {
+		t.Fatalf("expected %s, got %s", dag.Completed, statuses["detect"])
+	}
+
+	// 前回と同じ値ならスキップされる
+	outputs, statuses := run(dag.WithPreviousResult(context.Background(), first), "42")
+	if statuses["detect"] != dag.Skipped {
+		t.Fatalf("expected %s, got %s", dag.Skipped, statuses["detect"])
+	}
+	if _, exists := outputs["detect"]; exists {
+		t.Fatalf("expected no output for skipped node, got %v", outputs["detect"])
+	}
+
+	// 値が変わっていれば再び実行される
+	outputs, statuses = run(dag.WithPreviousResult(context.Background(), first), "43")
+	if statuses["detect"] != dag.Completed {
+		t.Fatalf("expected %s, got %s", dag.Completed, statuses["detect"])
+	}
+	if got := outputs["detect"]; !slices.Equal(got, []string{"43"}) {
+		t.Fatalf("expected [43], got %v", got)
+	}
+}
+
+// splitterNodeは入力を"first"と"rest"のキーに振り分けるノードです。
+type splitterNode struct {
+	inputs  []string
+	outputs map[string][]string
+}
+
+func (n *splitterNode) Execute(ctx context.Context) error {
+	if len(n.inputs) == 0 {
+		return fmt.Errorf("input must not be empty")
+	}
+	n.outputs = map[string][]string{
+		"first": n.inputs[:1],
+		"rest":  n.inputs[1:],
+	}
+	return nil
+}
+
+func (n *splitterNode) Name() string              { return "splitter" }
+func (n *splitterNode) SetInputs(inputs []string) { n.inputs = inputs }
+func (n *splitterNode) GetOutputs() []string      { return n.inputs }
+func (n *splitterNode) Reset()                    { n.inputs, n.outputs = nil, nil }
+
+func (n *splitterNode) GetKeyedOutputs() map[string][]string { return n.outputs }
+
+func TestDAGEdgeOutput(t *testing.T) {
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("splitter", &splitterNode{})
+	workflow.AddNode("first", node.NewCollectNode("first"))
+	workflow.AddNode("rest", node.NewCollectNode("rest"))
+	workflow.AddNode("all", node.NewCollectNode("all"))
+	workflow.AddNode("missing", node.NewCollectNode("missing"))
+	for _, to := range []dag.NodeID{"first", "rest", "all", "missing"} {
+		if err := workflow.AddEdge("splitter", to); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+	}
+	for to, key := range map[dag.NodeID]string{"first": "first", "rest": "rest", "missing": "unknown"} {
+		if err := workflow.SetEdgeOutput("splitter", to, key); err != nil {
+			t.Fatalf("failed to set edge output: %v", err)
+		}
+	}
+
+	drainChannels(workflow)
+	_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"splitter": {"a", "b", "c"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[dag.NodeID]string{
+		"first":   `["a"]`,
+		"rest":    `["b","c"]`,
+		"all":     `["a","b","c"]`, // キー指定のないエッジにはGetOutputsが渡る
+		"missing": `[]`,            // 存在しないキーのエッジからは何も渡らない
+	}
+	for id, want := range expected {
+		if got := finalOutputs[id]; len(got) != 1 || got[0] != want {
+			t.Fatalf("expected %s for node %s, got %v", want, id, got)
+		}
+	}
+}
+
+func TestDAGEdgeTransform(t *testing.T) {
+	errTransform := errors.New("transform failed")
+	upper := func(outputs []string) ([]string, error) {
+		for i, output := range outputs {
+			outputs[i] = strings.ToUpper(output)
+		}
+		return outputs, nil
+	}
+	failing := func([]string) ([]string, error) {
+		return nil, errTransform
+	}
+
+	tests := []struct {
+		name           string
+		transform      func([]string) ([]string, error)
+		expectedFinal  []string
+		expectedError  error
+		expectedStatus dag.NodeStatus
+	}{
+		{"Uppercase in transit", upper, []string{"HELLO WORLD!"}, nil, dag.Completed},
+		{"Transform error fails the consumer", failing, nil, errTransform, dag.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := dag.NewDAG(1)
+			workflow.AddNode("producer", node.NewTextNode("producer", func(inputs []string) (string, error) {
+				return strings.Join(inputs, " "), nil
+			}))
+			workflow.AddNode("consumer", node.NewTextNode("consumer", func(inputs []string) (string, error) {
+				return inputs[0] + "!", nil
+			}))
+			if err := workflow.AddEdgeWithTransform("producer", "consumer", tt.transform); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+
+			statuses := recordStatuses(workflow)
+			outputs, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"producer": {"hello", "world"}})
+			if got := statuses()["consumer"]; got != tt.expectedStatus {
+				t.Fatalf("expected consumer to be %s, got %s", tt.expectedStatus, got)
+			}
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Fatalf("expected %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(finalOutputs["consumer"], tt.expectedFinal) {
+				t.Fatalf("expected %v, got %v", tt.expectedFinal, finalOutputs["consumer"])
+			}
+			// 変換は依存元ノードの出力そのものを変更しない
+			if !slices.Equal(outputs["producer"], []string{"hello world"}) {
+				t.Fatalf("expected producer output to be unchanged, got %v", outputs["producer"])
+			}
+		})
+	}
+}
+
+func TestDAGOptionalEdge(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, "+"), nil
+	}
+
+	t.Run("Consumer runs without a slow optional upstream", func(t *testing.T) {
+		workflow := dag.NewDAG(2)
+		slow := &sleepNode{name: "slow", duration: 200 * time.Millisecond}
+		workflow.AddNode("slow", slow)
+		workflow.AddNode("fast", node.NewTextNode("fast", join))
+		var slowDone bool
+		workflow.AddNode("consumer", node.NewTextNode("consumer", func(inputs []string) (string, error) {
+			slowDone = slow.completed.Load()
+			return join(inputs)
+		}))
+		if err := workflow.AddOptionalEdge("slow", "consumer"); err != nil {
+			t.Fatalf("failed to add optional edge: %v", err)
+		}
+		if err := workflow.AddEdge("fast", "consumer"); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+		drainChannels(workflow)
+
+		inputs := map[dag.NodeID][]string{"slow": {"late"}, "fast": {"now"}}
+		outputs, _, err := workflow.Execute(context.Background(), inputs)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if slowDone {
+			t.Fatal("expected consumer to run before the optional upstream completed")
+		}
+		if !slices.Equal(outputs["consumer"], []string{"now"}) {
+			t.Fatalf("expected consumer to run without the optional input, got %v", outputs["consumer"])
+		}
+		// 任意のエッジの依存元も最後まで実行される
+		if !slices.Equal(outputs["slow"], []string{"late"}) {
+			t.Fatalf("expected slow to complete, got %v", outputs["slow"])
+		}
+	})
+
+	t.Run("Consumer receives a completed optional upstream", func(t *testing.T) {
+		// optionalはrequiredの依存元でもあるため、consumerの実行時には必ず完了している
+		workflow := dag.NewDAG(2)
+		workflow.AddNode("optional", node.NewTextNode("optional", join))
+		workflow.AddNode("required", node.NewTextNode("required", join))
+		workflow.AddNode("consumer", node.NewTextNode("consumer", join))
+		if err := workflow.AddEdge("optional", "required"); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+		if err := workflow.AddOptionalEdge("optional", "consumer"); err != nil {
+			t.Fatalf("failed to add optional edge: %v", err)
+		}
+		if err := workflow.AddEdge("required", "consumer"); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+		drainChannels(workflow)
+
+		outputs, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"optional": {"a"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(outputs["consumer"], []string{"a+a"}) {
+			t.Fatalf("expected consumer to receive both inputs, got %v", outputs["consumer"])
+		}
+	})
+}
+
+func TestDAGNodeFunc(t *testing.T) {
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("split", node.NewFunc("split", func(inputs []string) ([]string, error) {
+		return strings.Fields(strings.Join(inputs, " ")), nil
+	}))
+	workflow.AddNode("count", node.NewFunc("count", func(inputs []string) ([]string, error) {
+		return []string{strconv.Itoa(len(inputs))}, nil
+	}))
+	if err := workflow.AddEdge("split", "count"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	drainChannels(workflow)
+
+	outputs, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"split": {"a b", "c"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(outputs["split"], []string{"a", "b", "c"}) {
+		t.Fatalf("expected [a b c], got %v", outputs["split"])
+	}
+	if !slices.Equal(finalOutputs["count"], []string{"3"}) {
+		t.Fatalf("expected [3], got %v", finalOutputs["count"])
+	}
+}
+
+func TestDAGMultiTextNode(t *testing.T) {
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("csv", node.NewMultiTextNode("csv", func(inputs []string) ([]string, error) {
+		return strings.Split(inputs[0], ","), nil
+	}))
+	workflow.AddNode("upper", node.NewMultiTextNode("upper", func(inputs []string) ([]string, error) {
+		outputs := make([]string, len(inputs))
+		for i, input := range inputs {
+			outputs[i] = strings.ToUpper(input)
+		}
+		return outputs, nil
+	}))
+	workflow.AddNode("count", node.NewTextNode("count", func(inputs []string) (string, error) {
+		return strconv.Itoa(len(inputs)), nil
+	}))
+	for _, edge := range [][2]dag.NodeID{{"csv", "upper"}, {"csv", "count"}} {
+		if err := workflow.AddEdge(edge[0], edge[1]); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+	}
+	drainChannels(workflow)
+
+	// 分割した全ての値が各依存先ノードに渡される
+	_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"csv": {"x,y,z"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(finalOutputs["upper"], []string{"X", "Y", "Z"}) {
+		t.Fatalf("expected [X Y Z], got %v", finalOutputs["upper"])
+	}
+	if !slices.Equal(finalOutputs["count"], []string{"3"}) {
+		t.Fatalf("expected [3], got %v", finalOutputs["count"])
+	}
+}
+
+func TestDAGSetEdgeOutputErrors(t *testing.T) {
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("splitter", &splitterNode{})
+	workflow.AddNode("text", node.NewCollectNode("text"))
+	workflow.AddNode("sink", node.NewCollectNode("sink"))
+	if err := workflow.AddEdge("text", "sink"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	if err := workflow.SetEdgeOutput("splitter", "sink", "first"); err == nil {
+		t.Fatalf("expected error for missing edge")
+	}
+	if err := workflow.SetEdgeOutput("text", "sink", "first"); err == nil {
+		t.Fatalf("expected error for node without keyed outputs")
+	}
+}
+
+// sleepNodeは指定時間待機してから入力をそのまま出力するノードです。
+// コンテキストがキャンセルされた場合は待機を中断してエラーを返します。
+type sleepNode struct {
+	name      string
+	duration  time.Duration
+	inputs    []string
+	outputs   []string
+	completed atomic.Bool
+}
+
+func (n *sleepNode) Execute(ctx context.Context) error {
+	select {
+	case <-time.After(n.duration):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	n.outputs = n.inputs
+	n.completed.Store(true)
+	return nil
+}
+
+func (n *sleepNode) Name() string              { return n.name }
+func (n *sleepNode) SetInputs(inputs []string) { n.inputs = inputs }
+func (n *sleepNode) GetOutputs() []string      { return n.outputs }
+func (n *sleepNode) Reset()                    { n.inputs, n.outputs = nil, nil }
+
+func TestDAGFailFast(t *testing.T) {
+	errFast := errors.New("fast failure")
+	failing := func(inputs []string) (string, error) {
+		return "", errFast
+	}
+
+	tests := []struct {
+		name            string
+		opts            []dag.Option
+		expectCompleted bool
+	}{
+		{"Fail-fast cancels the slow node", []dag.Option{dag.WithFailFast()}, false},
+		{"Default mode lets the slow node finish", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slow := &sleepNode{name: "slow", duration: 500 * time.Millisecond}
+			after := &sleepNode{name: "after"}
+
+			workflow := dag.NewDAG(2, tt.opts...)
+			workflow.AddNode("fail", node.NewTextNode("fail", failing))
+			workflow.AddNode("slow", slow)
+			workflow.AddNode("after", after)
+			if err := workflow.AddEdge("slow", "after"); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+
+			drainChannels(workflow)
+			_, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"slow": {"input"}})
+			if !errors.Is(err, errFast) {
+				t.Fatalf("expected %v, got %v", errFast, err)
+			}
+
+			if slow.completed.Load() != tt.expectCompleted {
+				t.Fatalf("expected slow node completed: %v, got: %v", tt.expectCompleted, slow.completed.Load())
+			}
+			if after.completed.Load() != tt.expectCompleted {
+				t.Fatalf("expected after node completed: %v, got: %v", tt.expectCompleted, after.completed.Load())
+			}
+		})
+	}
+}
+
+func TestDAGMaxTotalOutputBytes(t *testing.T) {
+	double := func(inputs []string) (string, error) {
+		return strings.Repeat(strings.Join(inputs, ""), 2), nil
+	}
+
+	tests := []struct {
+		name        string
+		limit       int
+		expectError bool
+	}{
+		{"Within the limit", 70, false},
+		{"Exceeding the limit aborts", 50, true},
+		{"No limit", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := dag.NewDAG(1)
+			workflow.AddNode("first", node.NewTextNode("first", double))
+			workflow.AddNode("second", node.NewTextNode("second", double))
+			workflow.AddNode("third", node.NewTextNode("third", double))
+			if err := workflow.AddEdge("first", "second"); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+			if err := workflow.AddEdge("second", "third"); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+			workflow.SetMaxTotalOutputBytes(tt.limit)
+
+			// 出力は10, 20, 40バイトで合計70バイト
+			drainChannels(workflow)
+			_, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"first": {"abcde"}})
+			if (err != nil) != tt.expectError {
+				t.Fatalf("expected error: %v, got: %v", tt.expectError, err)
+			}
+			if tt.expectError && !errors.Is(err, dag.ErrOutputLimitExceeded) {
+				t.Fatalf("expected %v, got %v", dag.ErrOutputLimitExceeded, err)
+			}
+		})
+	}
+}
+
+func TestDAGRepeatedExecute(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+
+	// 循環するグラフはエラーを返し、購読側のチャネルも閉じられる
+	cyclic := dag.NewDAG(1)
+	cyclic.AddNode("a", node.NewTextNode("a", join))
+	cyclic.AddNode("b", node.NewTextNode("b", join))
+	if err := cyclic.AddEdge("a", "b"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if err := cyclic.AddEdge("b", "a"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		statusChan := cyclic.GetStatusChan()
+		if _, _, err := cyclic.Execute(context.Background(), nil); err == nil {
+			t.Fatalf("expected error for cyclic graph")
+		}
+		if _, ok := <-statusChan; ok {
+			t.Fatalf("expected status channel to be closed")
+		}
+	}
+
+	// 正しいグラフは何度実行しても同じ結果になる
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("a", node.NewTextNode("a", join))
+	workflow.AddNode("b", node.NewTextNode("b", join))
+	if err := workflow.AddEdge("a", "b"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		drainChannels(workflow)
+		_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"a": {"hello", "world"}})
+		if err != nil {
+			t.Fatalf("run %d: unexpected error: %v", i, err)
+		}
+		if got := finalOutputs["b"]; !slices.Equal(got, []string{"hello world"}) {
+			t.Fatalf("run %d: expected [hello world], got %v", i, got)
+		}
+	}
+}
+
+func TestDAGLimiterStats(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxConcurrent int
+		expectWait    bool
+	}{
+		{"Constrained concurrency records waits", 1, true},
+		{"Sufficient concurrency does not wait", 3, false},
+		{"Zero concurrency is unbounded", 0, false},
+		{"Negative concurrency is unbounded", -1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := dag.NewDAG(tt.maxConcurrent)
+			for _, id := range []dag.NodeID{"a", "b", "c"} {
+				workflow.AddNode(id, &sleepNode{name: string(id), duration: 20 * time.Millisecond})
+			}
+
+			drainChannels(workflow)
+			result, err := workflow.Run(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			stats := result.LimiterStats
+			if stats.Acquired != 3 {
+				t.Fatalf("expected 3 acquisitions, got %d", stats.Acquired)
+			}
+			if tt.expectWait {
+				if stats.Blocked == 0 || stats.TotalWait <= 0 || stats.AverageWait() <= 0 {
+					t.Fatalf("expected recorded waits, got %+v", stats)
+				}
+			} else if stats.Blocked != 0 || stats.TotalWait != 0 {
+				t.Fatalf("expected no waits, got %+v", stats)
+			}
+		})
+	}
+}
+
+// startRecorderはノードの実行開始時刻を記録します。
+type startRecorder struct {
+	mu     sync.Mutex
+	starts map[dag.NodeID]time.Time
+}
+
+func (r *startRecorder) node(id dag.NodeID) node.Node {
+	return node.NewTextNode(string(id), func(inputs []string) (string, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.starts == nil {
+			r.starts = make(map[dag.NodeID]time.Time)
+		}
+		r.starts[id] = time.Now()
+		return string(id), nil
+	})
+}
+
+func TestDAGRateLimiter(t *testing.T) {
+	interval := 30 * time.Millisecond
+	recorder := &startRecorder{}
+
+	workflow := dag.NewDAG(4, dag.WithRateLimiter(rate.NewLimiter(rate.Every(interval), 1)))
+	for _, id := range []dag.NodeID{"a", "b", "c", "d"} {
+		workflow.AddNode(id, recorder.node(id))
+	}
+
+	drainChannels(workflow)
+	if _, _, err := workflow.Execute(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var starts []time.Time
+	for _, start := range recorder.starts {
+		starts = append(starts, start)
+	}
+	slices.SortFunc(starts, func(a, b time.Time) int { return a.Compare(b) })
+	// 個々の間隔はゴルーチンのスケジューリングで揺らぐため、最初から最後までの間隔で確認する
+	// タイマーの誤差を考慮して多少の余裕を持たせる
+	expected := interval * time.Duration(len(starts)-1)
+	if span := starts[len(starts)-1].Sub(starts[0]); span < expected*8/10 {
+		t.Fatalf("expected executions spread over at least %v, got %v", expected, span)
+	}
+}
+
+func TestDAGRateLimiterCancellation(t *testing.T) {
+	recorder := &startRecorder{}
+
+	workflow := dag.NewDAG(2, dag.WithRateLimiter(rate.NewLimiter(rate.Every(time.Hour), 1)))
+	workflow.AddNode("a", recorder.node("a"))
+	workflow.AddNode("b", recorder.node("b"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	drainChannels(workflow)
+	if _, _, err := workflow.Execute(ctx, nil); err == nil {
+		t.Fatalf("expected error while waiting on the rate limiter")
+	}
+	if len(recorder.starts) != 1 {
+		t.Fatalf("expected only one node to run, got %v", recorder.starts)
+	}
+}
+
+// concurrencyTrackerは同時に実行されているノード数の最大値を記録します。
+type concurrencyTracker struct {
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (c *concurrencyTracker) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	n := c.running.Add(1)
+	defer c.running.Add(-1)
+	for {
+		peak := c.peak.Load()
+		if n <= peak || c.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return "response: " + prompt, nil
+}
+
+func TestDAGGroupLimit(t *testing.T) {
+	llm := &concurrencyTracker{}
+	text := &concurrencyTracker{}
+
+	ids := []dag.NodeID{"llm1", "llm2", "llm3", "llm4", "llm5"}
+	opts := []dag.Option{dag.WithGroupLimit("llm", 2)}
+	for _, id := range ids {
+		opts = append(opts, dag.WithGroup(id, "llm"))
+	}
+	workflow := dag.NewDAG(10, opts...)
+	inputs := make(map[dag.NodeID][]string)
+	for _, id := range ids {
+		workflow.AddNode(id, node.NewLLMNode(string(id), llm))
+		inputs[id] = []string{string(id)}
+	}
+	// グループに所属しないノードは全体の上限で並列に実行される
+	for _, id := range []dag.NodeID{"text1", "text2", "text3"} {
+		workflow.AddNode(id, node.NewLLMNode(string(id), text))
+		inputs[id] = []string{string(id)}
+	}
+
+	drainChannels(workflow)
+	result, err := workflow.Run(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if peak := llm.peak.Load(); peak > 2 {
+		t.Fatalf("expected at most 2 concurrent llm nodes, got %d", peak)
+	}
+	if stats := result.GroupLimiterStats["llm"]; stats.Acquired != len(ids) || stats.Blocked == 0 {
+		t.Fatalf("expected llm group to be constrained, got %+v", stats)
+	}
+	if stats := result.LimiterStats; stats.Acquired != 3 {
+		t.Fatalf("expected 3 acquisitions on the global limiter, got %+v", stats)
+	}
+}
+
+// mockStreamingLLMClientは応答を3つの断片に分けてストリーミングするクライアントです。
+type mockStreamingLLMClient struct{}
+
+func (c *mockStreamingLLMClient) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	return "mock response: " + prompt, nil
+}
+
+func (c *mockStreamingLLMClient) GenerateStream(ctx context.Context, prompt string) (<-chan string, error) {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for _, chunk := range []string{"mock ", "response: ", prompt} {
+			select {
+			case ch <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func TestDAGStreaming(t *testing.T) {
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("llm", node.NewLLMNode("llm", &mockStreamingLLMClient{}))
+	workflow.AddNode("silent", node.NewLLMNode("silent", &mockStreamingLLMClient{}))
+	workflow.AddNode("collect", node.NewCollectNode("collect"))
+	if err := workflow.AddEdge("llm", "collect"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	// ストリームを購読するのはllmノードだけで、silentノードの断片は破棄される
+	var chunks []string
+	stream := workflow.GetStreamChan("llm")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for chunk := range stream {
+			chunks = append(chunks, chunk)
+		}
+	}()
+
+	drainChannels(workflow)
+	outputs, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{
+		"llm":    {"hello"},
+		"silent": {"ignored"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	<-done
+
+	if !slices.Equal(chunks, []string{"mock ", "response: ", "hello"}) {
+		t.Fatalf("expected 3 streamed chunks, got %q", chunks)
+	}
+	if got := outputs["collect"]; !slices.Equal(got, []string{`["mock response: hello"]`}) {
+		t.Fatalf("expected assembled output downstream, got %v", got)
+	}
+}
+
+func TestDAGSetRootInputProvider(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+
+	workflow := dag.NewDAG(3)
+	for _, id := range []dag.NodeID{"root1", "root2", "root3", "child"} {
+		workflow.AddNode(id, node.NewTextNode(string(id), join))
+	}
+	if err := workflow.AddEdge("root1", "child"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	workflow.SetRootInputProvider(func(id dag.NodeID) ([]string, bool) {
+		if id == "child" {
+			t.Errorf("provider must not be called for non-root node %s", id)
+		}
+		if id == "root3" {
+			return nil, false
+		}
+		return []string{"default for " + string(id)}, true
+	})
+
+	inputs := map[dag.NodeID][]string{"root2": {"explicit"}}
+	drainChannels(workflow)
+	outputs, _, err := workflow.Execute(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[dag.NodeID]string{
+		"root1": "default for root1",
+		"root2": "explicit",
+		"root3": "",
+		"child": "default for root1",
+	}
+	for id, want := range expected {
+		if got := outputs[id]; !slices.Equal(got, []string{want}) {
+			t.Fatalf("expected [%s] for node %s, got %v", want, id, got)
+		}
+	}
+	if len(inputs) != 1 {
+		t.Fatalf("expected caller's inputs to be unchanged, got %v", inputs)
+	}
+}
+
+func TestDAGSetStopCondition(t *testing.T) {
+	var executed []dag.NodeID
+	var mu sync.Mutex
+	step := func(id dag.NodeID) node.Node {
+		return node.NewTextNode(string(id), func(inputs []string) (string, error) {
+			mu.Lock()
+			executed = append(executed, id)
+			mu.Unlock()
+			return string(id), nil
+		})
+	}
+
+	workflow := dag.NewDAG(1)
+	chain := []dag.NodeID{"a", "b", "c", "d"}
+	for i, id := range chain {
+		workflow.AddNode(id, step(id))
+		if i > 0 {
+			if err := workflow.AddEdge(chain[i-1], id); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+		}
+	}
+	workflow.SetStopCondition(func(outputs map[dag.NodeID][]string) bool {
+		_, ok := outputs["b"]
+		return ok
+	})
+
+	drainChannels(workflow)
+	result, err := workflow.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Status != dag.RunStopped {
+		t.Fatalf("expected status %s, got %s", dag.RunStopped, result.Status)
+	}
+	if !slices.Equal(executed, []dag.NodeID{"a", "b"}) {
+		t.Fatalf("expected only a and b to execute, got %v", executed)
+	}
+	if len(result.Outputs) != 2 || len(result.FinalOutputs) != 0 {
+		t.Fatalf("expected outputs of a and b and no final outputs, got %v and %v", result.Outputs, result.FinalOutputs)
+	}
+}
+
+func TestDAGGetRootNodes(t *testing.T) {
+	workflow := dag.NewDAG(2)
+	for _, id := range []dag.NodeID{"root2", "root1", "left", "right", "join"} {
+		workflow.AddNode(id, node.NewTextNode(string(id), func(inputs []string) (string, error) {
+			return strings.Join(inputs, " "), nil
+		}))
+	}
+	for _, edge := range [][]dag.NodeID{{"root1", "left"}, {"root2", "right"}, {"left", "join"}, {"right", "join"}} {
+		if err := workflow.AddEdge(edge[0], edge[1]); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+	}
+
+	expected := []dag.NodeID{"root1", "root2"}
+	if roots := workflow.GetRootNodes(); !slices.Equal(roots, expected) {
+		t.Fatalf("expected %v, got %v", expected, roots)
+	}
+
+	// 実行後も入力次数の変化の影響を受けない
+	drainChannels(workflow)
+	if _, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"root1": {"a"}, "root2": {"b"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if roots := workflow.GetRootNodes(); !slices.Equal(roots, expected) {
+		t.Fatalf("expected %v after execution, got %v", expected, roots)
+	}
+}
+
+func TestDAGIOHistory(t *testing.T) {
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("a", node.NewTextNode("a", func(inputs []string) (string, error) {
+		return strings.ToUpper(inputs[0]), nil
+	}))
+	workflow.AddNode("skip", node.NewTextNode("skip", func(inputs []string) (string, error) {
+		return "", node.ErrSkip
+	}))
+	workflow.AddNode("b", node.NewTextNode("b", func(inputs []string) (string, error) {
+		return inputs[0] + "!", nil
+	}))
+	for _, edge := range [][2]dag.NodeID{{"a", "b"}, {"a", "skip"}} {
+		if err := workflow.AddEdge(edge[0], edge[1]); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+	}
+
+	for run := range 2 {
+		statuses := recordStatuses(workflow)
+		if _, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"a": {"hi"}}); err != nil {
+			t.Fatalf("run %d: unexpected error: %v", run, err)
+		}
+		completed := 0
+		for _, status := range statuses() {
+			if status == dag.Completed {
+				completed++
+			}
+		}
+
+		// 履歴は直近の実行の分だけを、完了したノードごとに1件ずつ保持する
+		history := workflow.IOHistory()
+		if len(history) != completed {
+			t.Fatalf("run %d: expected %d entries, got %d: %v", run, completed, len(history), history)
+		}
+		if history[0].ID != "a" || !slices.Equal(history[0].Outputs, []string{"HI"}) {
+			t.Fatalf("run %d: expected a to complete first with [HI], got %+v", run, history[0])
+		}
+		if last := history[len(history)-1]; last.ID != "b" || !slices.Equal(last.Inputs, []string{"HI"}) || !slices.Equal(last.Outputs, []string{"HI!"}) {
+			t.Fatalf("run %d: expected b with [HI] -> [HI!], got %+v", run, last)
+		}
+	}
+}
+
+func TestDAGNodePanic(t *testing.T) {
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("bad", node.NewTextNode("bad", func(inputs []string) (string, error) {
+		return inputs[5], nil
+	}))
+	workflow.AddNode("good", node.NewTextNode("good", func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}))
+
+	// パニックはノードのエラーとして扱われ、他のノードは実行を続ける
+	statuses := recordStatuses(workflow)
+	outputs, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"bad": {"x"}, "good": {"ok"}})
+	var panicErr *dag.PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected PanicError, got %v", err)
+	}
+	if !strings.Contains(panicErr.Error(), "index out of range") || len(panicErr.Stack) == 0 {
+		t.Fatalf("expected recovered value and stack, got %q with %d bytes of stack", panicErr.Error(), len(panicErr.Stack))
+	}
+	got := statuses()
+	if got["bad"] != dag.Error || got["good"] != dag.Completed {
+		t.Fatalf("expected bad to be Error and good to be Completed, got %v", got)
+	}
+	if !slices.Equal(outputs["good"], []string{"ok"}) {
+		t.Fatalf("expected good output [ok], got %v", outputs["good"])
+	}
+}
+
+func TestDAGProgress(t *testing.T) {
+	release := make(chan struct{})
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("fast", node.NewTextNode("fast", func(inputs []string) (string, error) {
+		return "fast", nil
+	}))
+	workflow.AddNode("slow", node.NewTextNode("slow", func(inputs []string) (string, error) {
+		<-release
+		return "slow", nil
+	}))
+	if err := workflow.AddEdge("fast", "slow"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	if p := workflow.Progress(); p != 0 {
+		t.Fatalf("expected progress 0 before run, got %v", p)
+	}
+
+	// slowが開始した時点ではfastだけが完了している
+	events := workflow.GetEvents()
+	midway := make(chan float64, 1)
+	go func() {
+		for e := range events {
+			if e, ok := e.(dag.StatusEvent); ok && e.ID == "slow" && e.Status == dag.Running {
+				midway <- workflow.Progress()
+				close(release)
+			}
+		}
+	}()
+
+	if _, _, err := workflow.Execute(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p := <-midway; p != 0.5 {
+		t.Fatalf("expected progress 0.5 during run, got %v", p)
+	}
+	if p := workflow.Progress(); p != 1 {
+		t.Fatalf("expected progress 1 after run, got %v", p)
+	}
+}
+
+func TestDAGPartialOutputsOnError(t *testing.T) {
+	errMiddle := errors.New("middle failed")
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("first", node.NewTextNode("first", func(inputs []string) (string, error) {
+		return strings.ToUpper(inputs[0]), nil
+	}))
+	workflow.AddNode("middle", node.NewTextNode("middle", func(inputs []string) (string, error) {
+		return "", errMiddle
+	}))
+	workflow.AddNode("last", node.NewTextNode("last", func(inputs []string) (string, error) {
+		return inputs[0], nil
+	}))
+	for _, edge := range [][2]dag.NodeID{{"first", "middle"}, {"middle", "last"}} {
+		if err := workflow.AddEdge(edge[0], edge[1]); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+	}
+	drainChannels(workflow)
+
+	outputs, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"first": {"hello"}})
+	if !errors.Is(err, errMiddle) {
+		t.Fatalf("expected %v, got %v", errMiddle, err)
+	}
+	// 失敗する前に完了したノードの出力は返される
+	if outputs == nil || !slices.Equal(outputs["first"], []string{"HELLO"}) {
+		t.Fatalf("expected first output to be returned, got %v", outputs)
+	}
+	if _, exists := outputs["middle"]; exists {
+		t.Fatalf("expected no output for the failed node, got %v", outputs["middle"])
+	}
+	if finalOutputs == nil || len(finalOutputs) != 0 {
+		t.Fatalf("expected empty final outputs, got %v", finalOutputs)
+	}
+
+	drainChannels(workflow)
+	result, err := workflow.Run(context.Background(), map[dag.NodeID][]string{"first": {"hello"}})
+	if err == nil || result == nil || result.Status != dag.RunFailed {
+		t.Fatalf("expected a Failed result with the error, got %v, %v", result, err)
+	}
+}
+
+func TestDAGFinalOutputsOnlyCompletedLeaves(t *testing.T) {
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("root", node.NewTextNode("root", func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}))
+	workflow.AddNode("done", node.NewTextNode("done", func(inputs []string) (string, error) {
+		return inputs[0] + " done", nil
+	}))
+	workflow.AddNode("skipped", node.NewMergeNode("skipped", func([]string) ([]string, error) {
+		return nil, node.ErrSkip
+	}))
+	for _, edge := range [][]dag.NodeID{{"root", "done"}, {"root", "skipped"}} {
+		if err := workflow.AddEdge(edge[0], edge[1]); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+	}
+
+	if leaves := workflow.GetLeafNodes(); !slices.Equal(leaves, []dag.NodeID{"done", "skipped"}) {
+		t.Fatalf("expected leaves [done skipped], got %v", leaves)
+	}
+
+	drainChannels(workflow)
+	_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"root": {"work"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(finalOutputs) != 1 || !slices.Equal(finalOutputs["done"], []string{"work done"}) {
+		t.Fatalf("expected only the completed leaf, got %v", finalOutputs)
+	}
+}
+
+func TestDAGCancelWhileSaturated(t *testing.T) {
+	var ran atomic.Int32
+	workflow := dag.NewDAG(1, dag.WithPriority("blocker", 1))
+	workflow.AddNode("blocker", &sleepNode{name: "blocker", duration: time.Hour})
+	for _, id := range []dag.NodeID{"a", "b", "c"} {
+		workflow.AddNode(id, node.NewTextNode(string(id), func(inputs []string) (string, error) {
+			ran.Add(1)
+			return string(id), nil
+		}))
+	}
+
+	// blockerが実行枠を占有した後にキャンセルする
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	statuses := make(map[dag.NodeID]dag.NodeStatus)
+	done := make(chan struct{})
+	events := workflow.GetEvents()
+	go func() {
+		defer close(done)
+		for e := range events {
+			if e, ok := e.(dag.StatusEvent); ok {
+				statuses[e.ID] = e.Status
+				if e.ID == "blocker" && e.Status == dag.Running {
+					cancel()
+				}
+			}
+		}
+	}()
+
+	_, _, err := workflow.Execute(ctx, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	<-done
+
+	if n := ran.Load(); n != 0 {
+		t.Fatalf("expected pending nodes not to run, got %d executions", n)
+	}
+	for _, id := range []dag.NodeID{"a", "b", "c"} {
+		if statuses[id] != dag.Skipped {
+			t.Fatalf("expected %s to be %s, got %s", id, dag.Skipped, statuses[id])
+		}
+	}
+}
+
+func TestDAGCancelBeforeExecute(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ran atomic.Int32
+	workflow := dag.NewDAG(1)
+	// 最初のノードは正常に完了するが、完了前に実行をキャンセルする
+	workflow.AddNode("first", &ctxNode{fn: func(ctx context.Context, inputs []string) ([]string, error) {
+		cancel()
+		return []string{"first"}, nil
+	}})
+	for _, id := range []dag.NodeID{"second", "third"} {
+		workflow.AddNode(id, node.NewTextNode(string(id), func(inputs []string) (string, error) {
+			ran.Add(1)
+			return string(id), nil
+		}))
+	}
+	workflow.AddEdge("first", "second")
+	workflow.AddEdge("second", "third")
+
+	wait := recordStatuses(workflow)
+	_, _, err := workflow.Execute(ctx, nil)
+	// ノードは失敗していないが、キャンセルにより実行されなかったノードがあるためキャンセルのエラーとなる
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	statuses := wait()
+
+	if n := ran.Load(); n != 0 {
+		t.Fatalf("expected later nodes not to run, got %d executions", n)
+	}
+	if statuses["first"] != dag.Completed {
+		t.Fatalf("expected first to be %s, got %s", dag.Completed, statuses["first"])
+	}
+	for _, id := range []dag.NodeID{"second", "third"} {
+		if statuses[id] != dag.Skipped {
+			t.Fatalf("expected %s to be %s, got %s", id, dag.Skipped, statuses[id])
+		}
+	}
+}
+
+func TestDAGGetNode(t *testing.T) {
+	llmNode := node.NewLLMNode("llm", &MockLLMClient{})
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("llm", llmNode)
+	workflow.AddNode("text", node.NewTextNode("text", func(inputs []string) (string, error) {
+		return "", nil
+	}))
+
+	if n, ok := workflow.GetNode("llm"); !ok || n != llmNode {
+		t.Fatalf("expected the added node, got %v, %v", n, ok)
+	}
+	if _, ok := workflow.GetNode("missing"); ok {
+		t.Fatal("expected missing node not to be found")
+	}
+
+	got, ok := dag.GetNodeAs[*node.LLMNode](workflow, "llm")
+	if !ok || got != llmNode {
+		t.Fatalf("expected *node.LLMNode, got %v, %v", got, ok)
+	}
+	if _, ok := dag.GetNodeAs[*node.LLMNode](workflow, "text"); ok {
+		t.Fatal("expected type mismatch to return false")
+	}
+	if _, ok := dag.GetNodeAs[*node.LLMNode](workflow, "missing"); ok {
+		t.Fatal("expected missing node to return false")
+	}
+}
+
+func TestDAGRemoveNode(t *testing.T) {
+	suffix := func(id string) node.Node {
+		return node.NewTextNode(id, func(inputs []string) (string, error) {
+			return strings.Join(inputs, "+") + ">" + id, nil
+		})
+	}
+
+	workflow := dag.NewDAG(2)
+	for _, id := range []string{"a", "b", "c"} {
+		workflow.AddNode(dag.NodeID(id), suffix(id))
+	}
+	mustAddEdges := func(edges ...[2]dag.NodeID) {
+		t.Helper()
+		for _, e := range edges {
+			if err := workflow.AddEdge(e[0], e[1]); err != nil {
+				t.Fatalf("failed to add edge %s -> %s: %v", e[0], e[1], err)
+			}
+		}
+	}
+	execute := func() map[dag.NodeID][]string {
+		t.Helper()
+		drainChannels(workflow)
+		_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"a": {"in"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return finalOutputs
+	}
+	mustAddEdges([2]dag.NodeID{"a", "b"}, [2]dag.NodeID{"b", "c"})
+
+	cycles := []struct {
+		name          string
+		remove        dag.NodeID
+		add           []dag.NodeID
+		edges         [][2]dag.NodeID
+		expectedRoots []dag.NodeID
+		expectedFinal map[dag.NodeID][]string
+	}{
+		{
+			name:          "Remove the middle node",
+			remove:        "b",
+			expectedRoots: []dag.NodeID{"a", "c"},
+			expectedFinal: map[dag.NodeID][]string{"a": {"in>a"}, "c": {">c"}},
+		},
+		{
+			name:          "Re-add the removed node",
+			add:           []dag.NodeID{"b"},
+			edges:         [][2]dag.NodeID{{"a", "b"}, {"b", "c"}},
+			expectedRoots: []dag.NodeID{"a"},
+			expectedFinal: map[dag.NodeID][]string{"c": {"in>a>b>c"}},
+		},
+		{
+			name:          "Remove the root and add a new one",
+			remove:        "a",
+			add:           []dag.NodeID{"a"},
+			edges:         [][2]dag.NodeID{{"a", "c"}},
+			expectedRoots: []dag.NodeID{"a", "b"},
+			expectedFinal: map[dag.NodeID][]string{"c": {">b+in>a>c"}},
+		},
+	}
+
+	for _, cycle := range cycles {
+		if cycle.remove != "" {
+			if err := workflow.RemoveNode(cycle.remove); err != nil {
+				t.Fatalf("%s: failed to remove node: %v", cycle.name, err)
+			}
+			if _, ok := workflow.GetNode(cycle.remove); ok {
+				t.Fatalf("%s: expected %s to be removed", cycle.name, cycle.remove)
+			}
+		}
+		for _, id := range cycle.add {
+			workflow.AddNode(id, suffix(string(id)))
+		}
+		mustAddEdges(cycle.edges...)
+
+		if roots := workflow.GetRootNodes(); !slices.Equal(roots, cycle.expectedRoots) {
+			t.Fatalf("%s: expected roots %v, got %v", cycle.name, cycle.expectedRoots, roots)
+		}
+		if finalOutputs := execute(); !maps.EqualFunc(finalOutputs, cycle.expectedFinal, slices.Equal) {
+			t.Fatalf("%s: expected %v, got %v", cycle.name, cycle.expectedFinal, finalOutputs)
+		}
+	}
+
+	if err := workflow.AddEdge("a", "c"); err == nil {
+		t.Fatal("expected error when adding a duplicate edge")
+	}
+	if err := workflow.RemoveNode("missing"); err == nil {
+		t.Fatal("expected error when removing a missing node")
+	}
+}
+
+func TestDAGPriority(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+
+	tests := []struct {
+		name       string
+		nodes      []dag.NodeID
+		edges      [][]dag.NodeID
+		priorities map[dag.NodeID]int
+		expected   []dag.NodeID
+	}{
+		{
+			name:       "Roots start in priority order",
+			nodes:      []dag.NodeID{"low", "mid", "high"},
+			priorities: map[dag.NodeID]int{"low": -1, "high": 10},
+			expected:   []dag.NodeID{"high", "mid", "low"},
+		},
+		{
+			name:       "Ready successor overtakes waiting roots",
+			nodes:      []dag.NodeID{"first", "urgent", "b", "c"},
+			edges:      [][]dag.NodeID{{"first", "urgent"}},
+			priorities: map[dag.NodeID]int{"first": 5, "urgent": 10, "b": 1},
+			expected:   []dag.NodeID{"first", "urgent", "b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []dag.Option
+			for id, p := range tt.priorities {
+				opts = append(opts, dag.WithPriority(id, p))
+			}
+			workflow := dag.NewDAG(1, opts...)
+			for _, id := range tt.nodes {
+				workflow.AddNode(id, node.NewTextNode(string(id), join))
+			}
+			for _, edge := range tt.edges {
+				if err := workflow.AddEdge(edge[0], edge[1]); err != nil {
+					t.Fatalf("failed to add edge: %v", err)
+				}
+			}
+
+			drainChannels(workflow)
+			if _, _, err := workflow.Execute(context.Background(), nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if order := workflow.ExecutionOrder(); !slices.Equal(order, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, order)
+			}
+		})
+	}
+}
+
+func TestDAGExecutionOrder(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+
+	tests := []struct {
+		name  string
+		nodes []dag.NodeID
+		edges [][]dag.NodeID
+		check func(t *testing.T, order []dag.NodeID)
+	}{
+		{
+			name:  "Linear chain",
+			nodes: []dag.NodeID{"a", "b", "c"},
+			edges: [][]dag.NodeID{{"a", "b"}, {"b", "c"}},
+			check: func(t *testing.T, order []dag.NodeID) {
+				if !slices.Equal(order, []dag.NodeID{"a", "b", "c"}) {
+					t.Fatalf("expected [a b c], got %v", order)
+				}
+			},
+		},
+		{
+			name:  "Diamond",
+			nodes: []dag.NodeID{"root1", "root2", "left", "right", "join"},
+			edges: [][]dag.NodeID{{"root1", "left"}, {"root2", "right"}, {"left", "join"}, {"right", "join"}},
+			check: func(t *testing.T, order []dag.NodeID) {
+				if len(order) != 5 {
+					t.Fatalf("expected 5 started nodes, got %v", order)
+				}
+				joinIndex := slices.Index(order, "join")
+				for _, id := range []dag.NodeID{"root1", "root2", "left", "right"} {
+					if i := slices.Index(order, id); i < 0 || i > joinIndex {
+						t.Fatalf("expected %s to start before join, got %v", id, order)
+					}
+				}
+				if slices.Index(order, "root1") > slices.Index(order, "left") || slices.Index(order, "root2") > slices.Index(order, "right") {
+					t.Fatalf("expected roots to start before their successors, got %v", order)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := dag.NewDAG(2)
+			for _, id := range tt.nodes {
+				workflow.AddNode(id, node.NewTextNode(string(id), join))
+			}
+			for _, edge := range tt.edges {
+				if err := workflow.AddEdge(edge[0], edge[1]); err != nil {
+					t.Fatalf("failed to add edge: %v", err)
+				}
+			}
+
+			drainChannels(workflow)
+			if _, _, err := workflow.Execute(context.Background(), nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(t, workflow.ExecutionOrder())
+		})
+	}
+}
+
+func TestDAGFilterSkipsDownstream(t *testing.T) {
+	tests := []struct {
+		name             string
+		keep             func(string) bool
+		expectedStatuses map[dag.NodeID]dag.NodeStatus
+		expectedFinal    string
+	}{
+		{
+			name: "Keep all",
+			keep: func(string) bool { return true },
+			expectedStatuses: map[dag.NodeID]dag.NodeStatus{
+				"filter": dag.Completed, "llm": dag.Completed, "collect": dag.Completed,
+			},
+			expectedFinal: `["mock response: a\nb"]`,
+		},
+		{
+			name: "Keep some",
+			keep: func(s string) bool { return s == "a" },
+			expectedStatuses: map[dag.NodeID]dag.NodeStatus{
+				"filter": dag.Completed, "llm": dag.Completed, "collect": dag.Completed,
+			},
+			expectedFinal: `["mock response: a"]`,
+		},
+		{
+			name: "Keep none",
+			keep: func(string) bool { return false },
+			expectedStatuses: map[dag.NodeID]dag.NodeStatus{
+				"filter": dag.Completed, "llm": dag.Skipped, "collect": dag.Skipped,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := node.NewLLMNode("llm", &MockLLMClient{})
+			llm.SetJoiner("\n")
+
+			workflow := dag.NewDAG(1)
+			workflow.AddNode("filter", node.NewFilterNode("filter", tt.keep))
+			workflow.AddNode("llm", llm)
+			workflow.AddNode("collect", node.NewCollectNode("collect"))
+			if err := workflow.AddEdge("filter", "llm"); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+			if err := workflow.AddEdge("llm", "collect"); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+
+			statuses := recordStatuses(workflow)
+			outputs, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"filter": {"a", "b"}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for id, want := range tt.expectedStatuses {
+				if got := statuses()[id]; got != want {
+					t.Fatalf("expected %s for node %s, got %s", want, id, got)
+				}
+			}
+			if tt.expectedFinal != "" {
+				if got := outputs["collect"]; !slices.Equal(got, []string{tt.expectedFinal}) {
+					t.Fatalf("expected %s, got %v", tt.expectedFinal, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDAGSetEnabled(t *testing.T) {
+	tests := []struct {
+		name             string
+		opts             []dag.Option
+		expectedStatuses map[dag.NodeID]dag.NodeStatus
+		expectedFinal    map[dag.NodeID][]string
+	}{
+		{
+			name: "Pass inputs through",
+			expectedStatuses: map[dag.NodeID]dag.NodeStatus{
+				"source": dag.Completed, "upper": dag.Skipped, "sink": dag.Completed,
+			},
+			expectedFinal: map[dag.NodeID][]string{"sink": {"hello!"}},
+		},
+		{
+			name: "Drop outputs",
+			opts: []dag.Option{dag.WithDropDisabledOutputs()},
+			expectedStatuses: map[dag.NodeID]dag.NodeStatus{
+				"source": dag.Completed, "upper": dag.Skipped, "sink": dag.Skipped,
+			},
+			expectedFinal: map[dag.NodeID][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executed := false
+			workflow := dag.NewDAG(1, tt.opts...)
+			workflow.AddNode("source", node.NewTextNode("source", func(inputs []string) (string, error) {
+				return strings.Join(inputs, " "), nil
+			}))
+			workflow.AddNode("upper", node.NewTextNode("upper", func(inputs []string) (string, error) {
+				executed = true
+				return strings.ToUpper(strings.Join(inputs, " ")), nil
+			}))
+			workflow.AddNode("sink", node.NewTextNode("sink", func(inputs []string) (string, error) {
+				return strings.Join(inputs, " ") + "!", nil
+			}))
+			if err := workflow.AddEdge("source", "upper"); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+			if err := workflow.AddEdge("upper", "sink"); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+			workflow.SetEnabled("upper", false)
+
+			statuses := recordStatuses(workflow)
+			_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"source": {"hello"}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if executed {
+				t.Fatal("expected the disabled node not to be executed")
+			}
+			for id, want := range tt.expectedStatuses {
+				if got := statuses()[id]; got != want {
+					t.Fatalf("expected %s for node %s, got %s", want, id, got)
+				}
+			}
+			if !maps.EqualFunc(finalOutputs, tt.expectedFinal, slices.Equal) {
+				t.Fatalf("expected final outputs %v, got %v", tt.expectedFinal, finalOutputs)
+			}
+
+			// 有効に戻すと再び実行される
+			workflow.SetEnabled("upper", true)
+			drainChannels(workflow)
+			_, finalOutputs, err = workflow.Execute(context.Background(), map[dag.NodeID][]string{"source": {"hello"}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := []string{"HELLO!"}; !slices.Equal(finalOutputs["sink"], want) {
+				t.Fatalf("expected %v after enabling, got %v", want, finalOutputs["sink"])
+			}
+		})
+	}
+}
+
+func TestDAGResetsNodesBetweenRuns(t *testing.T) {
+	upper := node.NewTextNode("upper", func(inputs []string) (string, error) {
+		return strings.ToUpper(strings.Join(inputs, " ")), nil
+	})
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("filter", node.NewFilterNode("filter", func(s string) bool { return s != "drop" }))
+	workflow.AddNode("upper", upper)
+	if err := workflow.AddEdge("filter", "upper"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	drainChannels(workflow)
+	if _, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"filter": {"keep"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := upper.GetOutputs(); !slices.Equal(got, []string{"KEEP"}) {
+		t.Fatalf("expected [KEEP] after the first run, got %v", got)
+	}
+
+	// 2回目の実行ではupperがスキップされるため、前回の出力が残っていてはならない
+	drainChannels(workflow)
+	outputs, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"filter": {"drop"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := outputs["upper"]; ok {
+		t.Fatalf("expected upper to have no outputs, got %v", outputs)
+	}
+	if got := upper.GetOutputs(); got != nil {
+		t.Fatalf("expected stale outputs to be cleared, got %v", got)
+	}
+}
+
+func TestDAGEdgeWeight(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+	workflow := dag.NewDAG(1)
+	for _, id := range []dag.NodeID{"a", "b", "c"} {
+		workflow.AddNode(id, node.NewTextNode(string(id), join))
+	}
+	if err := workflow.AddWeightedEdge("a", "c", 1024); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if err := workflow.AddEdge("b", "c"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if err := workflow.AddWeightedEdge("a", "missing", 1); err == nil {
+		t.Fatal("expected error for an unknown node")
+	}
+
+	tests := []struct {
+		name           string
+		from, to       dag.NodeID
+		expectedWeight float64
+		expectedOK     bool
+	}{
+		{"Weighted edge", "a", "c", 1024, true},
+		{"Unweighted edge", "b", "c", 0, true},
+		{"Missing edge", "a", "b", 0, false},
+		{"Reversed edge", "c", "a", 0, false},
+		{"Unknown node", "a", "missing", 0, false},
+	}
+
+	clone := workflow.Clone()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, d := range []*dag.DAG{workflow, clone} {
+				weight, ok := d.EdgeWeight(tt.from, tt.to)
+				if weight != tt.expectedWeight || ok != tt.expectedOK {
+					t.Fatalf("expected (%v, %v), got (%v, %v)", tt.expectedWeight, tt.expectedOK, weight, ok)
+				}
+			}
+		})
+	}
+
+	// 重みは実行結果に影響しない
+	drainChannels(workflow)
+	_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"a": {"x"}, "b": {"y"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := finalOutputs["c"]; !slices.Equal(got, []string{"x y"}) {
+		t.Fatalf("expected [x y], got %v", got)
+	}
+}
+
+func TestDAGAbort(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+	unsafe := func(inputs []string) bool { return slices.Contains(inputs, "unsafe") }
+
+	tests := []struct {
+		name             string
+		input            string
+		expectedStatus   dag.RunStatus
+		expectedStatuses map[dag.NodeID]dag.NodeStatus
+	}{
+		{
+			name:           "Continue",
+			input:          "safe",
+			expectedStatus: dag.RunCompleted,
+			expectedStatuses: map[dag.NodeID]dag.NodeStatus{
+				"input": dag.Completed, "guard": dag.Completed, "answer": dag.Completed,
+			},
+		},
+		{
+			name:           "Abort",
+			input:          "unsafe",
+			expectedStatus: dag.RunAborted,
+			expectedStatuses: map[dag.NodeID]dag.NodeStatus{
+				"input": dag.Completed, "guard": dag.Completed, "answer": dag.Pending,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := dag.NewDAG(1)
+			workflow.AddNode("input", node.NewTextNode("input", join))
+			workflow.AddNode("guard", node.NewAbortNode("guard", unsafe))
+			workflow.AddNode("answer", node.NewTextNode("answer", join))
+			if err := workflow.AddEdge("input", "guard"); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+			if err := workflow.AddEdge("guard", "answer"); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+
+			statuses := recordStatuses(workflow)
+			result, err := workflow.Run(context.Background(), map[dag.NodeID][]string{"input": {tt.input}})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result.Status != tt.expectedStatus {
+				t.Fatalf("expected %s, got %s", tt.expectedStatus, result.Status)
+			}
+			// 打ち切った場合も、それまでに完了したノードの出力は返される
+			if got := result.Outputs["guard"]; !slices.Equal(got, []string{tt.input}) {
+				t.Fatalf("expected guard outputs [%s], got %v", tt.input, got)
+			}
+			// 開始されなかったノードの状態は通知されないため、Pendingのままである
+			recorded := statuses()
+			for id, want := range tt.expectedStatuses {
+				got, ok := recorded[id]
+				if !ok {
+					got = dag.Pending
+				}
+				if got != want {
+					t.Fatalf("expected %s for node %s, got %s", want, id, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDAGEdges(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+	workflow := dag.NewDAG(1)
+	for _, id := range []dag.NodeID{"a", "b", "c", "d"} {
+		workflow.AddNode(id, node.NewTextNode(string(id), join))
+	}
+	if len(workflow.Edges()) != 0 {
+		t.Fatalf("expected no edges, got %v", workflow.Edges())
+	}
+
+	if err := workflow.AddEdge("c", "d"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if err := workflow.AddOptionalEdge("a", "c"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if err := workflow.AddEdgeWithTransform("b", "d", func(s []string) ([]string, error) { return s, nil }); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if err := workflow.AddEdge("a", "b"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	expected := [][2]dag.NodeID{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}}
+	if got := workflow.Edges(); !slices.Equal(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+
+	if err := workflow.RemoveNode("b"); err != nil {
+		t.Fatalf("failed to remove node: %v", err)
+	}
+	expected = [][2]dag.NodeID{{"a", "c"}, {"c", "d"}}
+	if got := workflow.Edges(); !slices.Equal(got, expected) {
+		t.Fatalf("expected %v after removing b, got %v", expected, got)
+	}
+}
+
+func TestDAGAddFailureEdge(t *testing.T) {
+	tests := []struct {
+		name             string
+		fail             bool
+		expectedStatuses map[dag.NodeID]dag.NodeStatus
+		expectedFinal    map[dag.NodeID][]string
+	}{
+		{
+			name: "Failure routes to handler",
+			fail: true,
+			expectedStatuses: map[dag.NodeID]dag.NodeStatus{
+				"risky": dag.Error, "next": dag.Skipped, "compensate": dag.Completed,
+			},
+			expectedFinal: map[dag.NodeID][]string{"compensate": {"compensated: broken"}},
+		},
+		{
+			name: "Success skips handler",
+			expectedStatuses: map[dag.NodeID]dag.NodeStatus{
+				"risky": dag.Completed, "next": dag.Completed, "compensate": dag.Skipped,
+			},
+			expectedFinal: map[dag.NodeID][]string{"next": {"ok!"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := dag.NewDAG(1, dag.WithFailFast())
+			workflow.AddNode("risky", node.NewTextNode("risky", func(inputs []string) (string, error) {
+				if tt.fail {
+					return "", errors.New("broken")
+				}
+				return "ok", nil
+			}))
+			workflow.AddNode("next", node.NewTextNode("next", func(inputs []string) (string, error) {
+				return strings.Join(inputs, " ") + "!", nil
+			}))
+			workflow.AddNode("compensate", node.NewTextNode("compensate", func(inputs []string) (string, error) {
+				return "compensated: " + strings.Join(inputs, " "), nil
+			}))
+			if err := workflow.AddEdge("risky", "next"); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+			if err := workflow.AddFailureEdge("risky", "compensate"); err != nil {
+				t.Fatalf("failed to add failure edge: %v", err)
+			}
+
+			statuses := recordStatuses(workflow)
+			result, err := workflow.Run(context.Background(), map[dag.NodeID][]string{"risky": {"go"}})
+			if err != nil {
+				t.Fatalf("expected the failure to be handled, got %v", err)
+			}
+			if result.Status != dag.RunCompleted {
+				t.Fatalf("expected %s, got %s", dag.RunCompleted, result.Status)
+			}
+			for id, want := range tt.expectedStatuses {
+				if got := statuses()[id]; got != want {
+					t.Fatalf("expected %s for node %s, got %s", want, id, got)
+				}
+			}
+			if !maps.EqualFunc(result.FinalOutputs, tt.expectedFinal, slices.Equal) {
+				t.Fatalf("expected final outputs %v, got %v", tt.expectedFinal, result.FinalOutputs)
+			}
+		})
+	}
+}
+
+func TestDAGWithWorkflowTimeout(t *testing.T) {
+	// 各ノードは制限時間内に終わるが、合計の待機時間は制限時間を超える
+	first := &sleepNode{name: "first", duration: 30 * time.Millisecond}
+	second := &sleepNode{name: "second", duration: 30 * time.Millisecond}
+	third := &sleepNode{name: "third", duration: 30 * time.Millisecond}
+
+	workflow := dag.NewDAG(1, dag.WithWorkflowTimeout(50*time.Millisecond))
+	workflow.AddNode("first", first)
+	workflow.AddNode("second", second)
+	workflow.AddNode("third", third)
+	if err := workflow.AddEdge("first", "second"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if err := workflow.AddEdge("second", "third"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	statuses := recordStatuses(workflow)
+	start := time.Now()
+	result, err := workflow.Run(context.Background(), map[dag.NodeID][]string{"first": {"input"}})
+	elapsed := time.Since(start)
+	if !errors.Is(err, dag.ErrWorkflowTimeout) {
+		t.Fatalf("expected ErrWorkflowTimeout, got %v", err)
+	}
+	if elapsed >= 90*time.Millisecond {
+		t.Fatalf("expected the workflow to be cut off, took %s", elapsed)
+	}
+	if result.Status != dag.RunFailed {
+		t.Fatalf("expected %s, got %s", dag.RunFailed, result.Status)
+	}
+	if got := result.Outputs["first"]; !slices.Equal(got, []string{"input"}) {
+		t.Fatalf("expected partial output of first node, got %v", got)
+	}
+	if statuses()["second"] != dag.Error {
+		t.Fatalf("expected second node to be cancelled, got %s", statuses()["second"])
+	}
+	if third.completed.Load() {
+		t.Fatalf("expected third node not to run")
+	}
+}
+
+func TestDAGStatusChanBuffered(t *testing.T) {
+	slow := &sleepNode{name: "slow", duration: 50 * time.Millisecond}
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("fast", node.NewIdentityNode("fast"))
+	workflow.AddNode("slow", slow)
+	if err := workflow.AddEdge("fast", "slow"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	// 読み手がいなくても実行はブロックしない
+	if _, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"fast": {"input"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 実行の開始後に購読しても、それまでの状態変更を含めて受け取れる
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"fast": {"input"}})
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	statusChan, ioChan := workflow.GetStatusChan(), workflow.GetIOChan()
+	if again := workflow.GetStatusChan(); again != statusChan {
+		t.Fatal("expected the same status channel for the current run")
+	}
+
+	var received []dag.NodeState
+	for state := range statusChan {
+		received = append(received, state)
+	}
+	var ios []dag.NodeID
+	for io := range ioChan {
+		ios = append(ios, io.ID)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []dag.NodeState{
+		{ID: "fast", Status: dag.Ready},
+		{ID: "fast", Status: dag.Running},
+		{ID: "fast", Status: dag.Completed},
+		{ID: "slow", Status: dag.Ready},
+		{ID: "slow", Status: dag.Running},
+		{ID: "slow", Status: dag.Completed},
+	}
+	if !slices.Equal(received, expected) {
+		t.Fatalf("expected statuses %v, got %v", expected, received)
+	}
+	if !slices.Equal(ios, []dag.NodeID{"fast", "slow"}) {
+		t.Fatalf("expected io for both nodes, got %v", ios)
+	}
+}
@@ -0,0 +1,24 @@
+package dag
+
+// WithDedupInputsはノードidに渡す入力から重複を取り除くよう設定します。
+// 同じ値が複数の経路から届く場合に、最初に現れたものだけを残して順序を保ったまま渡します。
+// 外部から与えられた入力も対象です。WithTaggedInputsと併用した場合は、前置した後の文字列で比較します。
+func WithDedupInputs(id NodeID) Option {
+	return func(dag *DAG) {
+		dag.dedupInputs[id] = true
+	}
+}
+
+// dedupは最初に現れた順序を保ったまま重複を取り除いた新しいスライスを返します。
+func dedup(inputs []string) []string {
+	seen := make(map[string]bool, len(inputs))
+	var unique []string
+	for _, input := range inputs {
+		if seen[input] {
+			continue
+		}
+		seen[input] = true
+		unique = append(unique, input)
+	}
+	return unique
+}
@@ -0,0 +1,47 @@
+package dag_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGWithDedupInputs(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []dag.Option
+		expected []string
+	}{
+		{"Without dedup", nil, []string{"shared", "shared", "left", "shared", "right"}},
+		{"With dedup", []dag.Option{dag.WithDedupInputs("merge")}, []string{"shared", "left", "right"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			emit := func(outputs ...string) func([]string) ([]string, error) {
+				return func([]string) ([]string, error) { return outputs, nil }
+			}
+			workflow := dag.NewDAG(2, tt.opts...)
+			workflow.AddNode("left", node.NewMergeNode("left", emit("shared", "left")))
+			workflow.AddNode("right", node.NewMergeNode("right", emit("shared", "right")))
+			workflow.AddNode("merge", node.NewIdentityNode("merge"))
+			for _, from := range []dag.NodeID{"left", "right"} {
+				if err := workflow.AddEdge(from, "merge"); err != nil {
+					t.Fatalf("failed to add edge: %v", err)
+				}
+			}
+
+			drainChannels(workflow)
+			_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"merge": {"shared"}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(finalOutputs["merge"], tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, finalOutputs["merge"])
+			}
+		})
+	}
+}
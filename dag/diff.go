@@ -0,0 +1,80 @@
+package dag
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DiffResultは2つのDAGのグラフ構造の差分です。
+// 全てのスライスはNodeIDの昇順（エッジは依存元、依存先の順）に並びます。
+type DiffResult struct {
+	AddedNodes   []NodeID    // bにだけ存在するノード
+	RemovedNodes []NodeID    // aにだけ存在するノード
+	AddedEdges   [][2]NodeID // bにだけ存在するエッジ
+	RemovedEdges [][2]NodeID // aにだけ存在するエッジ
+}
+
+// Diffはaからbへのノードとエッジの追加と削除を返します。
+// ノードとエッジの有無だけを比較し、ノードの実装やエッジの設定（変換関数、重みなど）の違いは含みません。
+// ワークフローの定義の変更をCIで確認するといった用途に使用します。
+func Diff(a, b *DAG) DiffResult {
+	var result DiffResult
+	for _, id := range a.sortedNodeIDs() {
+		if _, ok := b.nodes[id]; !ok {
+			result.RemovedNodes = append(result.RemovedNodes, id)
+		}
+	}
+	for _, id := range b.sortedNodeIDs() {
+		if _, ok := a.nodes[id]; !ok {
+			result.AddedNodes = append(result.AddedNodes, id)
+		}
+	}
+
+	aEdges, bEdges := a.Edges(), b.Edges()
+	for _, edge := range aEdges {
+		if !slices.Contains(bEdges, edge) {
+			result.RemovedEdges = append(result.RemovedEdges, edge)
+		}
+	}
+	for _, edge := range bEdges {
+		if !slices.Contains(aEdges, edge) {
+			result.AddedEdges = append(result.AddedEdges, edge)
+		}
+	}
+	return result
+}
+
+// Emptyは差分がないかどうかを返します。
+func (d DiffResult) Empty() bool {
+	return len(d.AddedNodes) == 0 && len(d.RemovedNodes) == 0 && len(d.AddedEdges) == 0 && len(d.RemovedEdges) == 0
+}
+
+// Stringは差分を1行に1つずつ、追加を"+"、削除を"-"で始まる形式で返します。
+// 削除したノード、追加したノード、削除したエッジ、追加したエッジの順に並ぶため、出力は毎回同じになります。
+func (d DiffResult) String() string {
+	var b strings.Builder
+	for _, id := range d.RemovedNodes {
+		fmt.Fprintf(&b, "- node %s\n", id)
+	}
+	for _, id := range d.AddedNodes {
+		fmt.Fprintf(&b, "+ node %s\n", id)
+	}
+	for _, edge := range d.RemovedEdges {
+		fmt.Fprintf(&b, "- edge %s -> %s\n", edge[0], edge[1])
+	}
+	for _, edge := range d.AddedEdges {
+		fmt.Fprintf(&b, "+ edge %s -> %s\n", edge[0], edge[1])
+	}
+	return b.String()
+}
+
+// sortedNodeIDsは全てのノードのIDを昇順に返すメソッド
+func (dag *DAG) sortedNodeIDs() []NodeID {
+	ids := make([]NodeID, 0, len(dag.nodes))
+	for id := range dag.nodes {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
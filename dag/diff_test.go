@@ -0,0 +1,52 @@
+package dag_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDiff(t *testing.T) {
+	base := dag.NewDAG(1)
+	for _, id := range []dag.NodeID{"fetch", "parse", "summarize", "notify"} {
+		base.AddNode(id, node.NewIdentityNode(string(id)))
+	}
+	for _, edge := range [][2]dag.NodeID{{"fetch", "parse"}, {"parse", "summarize"}, {"summarize", "notify"}} {
+		if err := base.AddEdge(edge[0], edge[1]); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+	}
+
+	// 複製からnotifyを削除し、fetchからsummarizeへのエッジを追加する
+	modified := base.Clone()
+	if err := modified.RemoveNode("notify"); err != nil {
+		t.Fatalf("failed to remove node: %v", err)
+	}
+	if err := modified.AddEdge("fetch", "summarize"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	diff := dag.Diff(base, modified)
+	if diff.Empty() {
+		t.Fatal("expected a non-empty diff")
+	}
+	if !slices.Equal(diff.RemovedNodes, []dag.NodeID{"notify"}) || len(diff.AddedNodes) != 0 {
+		t.Fatalf("unexpected node diff: added %v, removed %v", diff.AddedNodes, diff.RemovedNodes)
+	}
+	if !slices.Equal(diff.AddedEdges, [][2]dag.NodeID{{"fetch", "summarize"}}) {
+		t.Fatalf("unexpected added edges: %v", diff.AddedEdges)
+	}
+	if !slices.Equal(diff.RemovedEdges, [][2]dag.NodeID{{"summarize", "notify"}}) {
+		t.Fatalf("unexpected removed edges: %v", diff.RemovedEdges)
+	}
+
+	expected := "- node notify\n- edge summarize -> notify\n+ edge fetch -> summarize\n"
+	if got := diff.String(); got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+	if !dag.Diff(base, base.Clone()).Empty() {
+		t.Fatal("expected no diff between a DAG and its clone")
+	}
+}
@@ -0,0 +1,166 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/momiom/workflow/node"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/topo"
+)
+
+// MissingInputsErrorは入力を1つも受け取らないノードがあることを示すエラーです。
+type MissingInputsError struct {
+	Nodes []NodeID
+}
+
+func (e *MissingInputsError) Error() string {
+	ids := make([]string, len(e.Nodes))
+	for i, id := range e.Nodes {
+		ids[i] = string(id)
+	}
+	return fmt.Sprintf("nodes would receive no inputs: %s", strings.Join(ids, ", "))
+}
+
+// InvalidNodeErrorはノードが見込まれる入力の数を処理できないことを示すエラーです。
+type InvalidNodeError struct {
+	ID  NodeID
+	Err error
+}
+
+func (e *InvalidNodeError) Error() string {
+	return fmt.Sprintf("node %s: %v", e.ID, e.Err)
+}
+
+func (e *InvalidNodeError) Unwrap() error {
+	return e.Err
+}
+
+// node.InputSpecまたはnode.InputValidatorを実装するノードの入力の数を検証するメソッド
+// 入力の数は依存元ノードの数（ファンイン）と外部入力、SetConstantInputsで設定した固定の入力の数の合計とします。
+// rootsがfalseの場合、外部入力が実行時まで分からないルートノードは検証しません。
+// SetLazyInputsで遅延入力を設定したノードも、入力の数が実行時まで分からないため検証しません。
+func (dag *DAG) validateNodes(order []NodeID, inputs map[NodeID][]string, roots bool) error {
+	var errs []error
+	for _, id := range order {
+		fanIn := len(dag.predecessors[id])
+		if fanIn == 0 && !roots {
+			continue
+		}
+		if _, lazy := dag.lazyInputs[id]; lazy {
+			continue
+		}
+		c := dag.constantInputs[id]
+		count := fanIn + len(inputs[id]) + len(c.before) + len(c.after)
+		if err := validateInputCount(dag.nodeMap[id], count); err != nil {
+			errs = append(errs, &InvalidNodeError{ID: id, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateInputCountはノードがcount個の入力を受け取れるかを検証します。
+// node.InputSpecの範囲を先に検証し、範囲内であればnode.InputValidatorで検証します。
+func validateInputCount(n node.Node, count int) error {
+	if spec, ok := n.(node.InputSpec); ok {
+		min, max := spec.InputSpec()
+		if count < min || (max >= 0 && count > max) {
+			return fmt.Errorf("input must be %s, got %d", formatArity(min, max), count)
+		}
+	}
+	if v, ok := n.(node.InputValidator); ok {
+		return v.ValidateInputs(count)
+	}
+	return nil
+}
+
+// formatArityは入力の数の範囲をエラーメッセージ用の文字列にします。
+func formatArity(min, max int) string {
+	switch {
+	case max < 0:
+		return fmt.Sprintf("at least %d", min)
+	case min == max:
+		return fmt.Sprintf("exactly %d", min)
+	default:
+		return fmt.Sprintf("between %d and %d", min, max)
+	}
+}
+
+// グラフの構造とノードの入力の数を検証するメソッド
+// グラフが循環している場合や、node.InputValidatorを実装するノードがファンインから見込まれる
+// 入力の数を処理できない場合にエラーを返します。各ノードのエラーは*InvalidNodeErrorです。
+// ルートノードの入力は実行時に与えられるため、ルートノードはDryRunで検証してください。
+func (dag *DAG) Validate() error {
+	order, err := dag.plan()
+	if err != nil {
+		return err
+	}
+	return dag.validateNodes(order, nil, false)
+}
+
+// 実行順序を決定的に返すメソッド
+// トポロジカルソートの順序のうち、順序が決まらないノード同士はNodeIDの昇順に並べます。
+func (dag *DAG) plan() ([]NodeID, error) {
+	sorted, err := topo.SortStabilized(dag.graph, func(nodes []graph.Node) {
+		slices.SortFunc(nodes, func(a, b graph.Node) int {
+			idA, _ := dag.nodeID(a.ID())
+			idB, _ := dag.nodeID(b.ID())
+			return strings.Compare(string(idA), string(idB))
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	order := make([]NodeID, 0, len(sorted))
+	for _, n := range sorted {
+		if id, ok := dag.nodeID(n.ID()); ok {
+			order = append(order, id)
+		}
+	}
+	return order, nil
+}
+
+// ノードを実行せずに、入力とグラフの構造を検証して実行予定の順序を返すメソッド
+// 依存元を持たず入力も与えられないノードがある場合は、実行順序とともに*MissingInputsErrorを返します。
+// SetLazyInputsで遅延入力を設定したノードは、実行時に入力が与えられるものとします。
+// node.InputSpecやnode.InputValidatorを実装するノードが入力の数を処理できない場合は*InvalidNodeErrorを返します。
+// 存在しないノードへの入力が含まれる場合や、グラフが循環している場合もエラーを返します。
+func (dag *DAG) DryRun(inputs map[NodeID][]string) ([]NodeID, error) {
+	dag.log().Debug("Dry running DAG")
+
+	if err := Inputs(inputs).Validate(dag); err != nil {
+		return nil, err
+	}
+
+	order, err := dag.plan()
+	if err != nil {
+		return nil, err
+	}
+
+	inputs = dag.resolveInputs(inputs)
+	var missing []NodeID
+	for _, id := range order {
+		if _, lazy := dag.lazyInputs[id]; lazy {
+			continue
+		}
+		if len(inputs[id]) == 0 && len(dag.predecessors[id]) == 0 {
+			missing = append(missing, id)
+		}
+	}
+
+	var errs []error
+	if len(missing) > 0 {
+		errs = append(errs, &MissingInputsError{Nodes: missing})
+	}
+	if err := dag.validateNodes(order, inputs, true); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return order, errors.Join(errs...)
+	}
+	return order, nil
+}
@@ -0,0 +1,216 @@
+package dag_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGDryRun(t *testing.T) {
+	var executions atomic.Int32
+	join := func(inputs []string) (string, error) {
+		executions.Add(1)
+		return strings.Join(inputs, " "), nil
+	}
+
+	newWorkflow := func() *dag.DAG {
+		workflow := dag.NewDAG(2)
+		for _, id := range []dag.NodeID{"b", "a", "join", "c"} {
+			workflow.AddNode(id, node.NewTextNode(string(id), join))
+		}
+		for _, edge := range [][]dag.NodeID{{"a", "join"}, {"b", "join"}, {"join", "c"}} {
+			if err := workflow.AddEdge(edge[0], edge[1]); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+		}
+		return workflow
+	}
+
+	tests := []struct {
+		name          string
+		inputs        map[dag.NodeID][]string
+		expectMissing []dag.NodeID
+		expectError   bool
+	}{
+		{"All roots have inputs", map[dag.NodeID][]string{"a": {"x"}, "b": {"y"}}, nil, false},
+		{"Root without inputs", map[dag.NodeID][]string{"a": {"x"}}, []dag.NodeID{"b"}, true},
+		{"Root with empty inputs", map[dag.NodeID][]string{"a": {"x"}, "b": {}}, []dag.NodeID{"b"}, true},
+		{"Unknown node", map[dag.NodeID][]string{"a": {"x"}, "b": {"y"}, "missing": {"z"}}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := newWorkflow().DryRun(tt.inputs)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("expected error: %v, got: %v", tt.expectError, err)
+			}
+
+			var missingErr *dag.MissingInputsError
+			if tt.expectMissing != nil {
+				if !errors.As(err, &missingErr) {
+					t.Fatalf("expected MissingInputsError, got %v", err)
+				}
+				if !slices.Equal(missingErr.Nodes, tt.expectMissing) {
+					t.Fatalf("expected missing %v, got %v", tt.expectMissing, missingErr.Nodes)
+				}
+			}
+			if err == nil || missingErr != nil {
+				if expected := []dag.NodeID{"a", "b", "join", "c"}; !slices.Equal(order, expected) {
+					t.Fatalf("expected order %v, got %v", expected, order)
+				}
+			}
+		})
+	}
+
+	if n := executions.Load(); n != 0 {
+		t.Fatalf("expected no node executions during dry run, got %d", n)
+	}
+}
+
+type validateMockLLMClient struct{}
+
+func (c *validateMockLLMClient) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	return prompt, nil
+}
+
+func TestDAGValidate(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+
+	tests := []struct {
+		name        string
+		preds       []dag.NodeID
+		joiner      bool
+		expectError bool
+	}{
+		{"Single predecessor", []dag.NodeID{"a"}, false, false},
+		{"Two predecessors without joiner", []dag.NodeID{"a", "b"}, false, true},
+		{"Two predecessors with joiner", []dag.NodeID{"a", "b"}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := node.NewLLMNode("llm", &validateMockLLMClient{})
+			if tt.joiner {
+				llm.SetJoiner("\n")
+			}
+
+			workflow := dag.NewDAG(2)
+			workflow.AddNode("llm", llm)
+			for _, id := range tt.preds {
+				workflow.AddNode(id, node.NewTextNode(string(id), join))
+				if err := workflow.AddEdge(id, "llm"); err != nil {
+					t.Fatalf("failed to add edge: %v", err)
+				}
+			}
+
+			err := workflow.Validate()
+			if (err != nil) != tt.expectError {
+				t.Fatalf("expected error: %v, got: %v", tt.expectError, err)
+			}
+			var invalid *dag.InvalidNodeError
+			if tt.expectError && (!errors.As(err, &invalid) || invalid.ID != "llm") {
+				t.Fatalf("expected InvalidNodeError for llm, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDAGDryRunValidatesRoots(t *testing.T) {
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("llm", node.NewLLMNode("llm", &validateMockLLMClient{}))
+
+	// ルートノードの入力の数は実行時に決まるため、Validateでは検証されない
+	if err := workflow.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := workflow.DryRun(map[dag.NodeID][]string{"llm": {"first", "second"}})
+	var invalid *dag.InvalidNodeError
+	if !errors.As(err, &invalid) || invalid.ID != "llm" {
+		t.Fatalf("expected InvalidNodeError for llm, got %v", err)
+	}
+}
+
+func TestDAGDryRunLazyInputs(t *testing.T) {
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("llm", node.NewLLMNode("llm", &validateMockLLMClient{}))
+	workflow.SetLazyInputs(map[dag.NodeID]dag.InputFunc{
+		"llm": func(ctx context.Context) ([]string, error) {
+			return []string{"prompt"}, nil
+		},
+	})
+
+	// 遅延入力だけを受け取るルートノードは、入力が与えられるものとして扱う
+	order, err := workflow.DryRun(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(order, []dag.NodeID{"llm"}) {
+		t.Fatalf("expected order [llm], got %v", order)
+	}
+
+	drainChannels(workflow)
+	if _, err := workflow.Run(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// arityNodeは入力の数の範囲だけを宣言するノードです。
+type arityNode struct {
+	*node.TextNode
+	min, max int
+}
+
+func (n *arityNode) InputSpec() (int, int) { return n.min, n.max }
+
+func TestDAGValidateInputSpec(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+
+	tests := []struct {
+		name          string
+		min, max      int
+		preds         int
+		expectedError string
+	}{
+		{"Within range", 2, 3, 2, ""},
+		{"Too few inputs", 2, 3, 1, "node pair: input must be between 2 and 3, got 1"},
+		{"Too many inputs", 1, 1, 2, "node pair: input must be exactly 1, got 2"},
+		{"Variadic", 1, -1, 4, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := dag.NewDAG(2)
+			workflow.AddNode("pair", &arityNode{TextNode: node.NewTextNode("pair", join), min: tt.min, max: tt.max})
+			for i := range tt.preds {
+				id := dag.NodeID(fmt.Sprintf("p%d", i))
+				workflow.AddNode(id, node.NewTextNode(string(id), join))
+				if err := workflow.AddEdge(id, "pair"); err != nil {
+					t.Fatalf("failed to add edge: %v", err)
+				}
+			}
+
+			err := workflow.Validate()
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.expectedError {
+				t.Fatalf("expected %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
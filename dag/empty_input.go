@@ -0,0 +1,21 @@
+package dag
+
+// WithSkipOnEmptyInputはノードidの入力が空の場合に、ノードを実行せずにスキップするよう設定します。
+// 入力がない場合と、全ての入力が空文字列の場合を空とみなします。
+// フィルタなどの上流のノードが空文字列を出力したときに、空の入力を受け付けないLLMNodeなどをエラーにしないために使用します。
+// スキップしたノードは出力を持たず、依存先ノードの実行は継続します。
+func WithSkipOnEmptyInput(id NodeID) Option {
+	return func(dag *DAG) {
+		dag.skipOnEmpty[id] = true
+	}
+}
+
+// emptyInputsは入力がないか、全ての入力が空文字列かどうかを返します。
+func emptyInputs(inputs []string) bool {
+	for _, input := range inputs {
+		if input != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package dag_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGWithSkipOnEmptyInput(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []dag.Option
+		expectError    bool
+		expectedStatus dag.NodeStatus
+	}{
+		{"Empty input fails LLM node by default", nil, true, dag.Error},
+		{"Empty input skips LLM node", []dag.Option{dag.WithSkipOnEmptyInput("llm")}, false, dag.Skipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := dag.NewDAG(1, tt.opts...)
+			// 上流のフィルタは条件に合う入力がないため空文字列を出力する
+			workflow.AddNode("filter", node.NewTextNode("filter", func(inputs []string) (string, error) {
+				return "", nil
+			}))
+			workflow.AddNode("llm", node.NewLLMNode("llm", &MockLLMClient{}))
+			if err := workflow.AddEdge("filter", "llm"); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+
+			statuses := recordStatuses(workflow)
+			_, err := workflow.Run(context.Background(), map[dag.NodeID][]string{"filter": {"input"}})
+			if (err != nil) != tt.expectError {
+				t.Fatalf("expected error: %v, got: %v", tt.expectError, err)
+			}
+			if got := statuses()["llm"]; got != tt.expectedStatus {
+				t.Fatalf("expected %s for llm node, got %s", tt.expectedStatus, got)
+			}
+		})
+	}
+}
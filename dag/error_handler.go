@@ -0,0 +1,55 @@
+package dag
+
+import (
+	"context"
+	"errors"
+
+	"github.com/momiom/workflow/node"
+)
+
+// ErrorActionはノードが失敗したときにエラーハンドラーが選ぶ処理です。
+type ErrorAction string
+
+const (
+	ActionFail  ErrorAction = "Fail"  // ノードをエラーとする（エラーハンドラーがない場合の動作）
+	ActionSkip  ErrorAction = "Skip"  // ノードをスキップし、依存先ノードの実行を継続する
+	ActionRetry ErrorAction = "Retry" // ノードをもう一度実行する
+)
+
+// ErrorHandlerはノードのエラーを受け取り、そのノードに対する処理を返す関数です。
+type ErrorHandler func(id NodeID, err error) ErrorAction
+
+// WithOnErrorはノードが失敗したときに呼び出すエラーハンドラーを設定します。
+// ハンドラーはWithRetryによる再試行の後に、ノードが返したエラーとともに呼び出されます。
+// ActionRetryを返すとノードを再実行し、再び失敗した場合はもう一度ハンドラーが呼び出されるため、
+// 再実行の回数はハンドラー側で制限してください。実行が中断されている場合は再実行しません。
+func WithOnError(id NodeID, handler ErrorHandler) Option {
+	return func(dag *DAG) {
+		dag.errorHandlers[id] = handler
+	}
+}
+
+// executeWithHandlerはノードを実行し、失敗した場合はエラーハンドラーの選んだ処理を行うメソッド
+// ハンドラーがActionSkipを選んだ場合はnode.ErrSkipを返します。
+func (dag *DAG) executeWithHandler(ctx context.Context, id NodeID, n node.Node) error {
+	handler, ok := dag.errorHandlers[id]
+	for {
+		err := dag.executeWithRetry(ctx, id, n)
+		if err == nil || !ok || errors.Is(err, node.ErrSkip) || errors.Is(err, node.ErrAbort) {
+			return err
+		}
+
+		action := handler(id, err)
+		dag.nodeLogger(id).Debug("Error handler called", "error", err, "action", action)
+		switch action {
+		case ActionSkip:
+			return node.ErrSkip
+		case ActionRetry:
+			if ctx.Err() != nil {
+				return err
+			}
+		default:
+			return err
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package dag_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGWithOnError(t *testing.T) {
+	errBroken := errors.New("broken")
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+
+	tests := []struct {
+		name          string
+		action        dag.ErrorAction
+		expectedError bool
+		expectedRuns  int32
+		expectedFinal map[dag.NodeID][]string
+		expectedState dag.NodeStatus
+	}{
+		{
+			name:          "Skip",
+			action:        dag.ActionSkip,
+			expectedRuns:  1,
+			expectedFinal: map[dag.NodeID][]string{"d": {"hello c"}},
+			expectedState: dag.Skipped,
+		},
+		{
+			name:          "Retry",
+			action:        dag.ActionRetry,
+			expectedRuns:  2,
+			expectedFinal: map[dag.NodeID][]string{"d": {"hello b hello c"}},
+			expectedState: dag.Completed,
+		},
+		{
+			name:          "Fail",
+			action:        dag.ActionFail,
+			expectedError: true,
+			expectedRuns:  1,
+			expectedState: dag.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// bは最初の実行だけ失敗する
+			var attempts atomic.Int32
+			flaky := node.NewTextNode("b", func(inputs []string) (string, error) {
+				if attempts.Add(1) == 1 {
+					return "", errBroken
+				}
+				return strings.Join(inputs, " ") + " b", nil
+			})
+			var calls atomic.Int32
+			handler := func(id dag.NodeID, err error) dag.ErrorAction {
+				calls.Add(1)
+				if id != "b" || !errors.Is(err, errBroken) {
+					t.Errorf("unexpected handler call for %s: %v", id, err)
+				}
+				return tt.action
+			}
+
+			workflow := dag.NewDAG(2, dag.WithOnError("b", handler))
+			workflow.AddNode("a", node.NewTextNode("a", join))
+			workflow.AddNode("b", flaky)
+			workflow.AddNode("c", node.NewTextNode("c", func(inputs []string) (string, error) {
+				return strings.Join(inputs, " ") + " c", nil
+			}))
+			workflow.AddNode("d", node.NewTextNode("d", join))
+			for _, edge := range [][2]dag.NodeID{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}} {
+				if err := workflow.AddEdge(edge[0], edge[1]); err != nil {
+					t.Fatalf("failed to add edge: %v", err)
+				}
+			}
+
+			statuses := recordStatuses(workflow)
+			_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"a": {"hello"}})
+			if tt.expectedError {
+				if !errors.Is(err, errBroken) {
+					t.Fatalf("expected %v, got %v", errBroken, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(finalOutputs, tt.expectedFinal) {
+					t.Fatalf("expected final outputs %v, got %v", tt.expectedFinal, finalOutputs)
+				}
+			}
+			// ハンドラーは最初の失敗でだけ呼び出される
+			if got := calls.Load(); got != 1 {
+				t.Fatalf("expected handler to be called once, got %d", got)
+			}
+			if got := attempts.Load(); got != tt.expectedRuns {
+				t.Fatalf("expected b to run %d times, got %d", tt.expectedRuns, got)
+			}
+			if got := statuses()["b"]; got != tt.expectedState {
+				t.Fatalf("expected b to be %s, got %s", tt.expectedState, got)
+			}
+		})
+	}
+}
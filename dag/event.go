@@ -0,0 +1,62 @@
+package dag
+
+import "time"
+
+// Eventは実行中に発生するイベントです。StatusEventまたはIOEventのいずれかです。
+// GetEventsのチャネルには1つのノードについて Running、IOEvent、Completed の順に送られ、
+// 依存元ノードのイベントは依存先ノードのイベントより先に送られます。
+type Event interface {
+	// Nodeはイベントが発生したノードのIDを返します。
+	Node() NodeID
+	// Timeはイベントが発生した時刻を返します。
+	Time() time.Time
+
+	event()
+}
+
+// StatusEventはノードの状態が変わったことを示すイベントです。
+type StatusEvent struct {
+	ID        NodeID
+	Status    NodeStatus
+	Timestamp time.Time
+}
+
+func (e StatusEvent) Node() NodeID    { return e.ID }
+func (e StatusEvent) Time() time.Time { return e.Timestamp }
+func (StatusEvent) event()            {}
+
+// IOEventはノードが完了し、その入出力が確定したことを示すイベントです。
+type IOEvent struct {
+	ID        NodeID
+	Inputs    []string
+	Outputs   []string
+	Metadata  map[string]any // node.MetadataProviderを実装するノードが返したメタデータ
+	Timestamp time.Time
+}
+
+func (e IOEvent) Node() NodeID    { return e.ID }
+func (e IOEvent) Time() time.Time { return e.Timestamp }
+func (IOEvent) event()            {}
+
+// 次回（実行中であれば現在）の実行の状態変更と入出力を1つのチャネルで受け取るメソッド
+// GetStatusChanとGetIOChanを別々に購読する場合と異なり、状態変更と入出力の前後関係が保たれます。
+// イベントは取得した後に発生したものだけが送られるため、実行の開始前に取得してください。
+// チャネルを取得していない実行のイベントは送られません。チャネルは実行の終了時に閉じられます。
+func (dag *DAG) GetEvents() <-chan Event {
+	dag.chanMu.Lock()
+	defer dag.chanMu.Unlock()
+	if dag.eventChan == nil {
+		dag.eventChan = make(chan Event)
+	}
+	return dag.eventChan
+}
+
+// イベントを購読者に送るメソッド
+func (dag *DAG) emitEvent(e Event) {
+	dag.chanMu.Lock()
+	ch := dag.eventChan
+	dag.chanMu.Unlock()
+	if ch != nil {
+		ch <- e
+	}
+}
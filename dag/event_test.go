@@ -0,0 +1,187 @@
+package dag_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGGetEvents(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("a", node.NewTextNode("a", join))
+	workflow.AddNode("b", node.NewTextNode("b", join))
+	if err := workflow.AddEdge("a", "b"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	// GetEventsだけを購読した場合、状態と入出力のチャネルを読まなくても実行は止まらない
+	events := workflow.GetEvents()
+	done := make(chan []dag.Event)
+	go func() {
+		var received []dag.Event
+		for e := range events {
+			received = append(received, e)
+		}
+		done <- received
+	}()
+
+	if _, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"a": {"hello", "world"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	received := <-done
+
+	// ノードごとのイベントの種類と位置を記録
+	index := make(map[string]int)
+	for i, e := range received {
+		if i > 0 && e.Time().Before(received[i-1].Time()) {
+			t.Fatalf("expected non-decreasing timestamps, got %v", received)
+		}
+		key := string(e.Node()) + ":"
+		switch e := e.(type) {
+		case dag.StatusEvent:
+			key += string(e.Status)
+		case dag.IOEvent:
+			key += "IO"
+			if e.ID == "b" && (len(e.Outputs) != 1 || e.Outputs[0] != "hello world") {
+				t.Fatalf("expected b to output [hello world], got %v", e.Outputs)
+			}
+		}
+		index[key] = i
+	}
+
+	for _, id := range []string{"a", "b"} {
+		running, okRunning := index[id+":Running"]
+		io, okIO := index[id+":IO"]
+		completed, okCompleted := index[id+":Completed"]
+		if !okRunning || !okIO || !okCompleted {
+			t.Fatalf("expected Running, IO and Completed events for %s, got %v", id, received)
+		}
+		if !(running < io && io < completed) {
+			t.Fatalf("expected Running < IO < Completed for %s, got %d, %d, %d", id, running, io, completed)
+		}
+	}
+	if index["a:Completed"] > index["b:Running"] {
+		t.Fatalf("expected a to complete before b starts, got %v", received)
+	}
+}
+
+func TestDAGReadyEvents(t *testing.T) {
+	// 実行枠が1つのため、2つ目のルートノードは1つ目が終わるまで実行枠を待つ
+	const duration = 50 * time.Millisecond
+	workflow := dag.NewDAG(1, dag.WithPriority("first", 1))
+	workflow.AddNode("first", &sleepNode{name: "first", duration: duration})
+	workflow.AddNode("second", &sleepNode{name: "second", duration: duration})
+	workflow.AddNode("after", &sleepNode{name: "after"})
+	if err := workflow.AddEdge("first", "after"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	events := workflow.GetEvents()
+	done := make(chan map[string]time.Time)
+	go func() {
+		times := make(map[string]time.Time)
+		for e := range events {
+			if e, ok := e.(dag.StatusEvent); ok {
+				times[string(e.ID)+":"+string(e.Status)] = e.Timestamp
+			}
+		}
+		done <- times
+	}()
+
+	inputs := map[dag.NodeID][]string{"first": {"1"}, "second": {"2"}}
+	if _, _, err := workflow.Execute(context.Background(), inputs); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	times := <-done
+
+	for _, id := range []string{"first", "second", "after"} {
+		ready, okReady := times[id+":Ready"]
+		running, okRunning := times[id+":Running"]
+		if !okReady || !okRunning {
+			t.Fatalf("expected Ready and Running events for %s, got %v", id, times)
+		}
+		if running.Before(ready) {
+			t.Fatalf("expected %s to be ready before running, got ready %v, running %v", id, ready, running)
+		}
+	}
+
+	// 実行枠を待った時間だけReadyとRunningの間に差が生じる
+	if gap := times["second:Running"].Sub(times["second:Ready"]); gap < duration*8/10 {
+		t.Fatalf("expected second to wait at least %v for a slot, got %v", duration*8/10, gap)
+	}
+	// 依存先ノードは依存元ノードの完了後にReadyになる
+	if times["after:Ready"].Before(times["first:Completed"]) {
+		t.Fatalf("expected after to become ready once first completes, got %v", times)
+	}
+}
+
+func TestDAGDeterministicEventOrder(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, "+"), nil
+	}
+	newWorkflow := func() *dag.DAG {
+		workflow := dag.NewDAG(4, dag.WithDeterministic(), dag.WithPriority("d", 10))
+		for _, id := range []dag.NodeID{"root", "a", "b", "c", "d", "join"} {
+			workflow.AddNode(id, node.NewTextNode(string(id), join))
+		}
+		for _, edge := range [][2]dag.NodeID{{"root", "c"}, {"root", "a"}, {"root", "d"}, {"root", "b"}, {"a", "join"}, {"b", "join"}, {"c", "join"}, {"d", "join"}} {
+			if err := workflow.AddEdge(edge[0], edge[1]); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+		}
+		return workflow
+	}
+
+	record := func(workflow *dag.DAG) []string {
+		events := workflow.GetEvents()
+		done := make(chan []string)
+		go func() {
+			var received []string
+			for e := range events {
+				switch e := e.(type) {
+				case dag.StatusEvent:
+					received = append(received, string(e.ID)+":"+string(e.Status))
+				case dag.IOEvent:
+					received = append(received, string(e.ID)+":IO:"+strings.Join(e.Outputs, ","))
+				}
+			}
+			done <- received
+		}()
+		if _, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"root": {"x"}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return <-done
+	}
+
+	workflow := newWorkflow()
+	expected := record(workflow)
+	for run := range 100 {
+		if got := record(workflow); !slices.Equal(got, expected) {
+			t.Fatalf("run %d: expected %v, got %v", run, expected, got)
+		}
+	}
+
+	// ノードはDryRunの順序で1つずつ実行され、優先度は無視される
+	order, err := newWorkflow().DryRun(map[dag.NodeID][]string{"root": {"x"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var started []dag.NodeID
+	for _, e := range expected {
+		if id, ok := strings.CutSuffix(e, ":Running"); ok {
+			started = append(started, dag.NodeID(id))
+		}
+	}
+	if !slices.Equal(started, order) {
+		t.Fatalf("expected nodes to start in %v, got %v", order, started)
+	}
+}
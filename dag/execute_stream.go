@@ -0,0 +1,61 @@
+package dag
+
+import (
+	"context"
+
+	"gonum.org/v1/gonum/graph/topo"
+)
+
+// NodeResultはExecuteStreamで送られる、処理を終えたノードの結果です。
+// ノードが完了した場合はOutputsに出力が、失敗した場合はErrにエラーが入ります。
+// IDが空の結果は実行全体のエラーを表します。
+type NodeResult struct {
+	ID      NodeID
+	Outputs []string
+	Err     error
+}
+
+// DAGを実行し、ノードが完了または失敗するたびにその結果をチャネルに送るメソッド
+// 結果は依存元ノードのものが依存先ノードのものより先に送られ、スキップしたノードの結果は送られません。
+// 実行全体がエラーとなった場合は、最後にIDが空でErrにRunと同じエラーを入れた結果を送ります。
+// 制限時間の超過やキャンセル、ノードの開始の失敗などもこの結果で判別できます。
+// チャネルは実行の終了時に閉じられます。チャネルを読まないと実行が進まないため、閉じられるまで読み続けてください。
+// ノードがない場合やグラフが循環している場合など、実行を開始できない場合はエラーを返します。
+// 実行全体の終了状態が必要な場合はRunを使用してください。
+func (dag *DAG) ExecuteStream(ctx context.Context, inputs map[NodeID][]string) (<-chan NodeResult, error) {
+	dag.log().Debug("Streaming DAG execution")
+
+	if len(dag.nodes) == 0 {
+		return nil, ErrEmptyDAG
+	}
+	sorted, err := topo.Sort(dag.graph)
+	if err != nil {
+		return nil, err
+	}
+	if err := dag.checkSchedulable(sorted); err != nil {
+		return nil, err
+	}
+
+	results := make(chan NodeResult)
+	dag.chanMu.Lock()
+	dag.resultChan = results
+	dag.chanMu.Unlock()
+	go func() {
+		defer close(results)
+		// ノードの結果はチャネルで受け取るため、ここでは実行全体のエラーだけを送る
+		if _, err := dag.Run(ctx, inputs); err != nil {
+			results <- NodeResult{Err: err}
+		}
+	}()
+	return results, nil
+}
+
+// ノードの結果をExecuteStreamのチャネルに送るメソッド
+func (dag *DAG) emitResult(r NodeResult) {
+	dag.chanMu.Lock()
+	ch := dag.resultChan
+	dag.chanMu.Unlock()
+	if ch != nil {
+		ch <- r
+	}
+}
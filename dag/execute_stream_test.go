@@ -0,0 +1,101 @@
+package dag_test
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGExecuteStream(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+	workflow := dag.NewDAG(2)
+	for _, id := range []dag.NodeID{"a", "b", "c", "d"} {
+		workflow.AddNode(id, node.NewTextNode(string(id), join))
+	}
+	for _, edge := range [][2]dag.NodeID{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}} {
+		if err := workflow.AddEdge(edge[0], edge[1]); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+	}
+	inputs := map[dag.NodeID][]string{"a": {"hello"}}
+
+	drainChannels(workflow)
+	expected, _, err := workflow.Execute(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results, err := workflow.ExecuteStream(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	outputs := make(map[dag.NodeID][]string)
+	var order []dag.NodeID
+	for result := range results {
+		if result.Err != nil {
+			t.Fatalf("unexpected error for %s: %v", result.ID, result.Err)
+		}
+		outputs[result.ID] = result.Outputs
+		order = append(order, result.ID)
+	}
+	if !maps.EqualFunc(outputs, expected, slices.Equal) {
+		t.Fatalf("expected streamed outputs %v, got %v", expected, outputs)
+	}
+	// 依存元ノードの結果が依存先ノードの結果より先に届く
+	if order[0] != "a" || order[len(order)-1] != "d" {
+		t.Fatalf("expected a first and d last, got %v", order)
+	}
+}
+
+func TestDAGExecuteStreamError(t *testing.T) {
+	errBroken := errors.New("broken")
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("broken", node.NewTextNode("broken", func(inputs []string) (string, error) {
+		return "", errBroken
+	}))
+
+	results, err := workflow.ExecuteStream(context.Background(), map[dag.NodeID][]string{"broken": {"x"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []dag.NodeResult
+	for result := range results {
+		got = append(got, result)
+	}
+	if len(got) != 2 || got[0].ID != "broken" || !errors.Is(got[0].Err, errBroken) {
+		t.Fatalf("expected an error result for broken followed by the run error, got %v", got)
+	}
+	// 最後の結果は実行全体のエラーを表す
+	if last := got[1]; last.ID != "" || !errors.Is(last.Err, errBroken) {
+		t.Fatalf("expected the run error as the last result, got %v", last)
+	}
+
+	// 制限時間の超過など、実行全体のエラーは最後の結果として送られる
+	timeout := dag.NewDAG(1, dag.WithWorkflowTimeout(10*time.Millisecond))
+	timeout.AddNode("slow", &sleepNode{name: "slow", duration: time.Hour})
+	results, err = timeout.ExecuteStream(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var last dag.NodeResult
+	for result := range results {
+		last = result
+	}
+	if last.ID != "" || !errors.Is(last.Err, dag.ErrWorkflowTimeout) {
+		t.Fatalf("expected %v as the last result, got %v", dag.ErrWorkflowTimeout, last)
+	}
+
+	// 実行を開始できない場合はチャネルを返さない
+	if _, err := dag.NewDAG(1).ExecuteStream(context.Background(), nil); !errors.Is(err, dag.ErrEmptyDAG) {
+		t.Fatalf("expected %v, got %v", dag.ErrEmptyDAG, err)
+	}
+}
@@ -0,0 +1,60 @@
+package dag
+
+import "sync"
+
+// Executorはノードの実行を受け付け、別のゴルーチンで実行するインターフェースです。
+// DAGは同時実行数の実行枠を獲得したノードだけをGoに渡すため、同時に渡されるタスクの数は
+// 同時実行数の上限（グループの上限を含む）を超えません。
+// Goはワーカーに空きがない場合にブロックしても構いませんが、渡されたタスクは必ず実行する必要があります。
+type Executor interface {
+	Go(task func())
+}
+
+// WithExecutorはノードをexecutorで実行するよう設定します。
+// 独自のワーカープールでゴルーチンの数を管理したい場合に使用します。
+func WithExecutor(executor Executor) Option {
+	return func(dag *DAG) {
+		dag.executor = executor
+	}
+}
+
+// goExecutorはタスクごとにゴルーチンを起動するExecutorです。
+type goExecutor struct{}
+
+func (goExecutor) Go(task func()) {
+	go task()
+}
+
+// WorkerPoolは固定数のワーカーでタスクを実行するExecutorです。
+// ワーカーが全て使用中の場合、Goは空きができるまでブロックします。
+type WorkerPool struct {
+	tasks chan func()
+	wg    sync.WaitGroup
+}
+
+// NewWorkerPoolはn個のワーカーを持つWorkerPoolを作成します。1未満の値は1として扱います。
+// 使用後はCloseでワーカーを停止してください。
+func NewWorkerPool(n int) *WorkerPool {
+	p := &WorkerPool{tasks: make(chan func())}
+	for range max(n, 1) {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for task := range p.tasks {
+				task()
+			}
+		}()
+	}
+	return p
+}
+
+// Goは空いているワーカーでtaskを実行します。
+func (p *WorkerPool) Go(task func()) {
+	p.tasks <- task
+}
+
+// Closeは実行中のタスクの終了を待ってワーカーを停止します。Closeの後にGoを呼び出すことはできません。
+func (p *WorkerPool) Close() {
+	close(p.tasks)
+	p.wg.Wait()
+}
@@ -0,0 +1,86 @@
+package dag_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+// countingExecutorは渡されたタスクの数を数えるExecutorです。
+type countingExecutor struct {
+	pool  *dag.WorkerPool
+	tasks atomic.Int32
+}
+
+func (e *countingExecutor) Go(task func()) {
+	e.tasks.Add(1)
+	e.pool.Go(task)
+}
+
+func TestDAGBoundedGoroutines(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping large graph test in short mode")
+	}
+	const nodes = 10000
+
+	tests := []struct {
+		name          string
+		maxConcurrent int
+		workers       int // 0の場合は既定のExecutorを使用する
+		bound         int
+	}{
+		{"Default executor", 8, 0, 8},
+		{"Worker pool smaller than the concurrency limit", 64, 4, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executor *countingExecutor
+			var opts []dag.Option
+			if tt.workers > 0 {
+				executor = &countingExecutor{pool: dag.NewWorkerPool(tt.workers)}
+				defer executor.pool.Close()
+				opts = append(opts, dag.WithExecutor(executor))
+			}
+
+			var peak atomic.Int64
+			process := func(inputs []string) (string, error) {
+				if n := int64(runtime.NumGoroutine()); n > peak.Load() {
+					peak.Store(n)
+				}
+				return "ok", nil
+			}
+
+			// 半数のノードをルート、残りをその依存先とする
+			workflow := dag.NewDAG(tt.maxConcurrent, opts...)
+			for i := range nodes {
+				workflow.AddNode(dag.NodeID(fmt.Sprintf("n%d", i)), node.NewTextNode("n", process))
+			}
+			for i := 0; i < nodes/2; i++ {
+				if err := workflow.AddEdge(dag.NodeID(fmt.Sprintf("n%d", i)), dag.NodeID(fmt.Sprintf("n%d", i+nodes/2))); err != nil {
+					t.Fatalf("failed to add edge: %v", err)
+				}
+			}
+
+			baseline := runtime.NumGoroutine()
+			drainChannels(workflow)
+			if _, _, err := workflow.Execute(context.Background(), nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// チャネルの読み出しやコンテキストの監視などのゴルーチンの分だけ余裕を持たせる
+			const slack = 10
+			if growth := int(peak.Load()) - baseline; growth > tt.bound+slack {
+				t.Fatalf("expected goroutine growth of at most %d, got %d", tt.bound+slack, growth)
+			}
+			if executor != nil && executor.tasks.Load() != nodes {
+				t.Fatalf("expected %d tasks, got %d", nodes, executor.tasks.Load())
+			}
+		})
+	}
+}
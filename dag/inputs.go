@@ -0,0 +1,58 @@
+package dag
+
+import (
+	"fmt"
+	"slices"
+)
+
+// InputsはExecuteやRunに渡す外部入力のマップです。
+// map[NodeID][]stringと同じ型のため、そのままExecuteやRunに渡すことができます。
+//
+//	inputs := dag.Inputs{}.
+//		Set("prompt", "hello").
+//		Append("context", docs...)
+type Inputs map[NodeID][]string
+
+// Setはノードidの入力をvalsで置き換え、Inputsを返します。
+// Inputsがnilの場合は新しいマップを作成して返します。
+func (in Inputs) Set(id NodeID, vals ...string) Inputs {
+	if in == nil {
+		in = make(Inputs)
+	}
+	in[id] = slices.Clone(vals)
+	return in
+}
+
+// Appendはノードidの入力の末尾にvalsを追加し、Inputsを返します。
+// Inputsがnilの場合は新しいマップを作成して返します。
+func (in Inputs) Append(id NodeID, vals ...string) Inputs {
+	if in == nil {
+		in = make(Inputs)
+	}
+	in[id] = append(in[id], vals...)
+	return in
+}
+
+// Mergeはotherの全ての入力を、ノードごとにAppendと同様に末尾へ追加し、Inputsを返します。
+func (in Inputs) Merge(other map[NodeID][]string) Inputs {
+	for id, vals := range other {
+		in = in.Append(id, vals...)
+	}
+	return in
+}
+
+// Validateは入力が参照する全てのノードがdagに存在するかを検証します。
+// 存在しないノードがある場合は、そのIDを昇順に並べたエラーを返します。
+func (in Inputs) Validate(dag *DAG) error {
+	var unknown []NodeID
+	for id := range in {
+		if _, ok := dag.nodes[id]; !ok {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	slices.Sort(unknown)
+	return fmt.Errorf("inputs reference unknown nodes %v", unknown)
+}
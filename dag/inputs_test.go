@@ -0,0 +1,69 @@
+package dag_test
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestInputs(t *testing.T) {
+	tests := []struct {
+		name     string
+		build    func() dag.Inputs
+		expected dag.Inputs
+	}{
+		{
+			name:     "Append accumulates",
+			build:    func() dag.Inputs { return dag.Inputs{}.Append("a", "1").Append("a", "2", "3") },
+			expected: dag.Inputs{"a": {"1", "2", "3"}},
+		},
+		{
+			name:     "Set overwrites",
+			build:    func() dag.Inputs { return dag.Inputs{}.Append("a", "1").Set("a", "2").Set("b") },
+			expected: dag.Inputs{"a": {"2"}, "b": {}},
+		},
+		{
+			name:     "Nil inputs are allocated",
+			build:    func() dag.Inputs { return dag.Inputs(nil).Set("a", "1") },
+			expected: dag.Inputs{"a": {"1"}},
+		},
+		{
+			name: "Merge appends other sources",
+			build: func() dag.Inputs {
+				return dag.Inputs{}.Set("a", "1").Merge(map[dag.NodeID][]string{"a": {"2"}, "b": {"3"}})
+			},
+			expected: dag.Inputs{"a": {"1", "2"}, "b": {"3"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.build(); !maps.EqualFunc(got, tt.expected, slices.Equal) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestInputsValidate(t *testing.T) {
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("a", node.NewIdentityNode("a"))
+
+	inputs := dag.Inputs{}.Set("a", "hello")
+	if err := inputs.Validate(workflow); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	drainChannels(workflow)
+	if _, finalOutputs, err := workflow.Execute(context.Background(), inputs); err != nil || !slices.Equal(finalOutputs["a"], []string{"hello"}) {
+		t.Fatalf("expected [hello], got %v, %v", finalOutputs["a"], err)
+	}
+
+	err := inputs.Set("z").Set("y").Validate(workflow)
+	if err == nil || err.Error() != "inputs reference unknown nodes [y z]" {
+		t.Fatalf("expected unknown nodes error, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package dag
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// jsonEventはWriteJSONLinesで1行に書き込むイベントです。
+type jsonEvent struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Type      string         `json:"type"` // "status"または"io"
+	Node      NodeID         `json:"node"`
+	Status    NodeStatus     `json:"status,omitempty"`
+	Inputs    []string       `json:"inputs,omitempty"`
+	Outputs   []string       `json:"outputs,omitempty"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+}
+
+// WriteJSONLinesはeventsの各イベントを1行のJSONオブジェクトとしてwに書き込みます。
+// 状態変更は {"timestamp", "type":"status", "node", "status"}、入出力は
+// {"timestamp", "type":"io", "node", "inputs", "outputs", "metadata"} の形で書き込まれ、ログの収集基盤にそのまま渡せます。
+// GetEventsで取得したチャネルを渡し、実行と並行してゴルーチンで呼び出してください。
+// 書き込みに失敗しても実行が止まらないよう、チャネルが閉じられるまで読み続けてから最初のエラーを返します。
+func WriteJSONLines(w io.Writer, events <-chan Event) error {
+	enc := json.NewEncoder(w)
+	var writeErr error
+	for e := range events {
+		if writeErr != nil {
+			continue
+		}
+		line := jsonEvent{Timestamp: e.Time(), Node: e.Node()}
+		switch e := e.(type) {
+		case StatusEvent:
+			line.Type = "status"
+			line.Status = e.Status
+		case IOEvent:
+			line.Type = "io"
+			line.Inputs = e.Inputs
+			line.Outputs = e.Outputs
+			line.Metadata = e.Metadata
+		}
+		if err := enc.Encode(line); err != nil {
+			writeErr = fmt.Errorf("failed to write event: %w", err)
+		}
+	}
+	return writeErr
+}
@@ -0,0 +1,95 @@
+package dag_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestWriteJSONLines(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("a", node.NewTextNode("a", join))
+	workflow.AddNode("b", node.NewTextNode("b", join))
+	if err := workflow.AddEdge("a", "b"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	var buf bytes.Buffer
+	written := make(chan error, 1)
+	events := workflow.GetEvents()
+	go func() { written <- dag.WriteJSONLines(&buf, events) }()
+	if _, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"a": {"hello"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := <-written; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counts := make(map[string]int)
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line struct {
+			Timestamp time.Time      `json:"timestamp"`
+			Type      string         `json:"type"`
+			Node      dag.NodeID     `json:"node"`
+			Status    dag.NodeStatus `json:"status"`
+			Inputs    []string       `json:"inputs"`
+			Outputs   []string       `json:"outputs"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("failed to parse line %q: %v", scanner.Text(), err)
+		}
+		if line.Timestamp.IsZero() || line.Node == "" {
+			t.Fatalf("expected timestamp and node in %q", scanner.Text())
+		}
+		switch line.Type {
+		case "status":
+			if line.Status == "" {
+				t.Fatalf("expected status in %q", scanner.Text())
+			}
+		case "io":
+			if len(line.Outputs) == 0 {
+				t.Fatalf("expected outputs in %q", scanner.Text())
+			}
+		default:
+			t.Fatalf("unexpected type in %q", scanner.Text())
+		}
+		counts[line.Type]++
+	}
+	// 各ノードについてReady、Running、Completedの状態変更と1つの入出力
+	if counts["status"] != 6 || counts["io"] != 2 {
+		t.Fatalf("expected 6 status and 2 io lines, got %v", counts)
+	}
+}
+
+// failingWriterは常に書き込みに失敗するio.Writerです。
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestWriteJSONLinesError(t *testing.T) {
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("a", node.NewIdentityNode("a"))
+
+	written := make(chan error, 1)
+	events := workflow.GetEvents()
+	go func() { written <- dag.WriteJSONLines(failingWriter{}, events) }()
+	// 書き込みに失敗しても実行は最後まで進む
+	if _, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"a": {"hello"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := <-written; err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("expected the write error, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package dag
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// InputFuncはノードの実行直前に入力を計算する関数です。
+// 現在時刻やアクセストークンなど、実行を開始した時点ではなくノードを実行する時点の値を渡す場合に使用します。
+type InputFunc func(ctx context.Context) ([]string, error)
+
+// ノードの入力を実行直前に計算する関数を設定するメソッド
+// providersに含まれるノードは、実行を開始する直前にその関数を呼び出し、結果をExecuteに渡した入力の後ろに連結して受け取ります。
+// 関数がエラーを返した場合、そのノードは実行されずにエラーとなります。
+func (dag *DAG) SetLazyInputs(providers map[NodeID]InputFunc) {
+	dag.lazyInputs = providers
+}
+
+// withLazyInputはノードidの遅延入力を評価し、外部入力に連結した入力のマップを返します。
+// 遅延入力を持たないノードの場合はinputsをそのまま返します。呼び出し側のマップは変更しません。
+func (dag *DAG) withLazyInput(ctx context.Context, id NodeID, inputs map[NodeID][]string) (map[NodeID][]string, error) {
+	provider, ok := dag.lazyInputs[id]
+	if !ok {
+		return inputs, nil
+	}
+	lazy, err := provider(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("lazy input for node %s failed: %w", id, err)
+	}
+	return map[NodeID][]string{id: slices.Concat(inputs[id], lazy)}, nil
+}
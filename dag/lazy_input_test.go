@@ -0,0 +1,81 @@
+package dag_test
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGSetLazyInputs(t *testing.T) {
+	errToken := errors.New("token unavailable")
+
+	tests := []struct {
+		name          string
+		providerErr   error
+		expectedErr   error
+		expectedFinal map[dag.NodeID][]string
+	}{
+		{
+			name:          "Provider evaluated when node runs",
+			expectedFinal: map[dag.NodeID][]string{"use": {"static token-1 issued"}},
+		},
+		{
+			name:        "Provider error fails node",
+			providerErr: errToken,
+			expectedErr: errToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 依存元のノードが実行されるまでトークンは発行されない
+			var issued []string
+			workflow := dag.NewDAG(1)
+			workflow.AddNode("issue", node.NewTextNode("issue", func(inputs []string) (string, error) {
+				issued = append(issued, "token-1")
+				return "issued", nil
+			}))
+			workflow.AddNode("use", node.NewTextNode("use", func(inputs []string) (string, error) {
+				return strings.Join(inputs, " "), nil
+			}))
+			if err := workflow.AddEdge("issue", "use"); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+			workflow.SetLazyInputs(map[dag.NodeID]dag.InputFunc{
+				"use": func(ctx context.Context) ([]string, error) {
+					if tt.providerErr != nil {
+						return nil, tt.providerErr
+					}
+					return slices.Clone(issued), nil
+				},
+			})
+
+			statuses := recordStatuses(workflow)
+			result, err := workflow.Run(context.Background(), map[dag.NodeID][]string{
+				"issue": {"go"},
+				"use":   {"static"},
+			})
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				if statuses()["use"] != dag.Error {
+					t.Fatalf("expected node use to fail, got %s", statuses()["use"])
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !maps.EqualFunc(result.FinalOutputs, tt.expectedFinal, slices.Equal) {
+				t.Fatalf("expected final outputs %v, got %v", tt.expectedFinal, result.FinalOutputs)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package dag
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/momiom/workflow/node"
+)
+
+// startNodesはnode.Lifecycleを実装する全てのノードをNodeIDの昇順に開始し、開始したノードを停止する関数を返すメソッド
+// いずれかのノードの開始に失敗した場合は、それまでに開始したノードを停止してエラーを返します。
+func (dag *DAG) startNodes(ctx context.Context) (func(), error) {
+	var started []node.Lifecycle
+	stop := func() {
+		// 開始した順と逆の順に停止する
+		for i := len(started) - 1; i >= 0; i-- {
+			started[i].Stop()
+		}
+	}
+	for _, id := range dag.sortedNodeIDs() {
+		l, ok := dag.nodeMap[id].(node.Lifecycle)
+		if !ok {
+			continue
+		}
+		if err := l.Start(ctx); err != nil {
+			stop()
+			return nil, fmt.Errorf("failed to start node %s: %w", id, err)
+		}
+		started = append(started, l)
+	}
+	return stop, nil
+}
@@ -0,0 +1,102 @@
+package dag_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+// lifecycleNodeはStartとStopの呼び出しを記録するノードです。
+type lifecycleNode struct {
+	name     string
+	startErr error
+	events   *[]string
+	inputs   []string
+	outputs  []string
+}
+
+func (n *lifecycleNode) Execute(ctx context.Context) error {
+	*n.events = append(*n.events, "execute "+n.name)
+	n.outputs = n.inputs
+	return nil
+}
+
+func (n *lifecycleNode) Start(ctx context.Context) error {
+	*n.events = append(*n.events, "start "+n.name)
+	return n.startErr
+}
+
+func (n *lifecycleNode) Stop()                     { *n.events = append(*n.events, "stop "+n.name) }
+func (n *lifecycleNode) Name() string              { return n.name }
+func (n *lifecycleNode) SetInputs(inputs []string) { n.inputs = inputs }
+func (n *lifecycleNode) GetOutputs() []string      { return n.outputs }
+func (n *lifecycleNode) Reset()                    { n.inputs, n.outputs = nil, nil }
+
+func TestDAGServiceNodeKeepsStateAcrossRuns(t *testing.T) {
+	// カウンターのサービスは実行をまたいで値を保持する
+	count := 0
+	counter := node.NewServiceNode("counter", func(ctx context.Context, inputs []string) ([]string, error) {
+		count++
+		return []string{inputs[0] + "#" + strconv.Itoa(count)}, nil
+	})
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("counter", counter)
+
+	for i, expected := range []string{"run#1", "run#2"} {
+		drainChannels(workflow)
+		_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"counter": {"run"}})
+		if err != nil {
+			t.Fatalf("run %d: unexpected error: %v", i, err)
+		}
+		if got := finalOutputs["counter"]; !slices.Equal(got, []string{expected}) {
+			t.Fatalf("run %d: expected %v, got %v", i, []string{expected}, got)
+		}
+	}
+
+	// 実行の終了時に停止されるため、実行の外では使えない
+	if err := counter.Execute(context.Background()); !errors.Is(err, node.ErrServiceNotStarted) {
+		t.Fatalf("expected the service to be stopped after the run, got %v", err)
+	}
+}
+
+func TestDAGLifecycle(t *testing.T) {
+	errStart := errors.New("cannot connect")
+
+	tests := []struct {
+		name           string
+		startErr       error
+		expectedEvents []string
+	}{
+		{"Start before run and stop after", nil, []string{"start a", "start b", "execute a", "execute b", "stop b", "stop a"}},
+		{"Start failure stops started nodes", errStart, []string{"start a", "start b", "stop a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []string
+			workflow := dag.NewDAG(1)
+			workflow.AddNode("a", &lifecycleNode{name: "a", events: &events})
+			workflow.AddNode("b", &lifecycleNode{name: "b", startErr: tt.startErr, events: &events})
+			if err := workflow.AddEdge("a", "b"); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+
+			drainChannels(workflow)
+			result, err := workflow.Run(context.Background(), map[dag.NodeID][]string{"a": {"input"}})
+			if !errors.Is(err, tt.startErr) {
+				t.Fatalf("expected error %v, got %v", tt.startErr, err)
+			}
+			if tt.startErr != nil && result != nil {
+				t.Fatalf("expected no result when a node fails to start, got %v", result)
+			}
+			if !slices.Equal(events, tt.expectedEvents) {
+				t.Fatalf("expected events %v, got %v", tt.expectedEvents, events)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package dag
+
+import (
+	"sync"
+	"time"
+)
+
+// LimiterStatsは同時実行数の制限による待機の統計です。
+// 待機時間が長い場合は同時実行数の上限が低すぎることを示します。
+type LimiterStats struct {
+	Acquired  int           // 実行枠を獲得した回数
+	Blocked   int           // 空きがなく待機した回数
+	TotalWait time.Duration // 待機時間の合計
+	MaxWait   time.Duration // 待機時間の最大値
+}
+
+// AverageWaitは実行枠の獲得1回あたりの平均待機時間を返します。
+func (s LimiterStats) AverageWait() time.Duration {
+	if s.Acquired == 0 {
+		return 0
+	}
+	return s.TotalWait / time.Duration(s.Acquired)
+}
+
+// limiterは同時実行数を制限するセマフォです。実行枠の獲得までの待機を記録します。
+type limiter struct {
+	sem   chan struct{} // 実行枠（nilの場合は制限しない）
+	mu    sync.Mutex
+	stats LimiterStats
+}
+
+// newLimiterは同時にn個まで実行枠を獲得できるlimiterを作成します。nが0以下の場合は制限しません。
+func newLimiter(n int) *limiter {
+	if n <= 0 {
+		return &limiter{}
+	}
+	return &limiter{sem: make(chan struct{}, n)}
+}
+
+// tryAcquireは空きがあれば実行枠を獲得してtrueを返します。空きがない場合は待機せずにfalseを返します。
+// 待機の記録は実行可能になってからの時間を知っている呼び出し側がrecordで行います。
+func (l *limiter) tryAcquire() bool {
+	if l.sem == nil {
+		return true
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// releaseは実行枠を解放します。
+func (l *limiter) release() {
+	if l.sem == nil {
+		return
+	}
+	<-l.sem
+}
+
+func (l *limiter) record(blocked bool, wait time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats.Acquired++
+	if blocked {
+		l.stats.Blocked++
+	}
+	l.stats.TotalWait += wait
+	l.stats.MaxWait = max(l.stats.MaxWait, wait)
+}
+
+// Statsはこれまでの統計を返します。
+func (l *limiter) Stats() LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
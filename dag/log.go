@@ -0,0 +1,66 @@
+package dag
+
+import (
+	"context"
+	"log/slog"
+)
+
+// levelHandlerは指定したレベル以上のログを出力するかどうかを、元のハンドラーのレベルに関わらず決めるslog.Handlerです。
+// 元のハンドラーがInfoであっても、levelをDebugにすればDebugのログを出力します。
+type levelHandler struct {
+	level   slog.Leveler
+	handler slog.Handler
+}
+
+func (h *levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{level: h.level, handler: h.handler.WithAttrs(attrs)}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{level: h.level, handler: h.handler.WithGroup(name)}
+}
+
+// WithLoggerはDAGのログをloggerに出力するよう設定します。
+// 設定しない場合はslog.Default()に出力します。いずれの場合もログには "component"="dag" の属性が付き、
+// ノードのログには "node" 属性、状態変更のログには "status" 属性が付きます。
+func WithLogger(logger *slog.Logger) Option {
+	return func(dag *DAG) {
+		dag.logger = logger.With("component", "dag")
+	}
+}
+
+// logはDAGのロガーを返すメソッド
+func (dag *DAG) log() *slog.Logger {
+	if dag.logger != nil {
+		return dag.logger
+	}
+	// slog.Default()は後から差し替えられることがあるため、呼び出しごとに取得する
+	return slog.Default().With("component", "dag")
+}
+
+// nodeLoggerはノードのライフサイクルのログに使用するロガーを返すメソッド
+// ログにはノードのIDが "node" 属性として付きます。
+// SetNodeLogLevelでレベルが設定されている場合は、DAGのロガーのレベルではなく設定したレベルでログを絞り込みます。
+func (dag *DAG) nodeLogger(id NodeID) *slog.Logger {
+	logger := dag.log().With("node", id)
+	if level, ok := dag.nodeLogLevels[id]; ok {
+		logger = slog.New(&levelHandler{level: level, handler: logger.Handler()})
+	}
+	return logger
+}
+
+// ノードのライフサイクルのログを出力する最小レベルを設定するメソッド
+// DAGのロガーのレベルに関わらず設定したレベルが使われるため、DAG全体はInfoのまま
+// 詳細なログが必要なノードだけをDebugにするといった使い分けができます。
+// 設定しないノードのログはDAGのロガーの設定に従います。
+func (dag *DAG) SetNodeLogLevel(id NodeID, level slog.Level) {
+	dag.nodeLogLevels[id] = level
+}
@@ -0,0 +1,251 @@
+package dag_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGSetNodeLogLevel(t *testing.T) {
+	var buf bytes.Buffer
+	defaultLogger := slog.Default()
+	// DAG全体のロガーはInfoのまま、ノードごとにレベルを設定する
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	defer slog.SetDefault(defaultLogger)
+
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("noisy", node.NewTextNode("noisy", join))
+	workflow.AddNode("quiet", node.NewTextNode("quiet", join))
+	workflow.AddNode("default", node.NewTextNode("default", join))
+	workflow.SetNodeLogLevel("noisy", slog.LevelDebug)
+	workflow.SetNodeLogLevel("quiet", slog.LevelWarn)
+
+	drainChannels(workflow)
+	if _, _, err := workflow.Execute(context.Background(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	logs := buf.String()
+	if !strings.Contains(logs, "level=DEBUG") || !strings.Contains(logs, "node=noisy") {
+		t.Fatalf("expected debug logs for noisy node, got:\n%s", logs)
+	}
+	for _, line := range strings.Split(logs, "\n") {
+		if strings.Contains(line, "level=DEBUG") && !strings.Contains(line, "node=noisy") {
+			t.Fatalf("expected debug logs only for noisy node, got: %s", line)
+		}
+		if strings.Contains(line, "node=quiet") {
+			t.Fatalf("expected no logs below warn for quiet node, got: %s", line)
+		}
+	}
+}
+
+// captureHandlerは受け取ったログを属性とともに記録するslog.Handlerです。
+type captureHandler struct {
+	mu      *sync.Mutex
+	records *[]map[string]string
+	attrs   []slog.Attr
+}
+
+func newCaptureHandler() *captureHandler {
+	return &captureHandler{mu: &sync.Mutex{}, records: &[]map[string]string{}}
+}
+
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *captureHandler) Handle(_ context.Context, r slog.Record) error {
+	record := map[string]string{"msg": r.Message, "level": r.Level.String()}
+	for _, attr := range h.attrs {
+		record[attr.Key] = attr.Value.String()
+	}
+	r.Attrs(func(attr slog.Attr) bool {
+		record[attr.Key] = attr.Value.String()
+		return true
+	})
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.records = append(*h.records, record)
+	return nil
+}
+
+func (h *captureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &captureHandler{mu: h.mu, records: h.records, attrs: append(slices.Clone(h.attrs), attrs...)}
+}
+
+func (h *captureHandler) WithGroup(string) slog.Handler { return h }
+
+func TestDAGWithLogger(t *testing.T) {
+	handler := newCaptureHandler()
+	workflow := dag.NewDAG(1, dag.WithLogger(slog.New(handler)))
+	workflow.AddNode("a", node.NewTextNode("a", func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}))
+
+	drainChannels(workflow)
+	if _, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"a": {"hello"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	if len(*handler.records) == 0 {
+		t.Fatal("expected logs on the custom logger, got none")
+	}
+
+	var nodeScoped, completed bool
+	for _, record := range *handler.records {
+		if record["component"] != "dag" {
+			t.Fatalf("expected component=dag on every record, got %v", record)
+		}
+		if record["node"] == "a" {
+			nodeScoped = true
+			if record["status"] == string(dag.Completed) {
+				completed = true
+			}
+		}
+	}
+	if !nodeScoped {
+		t.Fatalf("expected node-scoped records, got %v", *handler.records)
+	}
+	if !completed {
+		t.Fatalf("expected a status record for the completed node, got %v", *handler.records)
+	}
+}
+
+func TestDAGEmptyExecute(t *testing.T) {
+	// ノードがないDAGはエラーとなる
+	empty := dag.NewDAG(1)
+	result, err := empty.Run(context.Background(), nil)
+	if !errors.Is(err, dag.ErrEmptyDAG) {
+		t.Fatalf("expected %v, got %v", dag.ErrEmptyDAG, err)
+	}
+	if result != nil {
+		t.Fatalf("expected no result, got %v", result)
+	}
+
+	// 全てのノードが依存元を持つ場合は警告を出力する
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+	handler := newCaptureHandler()
+	noRoot := dag.NewDAG(1, dag.WithLogger(slog.New(handler)))
+	noRoot.AddNode("a", node.NewTextNode("a", join))
+	noRoot.AddNode("b", node.NewTextNode("b", join))
+	if err := noRoot.AddEdge("a", "b"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if err := noRoot.AddEdge("b", "a"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if _, err := noRoot.Run(context.Background(), nil); err == nil {
+		t.Fatal("expected error for a DAG without root nodes")
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	warned := false
+	for _, record := range *handler.records {
+		if record["level"] == slog.LevelWarn.String() && strings.Contains(record["msg"], "no root nodes") {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Fatalf("expected a warning about missing root nodes, got %v", *handler.records)
+	}
+}
+
+// secretNodeは秘密情報を受け取れたかどうかを出力するノードです。
+type secretNode struct {
+	name    string
+	outputs []string
+}
+
+func (n *secretNode) Execute(ctx context.Context) error {
+	key, ok := node.Secret(ctx, "api_key")
+	if !ok || key != "sk-very-secret" {
+		return errors.New("api_key is not available")
+	}
+	n.outputs = []string{"authorized"}
+	return nil
+}
+
+func (n *secretNode) Name() string         { return n.name }
+func (n *secretNode) SetInputs([]string)   {}
+func (n *secretNode) GetOutputs() []string { return n.outputs }
+func (n *secretNode) Reset()               { n.outputs = nil }
+
+func TestDAGWithSecrets(t *testing.T) {
+	handler := newCaptureHandler()
+	secrets := node.Secrets{"api_key": "sk-very-secret"}
+	workflow := dag.NewDAG(1, dag.WithLogger(slog.New(handler)), dag.WithSecrets(secrets))
+	workflow.AddNode("call", &secretNode{name: "call"})
+
+	drainChannels(workflow)
+	outputs, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"call": {"hello"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := outputs["call"]; !slices.Equal(got, []string{"authorized"}) {
+		t.Fatalf("expected the node to receive the secret, got %v", got)
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	for _, record := range *handler.records {
+		for key, value := range record {
+			if strings.Contains(value, "sk-very-secret") {
+				t.Fatalf("expected the secret not to be logged, got %s=%s", key, value)
+			}
+		}
+	}
+}
+
+func TestDAGReportsUnreachedNodes(t *testing.T) {
+	// fail-fastモードでない場合、失敗したノードの依存先は実行可能にならない
+	handler := newCaptureHandler()
+	workflow := dag.NewDAG(1, dag.WithLogger(slog.New(handler)))
+	workflow.AddNode("broken", node.NewTextNode("broken", func(inputs []string) (string, error) {
+		return "", errors.New("broken")
+	}))
+	workflow.AddNode("orphan", node.NewIdentityNode("orphan"))
+	workflow.AddNode("healthy", node.NewIdentityNode("healthy"))
+	if err := workflow.AddEdge("broken", "orphan"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	drainChannels(workflow)
+	result, err := workflow.Run(context.Background(), map[dag.NodeID][]string{
+		"broken":  {"input"},
+		"healthy": {"input"},
+	})
+	if err == nil {
+		t.Fatal("expected error from the broken node")
+	}
+	if !slices.Equal(result.Unreached, []dag.NodeID{"orphan"}) {
+		t.Fatalf("expected orphan to be reported as unreached, got %v", result.Unreached)
+	}
+
+	handler.mu.Lock()
+	defer handler.mu.Unlock()
+	warned := false
+	for _, record := range *handler.records {
+		if record["level"] == slog.LevelWarn.String() && strings.Contains(record["msg"], "never became runnable") &&
+			strings.Contains(record["nodes"], "orphan") {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Fatalf("expected a warning about unreached nodes, got %v", *handler.records)
+	}
+}
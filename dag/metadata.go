@@ -0,0 +1,21 @@
+package dag
+
+import (
+	"maps"
+
+	"github.com/momiom/workflow/node"
+)
+
+// metadataOfはノードが返したメタデータの写しを返します。
+// node.MetadataProviderを実装していないノードや、メタデータが空のノードの場合はnilを返します。
+func metadataOf(n node.Node) map[string]any {
+	provider, ok := n.(node.MetadataProvider)
+	if !ok {
+		return nil
+	}
+	metadata := provider.Metadata()
+	if len(metadata) == 0 {
+		return nil
+	}
+	return maps.Clone(metadata)
+}
@@ -0,0 +1,73 @@
+package dag_test
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+// httpStatusNodeはHTTPのステータスコードと応答時間をメタデータとして返すノードです。
+type httpStatusNode struct {
+	inputs  []string
+	outputs []string
+}
+
+func (n *httpStatusNode) Execute(ctx context.Context) error {
+	n.outputs = n.inputs
+	return nil
+}
+
+func (n *httpStatusNode) Name() string              { return "http" }
+func (n *httpStatusNode) SetInputs(inputs []string) { n.inputs = inputs }
+func (n *httpStatusNode) GetOutputs() []string      { return n.outputs }
+func (n *httpStatusNode) Reset()                    { n.inputs, n.outputs = nil, nil }
+func (n *httpStatusNode) Metadata() map[string]any {
+	return map[string]any{"status_code": 200, "latency_ms": 12}
+}
+
+func TestDAGNodeMetadata(t *testing.T) {
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("http", &httpStatusNode{})
+	workflow.AddNode("llm", node.NewLLMNode("llm", &MockLLMClient{}))
+	workflow.AddNode("text", node.NewIdentityNode("text"))
+	if err := workflow.AddEdge("http", "llm"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if err := workflow.AddEdge("llm", "text"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	events := workflow.GetEvents()
+	ioMetadata := make(map[dag.NodeID]map[string]any)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range events {
+			if io, ok := e.(dag.IOEvent); ok && io.Metadata != nil {
+				ioMetadata[io.ID] = io.Metadata
+			}
+		}
+	}()
+
+	result, err := workflow.Run(context.Background(), map[dag.NodeID][]string{"http": {"hello"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	<-done
+
+	expected := map[dag.NodeID]map[string]any{
+		"http": {"status_code": 200, "latency_ms": 12},
+		// MockLLMClientは"mock response: "をプロンプトの前に付けて返す
+		"llm": {"prompt_length": 5, "response_length": 20},
+	}
+	equal := func(a, b map[string]any) bool { return maps.Equal(a, b) }
+	if !maps.EqualFunc(result.Metadata, expected, equal) {
+		t.Fatalf("expected metadata %v, got %v", expected, result.Metadata)
+	}
+	if !maps.EqualFunc(ioMetadata, expected, equal) {
+		t.Fatalf("expected io event metadata %v, got %v", expected, ioMetadata)
+	}
+}
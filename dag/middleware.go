@@ -0,0 +1,33 @@
+package dag
+
+import (
+	"context"
+
+	"github.com/momiom/workflow/node"
+)
+
+// NodeExecFuncはノードidのnを実行する関数です。
+type NodeExecFunc func(ctx context.Context, id NodeID, n node.Node) error
+
+// Middlewareはノードの実行を包む関数です。
+// nextを呼び出す前後に処理を追加することで、ログやメトリクスなどノードに共通する処理を実装できます。
+// nextを呼び出さずにエラーを返すと、ノードは実行されずにそのエラーで失敗します。
+type Middleware func(next NodeExecFunc) NodeExecFunc
+
+// Useはノードの実行を包むミドルウェアを追加するメソッド
+// ミドルウェアは追加した順に外側から適用され、再試行する場合は試行ごとに呼び出されます。
+// 実行中に呼び出すことはできません。
+func (dag *DAG) Use(mw ...Middleware) {
+	dag.middlewares = append(dag.middlewares, mw...)
+}
+
+// executeNodeはミドルウェアを適用してノードを実行するメソッド
+func (dag *DAG) executeNode(ctx context.Context, id NodeID, n node.Node) error {
+	exec := func(ctx context.Context, id NodeID, n node.Node) error {
+		return n.Execute(ctx)
+	}
+	for i := len(dag.middlewares) - 1; i >= 0; i-- {
+		exec = dag.middlewares[i](exec)
+	}
+	return exec(ctx, id, n)
+}
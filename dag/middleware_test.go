@@ -0,0 +1,86 @@
+package dag_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGUse(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+	workflow := dag.NewDAG(2)
+	for _, id := range []dag.NodeID{"a", "b", "c"} {
+		workflow.AddNode(id, node.NewTextNode(string(id), join))
+	}
+	for _, edge := range [][2]dag.NodeID{{"a", "c"}, {"b", "c"}} {
+		if err := workflow.AddEdge(edge[0], edge[1]); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+	}
+
+	// ミドルウェアは追加した順に外側から適用される
+	var mu sync.Mutex
+	calls := make(map[dag.NodeID][]string)
+	trace := func(name string) dag.Middleware {
+		return func(next dag.NodeExecFunc) dag.NodeExecFunc {
+			return func(ctx context.Context, id dag.NodeID, n node.Node) error {
+				mu.Lock()
+				calls[id] = append(calls[id], name+" before")
+				mu.Unlock()
+				err := next(ctx, id, n)
+				mu.Lock()
+				calls[id] = append(calls[id], name+" after")
+				mu.Unlock()
+				return err
+			}
+		}
+	}
+	workflow.Use(trace("outer"), trace("inner"))
+
+	drainChannels(workflow)
+	if _, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"a": {"1"}, "b": {"2"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"outer before", "inner before", "inner after", "outer after"}
+	for _, id := range []dag.NodeID{"a", "b", "c"} {
+		if !slices.Equal(calls[id], expected) {
+			t.Fatalf("expected %s to be wrapped as %v, got %v", id, expected, calls[id])
+		}
+	}
+}
+
+func TestDAGUseRejects(t *testing.T) {
+	errDenied := errors.New("denied")
+	executed := false
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("secret", node.NewTextNode("secret", func(inputs []string) (string, error) {
+		executed = true
+		return "", nil
+	}))
+	workflow.Use(func(next dag.NodeExecFunc) dag.NodeExecFunc {
+		return func(ctx context.Context, id dag.NodeID, n node.Node) error {
+			if id == "secret" {
+				return errDenied
+			}
+			return next(ctx, id, n)
+		}
+	})
+
+	drainChannels(workflow)
+	_, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"secret": {"x"}})
+	if !errors.Is(err, errDenied) {
+		t.Fatalf("expected %v, got %v", errDenied, err)
+	}
+	if executed {
+		t.Fatal("expected the node not to be executed")
+	}
+}
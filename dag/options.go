@@ -0,0 +1,102 @@
+package dag
+
+import (
+	"time"
+
+	"github.com/momiom/workflow/node"
+
+	"golang.org/x/time/rate"
+)
+
+// OptionはDAGの動作を設定する関数です。
+type Option func(*DAG)
+
+// WithFailFastは最初のエラーで実行全体を中断するモードを有効にします。
+// ノードがエラーを返すと実行中のコンテキストがキャンセルされ、実行中のノードはキャンセルを
+// 検知して停止し、まだ開始していないノードは実行されません。
+func WithFailFast() Option {
+	return func(dag *DAG) {
+		dag.failFast = true
+	}
+}
+
+// WithRateLimiterは各ノードの実行前にレートリミッターで待機するよう設定します。
+// LLMなど呼び出し回数に制限のあるサービスへのリクエストを間引くために使用します。
+// 待機中にコンテキストがキャンセルされた場合、そのノードはエラーとなります。
+func WithRateLimiter(r *rate.Limiter) Option {
+	return func(dag *DAG) {
+		dag.rateLimiter = r
+	}
+}
+
+// WithGroupはノードをリソースグループに所属させます。
+// グループに所属するノードは、全体の同時実行数の代わりにWithGroupLimitで設定したグループの
+// 同時実行数で制限されます。上限が設定されていないグループのノードは全体の上限に従います。
+func WithGroup(id NodeID, group string) Option {
+	return func(dag *DAG) {
+		dag.nodeGroups[id] = group
+	}
+}
+
+// WithGroupLimitはリソースグループごとの同時実行数の上限を設定します。
+// 例えばLLMノードだけを最大2並列に制限し、テキスト処理は並列に実行するといった使い方ができます。
+// nが0以下の場合、そのグループのノードの同時実行数は制限されません。
+func WithGroupLimit(group string, n int) Option {
+	return func(dag *DAG) {
+		dag.groupLimits[group] = n
+	}
+}
+
+// WithPriorityはノードの実行の優先度を設定します。
+// 同時実行数の上限により複数のノードが実行枠を待っている場合、優先度の高いノードから実行枠を獲得します。
+// 優先度を設定しないノードの優先度は0で、同じ優先度のノードは実行可能になった順に実行されます。
+func WithPriority(id NodeID, p int) Option {
+	return func(dag *DAG) {
+		dag.priorities[id] = p
+	}
+}
+
+// WithFinalAggregatorは完了したリーフノードの出力をまとめる関数を設定します。
+// aggregatorの結果はExecuteResultのAggregatedとExecuteAggregatedで取得できます。
+// 設定しない場合は、リーフノードの出力をNodeIDの昇順に並べて1つにしたものになります。
+func WithFinalAggregator(aggregator func(finalOutputs map[NodeID][]string) []string) Option {
+	return func(dag *DAG) {
+		dag.finalAggregator = aggregator
+	}
+}
+
+// WithDeterministicはノードを安定したトポロジカル順（DryRunが返す順序）に1つずつ実行するモードを有効にします。
+// 同時実行数の上限、リソースグループ、優先度の設定は無視されます。
+// ノードの実行順序とイベントの順序が毎回同じになるため、実行結果を記録したファイルと比較するテストなどに使用します。
+func WithDeterministic() Option {
+	return func(dag *DAG) {
+		dag.deterministic = true
+	}
+}
+
+// WithDropDisabledOutputsはSetEnabledで無効にしたノードが、受け取った入力を依存先ノードに渡さないよう設定します。
+// 他に入力を持たない依存先ノードは、無効にしたノードとともにスキップされます。
+func WithDropDisabledOutputs() Option {
+	return func(dag *DAG) {
+		dag.dropDisabled = true
+	}
+}
+
+// WithSecretsは実行中のノードにsecretsを渡すよう設定します。
+// ノードはExecuteに渡されたコンテキストからnode.Secretで値を取り出せます。Executeに渡したコンテキストに
+// node.WithSecretsで設定した秘密情報がある場合、名前が同じものはこの設定の値が優先されます。
+// 秘密情報はDAGのログに出力されません。
+func WithSecrets(secrets node.Secrets) Option {
+	return func(dag *DAG) {
+		dag.secrets = secrets
+	}
+}
+
+// WithWorkflowTimeoutは実行全体の制限時間を設定します。
+// ノードごとのタイムアウトとは異なり、実行を開始してからdを超えると実行中のノードのコンテキストがキャンセルされ、
+// まだ開始していないノードは実行されません。実行はErrWorkflowTimeoutを返し、結果にはそれまでに完了したノードの出力が含まれます。
+func WithWorkflowTimeout(d time.Duration) Option {
+	return func(dag *DAG) {
+		dag.workflowTimeout = d
+	}
+}
@@ -0,0 +1,67 @@
+package dag
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTracerは実行全体とノードごとにOpenTelemetryのスパンを作成するよう設定します。
+// 実行全体のスパン "DAG Execution" の子として、ノードごとに "Node <id>" のスパンが作成され、
+// ノードの状態と実行時間が属性として記録されます。ノードのExecuteに渡されるコンテキストには
+// ノードのスパンが設定されるため、ノードの中で作成したスパンはその子になります。
+// 設定しない場合、runtime/traceのタスクとリージョンだけが記録されます。
+func WithTracer(tracer trace.Tracer) Option {
+	return func(dag *DAG) {
+		dag.tracer = tracer
+	}
+}
+
+// startRunSpanは実行全体のスパンを開始し、終了状態を記録してスパンを終える関数を返すメソッド
+// トレーサーが設定されていない場合は何もしません。
+func (dag *DAG) startRunSpan(ctx context.Context) (context.Context, func(status RunStatus, err error)) {
+	if dag.tracer == nil {
+		return ctx, func(RunStatus, error) {}
+	}
+	ctx, span := dag.tracer.Start(ctx, "DAG Execution", trace.WithAttributes(attribute.Int("workflow.nodes", len(dag.nodes))))
+	return ctx, func(status RunStatus, err error) {
+		span.SetAttributes(attribute.String("workflow.status", string(status)))
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// startNodeSpanはノードのスパンを開始し、ノードの状態を記録してスパンを終える関数を返すメソッド
+// トレーサーが設定されていない場合は何もしません。
+func (dag *DAG) startNodeSpan(ctx context.Context, id NodeID) (context.Context, func()) {
+	if dag.tracer == nil {
+		return ctx, func() {}
+	}
+	start := time.Now()
+	ctx, span := dag.tracer.Start(ctx, fmt.Sprintf("Node %s", id), trace.WithAttributes(attribute.String("workflow.node.id", string(id))))
+	return ctx, func() {
+		dag.statusMu.Lock()
+		status := dag.nodeStatus[id]
+		dag.statusMu.Unlock()
+		span.SetAttributes(
+			attribute.String("workflow.node.status", string(status)),
+			attribute.Int64("workflow.node.duration_ms", time.Since(start).Milliseconds()),
+		)
+		if status == Error {
+			span.SetStatus(codes.Error, "node failed")
+		}
+		span.End()
+	}
+}
+
+// recordSpanErrorはコンテキストのスパンにノードのエラーを記録します。
+// スパンがない場合は何もしません。
+func recordSpanError(ctx context.Context, err error) {
+	trace.SpanFromContext(ctx).RecordError(err)
+}
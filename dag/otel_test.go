@@ -0,0 +1,88 @@
+package dag_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestDAGWithTracer(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer provider.Shutdown(context.Background())
+
+	errBroken := errors.New("broken")
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+	workflow := dag.NewDAG(2, dag.WithTracer(provider.Tracer("workflow")))
+	workflow.AddNode("a", node.NewTextNode("a", join))
+	workflow.AddNode("b", node.NewTextNode("b", join))
+	workflow.AddNode("c", node.NewTextNode("c", func([]string) (string, error) { return "", errBroken }))
+	if err := workflow.AddEdge("a", "b"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if err := workflow.AddEdge("a", "c"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	drainChannels(workflow)
+	if _, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"a": {"hello"}}); !errors.Is(err, errBroken) {
+		t.Fatalf("expected %v, got %v", errBroken, err)
+	}
+
+	spans := exporter.GetSpans()
+	var run tracetest.SpanStub
+	nodeSpans := make(map[string]tracetest.SpanStub)
+	for _, span := range spans {
+		if span.Name == "DAG Execution" {
+			run = span
+			continue
+		}
+		nodeSpans[span.Name] = span
+	}
+	if !run.SpanContext.IsValid() {
+		t.Fatalf("expected a run span, got %v", spans)
+	}
+
+	var names []string
+	for name := range nodeSpans {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	if expected := []string{"Node a", "Node b", "Node c"}; !slices.Equal(names, expected) {
+		t.Fatalf("expected node spans %v, got %v", expected, names)
+	}
+
+	for name, span := range nodeSpans {
+		if span.Parent.SpanID() != run.SpanContext.SpanID() {
+			t.Fatalf("expected %s to be a child of the run span", name)
+		}
+		attrs := make(map[string]string)
+		for _, attr := range span.Attributes {
+			attrs[string(attr.Key)] = attr.Value.Emit()
+		}
+		if _, ok := attrs["workflow.node.duration_ms"]; !ok {
+			t.Fatalf("expected a duration attribute on %s, got %v", name, attrs)
+		}
+		expectedStatus := string(dag.Completed)
+		if name == "Node c" {
+			expectedStatus = string(dag.Error)
+			if span.Status.Code != codes.Error || len(span.Events) == 0 {
+				t.Fatalf("expected %s to record the error, got %v", name, span)
+			}
+		}
+		if attrs["workflow.node.status"] != expectedStatus {
+			t.Fatalf("expected status %s on %s, got %v", expectedStatus, name, attrs)
+		}
+	}
+}
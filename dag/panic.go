@@ -0,0 +1,36 @@
+package dag
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicErrorはノードのExecuteがパニックしたことを示すエラーです。
+// パニックはノードのエラーとして扱われ、他のノードの実行はfailFastの設定に従って継続または中断されます。
+type PanicError struct {
+	Value any    // recoverで回収した値
+	Stack []byte // パニックした時点のスタックトレース
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("node panicked: %v", e.Value)
+}
+
+// Unwrapは回収した値がエラーの場合にそのエラーを返します。
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// safeExecuteはノードを実行するexecを呼び出し、パニックした場合は*PanicErrorとして返します。
+// ミドルウェアのパニックも同様に回収します。
+func safeExecute(exec func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return exec()
+}
@@ -0,0 +1,65 @@
+package dag
+
+import (
+	"fmt"
+	"path"
+	"slices"
+)
+
+// パターンに一致するノード同士を全て接続するメソッド
+// fromPatternに一致する全てのノードから、toPatternに一致する全てのノードへエッジを追加します。
+// パターンはpath.Matchの形式で、例えば "source_*" から "sink" へのエッジを一度に追加できます。
+// 依存元ノードの順序はNodeIDの昇順になり、両方のパターンに一致するノード自身へのエッジは追加しません。
+// いずれかのパターンが1つもノードに一致しない場合や、既に存在するエッジが含まれる場合は、
+// エッジを1つも追加せずにエラーを返します。
+func (dag *DAG) AddEdgesFromPattern(fromPattern, toPattern string) error {
+	dag.log().Debug("Adding edges from pattern", "from", fromPattern, "to", toPattern)
+
+	froms, err := dag.matchNodes(fromPattern)
+	if err != nil {
+		return err
+	}
+	tos, err := dag.matchNodes(toPattern)
+	if err != nil {
+		return err
+	}
+
+	var edges []edgeKey
+	for _, from := range froms {
+		for _, to := range tos {
+			if from == to {
+				continue
+			}
+			if dag.graph.HasEdgeFromTo(dag.nodes[from].ID(), dag.nodes[to].ID()) {
+				return fmt.Errorf("edge %s -> %s already exists", from, to)
+			}
+			edges = append(edges, edgeKey{from: from, to: to})
+		}
+	}
+	for _, edge := range edges {
+		if err := dag.AddEdge(edge.from, edge.to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchNodesはpatternに一致するノードのIDを昇順に返すメソッド
+// 一致するノードがない場合はエラーを返します。
+func (dag *DAG) matchNodes(pattern string) ([]NodeID, error) {
+	var matched []NodeID
+	for id := range dag.nodes {
+		ok, err := path.Match(pattern, string(id))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if ok {
+			matched = append(matched, id)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("pattern %q matches no nodes", pattern)
+	}
+	slices.Sort(matched)
+	return matched, nil
+}
@@ -0,0 +1,83 @@
+package dag_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGAddEdgesFromPattern(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+	newWorkflow := func() *dag.DAG {
+		workflow := dag.NewDAG(2)
+		for _, id := range []dag.NodeID{"source_b", "source_a", "source_c", "other", "sink"} {
+			workflow.AddNode(id, node.NewTextNode(string(id), join))
+		}
+		return workflow
+	}
+
+	t.Run("Connect sources to sink", func(t *testing.T) {
+		workflow := newWorkflow()
+		if err := workflow.AddEdgesFromPattern("source_*", "sink"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		drainChannels(workflow)
+		inputs := map[dag.NodeID][]string{"source_a": {"a"}, "source_b": {"b"}, "source_c": {"c"}, "other": {"o"}}
+		_, finalOutputs, err := workflow.Execute(context.Background(), inputs)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// 依存元ノードの出力はNodeIDの昇順に連結される
+		if got := finalOutputs["sink"]; !slices.Equal(got, []string{"a b c"}) {
+			t.Fatalf("expected sink to receive all sources in order, got %v", got)
+		}
+		if _, ok := finalOutputs["other"]; !ok {
+			t.Fatalf("expected other to remain a leaf, got %v", finalOutputs)
+		}
+	})
+
+	tests := []struct {
+		name          string
+		fromPattern   string
+		toPattern     string
+		expectedError string
+	}{
+		{"No matching source", "missing_*", "sink", `pattern "missing_*" matches no nodes`},
+		{"No matching sink", "source_*", "target", `pattern "target" matches no nodes`},
+		{"Invalid pattern", "source_[", "sink", "invalid pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := newWorkflow()
+			err := workflow.AddEdgesFromPattern(tt.fromPattern, tt.toPattern)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+			}
+			if roots := workflow.GetRootNodes(); len(roots) != 5 {
+				t.Fatalf("expected no edges to be added, got roots %v", roots)
+			}
+		})
+	}
+
+	t.Run("Existing edge adds nothing", func(t *testing.T) {
+		workflow := newWorkflow()
+		if err := workflow.AddEdge("source_b", "sink"); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+		err := workflow.AddEdgesFromPattern("source_*", "sink")
+		if err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Fatalf("expected an already exists error, got %v", err)
+		}
+		if roots := workflow.GetRootNodes(); len(roots) != 4 {
+			t.Fatalf("expected only the existing edge, got roots %v", roots)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package dag
+
+// 新しいノードの開始を一時停止するメソッド
+// 実行中のノードはそのまま終了まで実行され、実行可能になったノードはResumeを呼び出すまで開始されません。
+// 一時停止中もコンテキストのキャンセルやShutdownにより実行を中断できます。
+// 実行していないときに呼び出した場合は、次の実行が一時停止した状態で始まります。
+// 実行中の各ノードの状態はGetStatusChanやGetEventsで確認できます。
+func (dag *DAG) Pause() {
+	dag.runMu.Lock()
+	defer dag.runMu.Unlock()
+	dag.log().Debug("Pausing DAG")
+	dag.paused = true
+}
+
+// Pauseで一時停止した実行を再開するメソッド
+// 一時停止していない場合は何もしません。
+func (dag *DAG) Resume() {
+	dag.runMu.Lock()
+	wasPaused := dag.paused
+	dag.paused = false
+	run := dag.activeRun
+	dag.runMu.Unlock()
+	if !wasPaused {
+		return
+	}
+
+	dag.log().Debug("Resuming DAG")
+	if run != nil {
+		run.wake()
+	}
+}
+
+// isPausedはPauseにより新しいノードの開始を止めているかどうかを返します。
+func (dag *DAG) isPaused() bool {
+	dag.runMu.Lock()
+	defer dag.runMu.Unlock()
+	return dag.paused
+}
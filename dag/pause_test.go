@@ -0,0 +1,92 @@
+package dag_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+// newPausingDAGは最初のノードの実行中にPauseを呼び出すDAGを作成します。
+// 2番目のノードが開始されるとsecondStartedがtrueになります。
+func newPausingDAG(secondStarted *atomic.Bool) *dag.DAG {
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("first", node.NewTextNode("first", func(inputs []string) (string, error) {
+		workflow.Pause()
+		return inputs[0], nil
+	}))
+	workflow.AddNode("second", node.NewTextNode("second", func(inputs []string) (string, error) {
+		secondStarted.Store(true)
+		return inputs[0] + "!", nil
+	}))
+	if err := workflow.AddEdge("first", "second"); err != nil {
+		panic(err)
+	}
+	return workflow
+}
+
+func TestDAGPauseResume(t *testing.T) {
+	var secondStarted atomic.Bool
+	workflow := newPausingDAG(&secondStarted)
+
+	drainChannels(workflow)
+	type runResult struct {
+		result *dag.ExecuteResult
+		err    error
+	}
+	done := make(chan runResult, 1)
+	go func() {
+		result, err := workflow.Run(context.Background(), map[dag.NodeID][]string{"first": {"hello"}})
+		done <- runResult{result, err}
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected the run to wait while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if secondStarted.Load() {
+		t.Fatal("expected second node not to start while paused")
+	}
+
+	workflow.Resume()
+	select {
+	case r := <-done:
+		if r.err != nil {
+			t.Fatalf("unexpected error: %v", r.err)
+		}
+		if got := r.result.FinalOutputs["second"]; !slices.Equal(got, []string{"hello!"}) {
+			t.Fatalf("expected second node output after resume, got %v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the run to finish after resume")
+	}
+	if !secondStarted.Load() {
+		t.Fatal("expected second node to start after resume")
+	}
+}
+
+func TestDAGPauseRespectsCancellation(t *testing.T) {
+	var secondStarted atomic.Bool
+	workflow := newPausingDAG(&secondStarted)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	statuses := recordStatuses(workflow)
+	if _, err := workflow.Run(ctx, map[dag.NodeID][]string{"first": {"hello"}}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	// 一時停止中にキャンセルされたため、実行枠を待っていたノードはスキップされる
+	if statuses()["second"] != dag.Skipped {
+		t.Fatalf("expected second node to be skipped, got %s", statuses()["second"])
+	}
+	if secondStarted.Load() {
+		t.Fatal("expected second node not to start after cancellation")
+	}
+	workflow.Resume()
+}
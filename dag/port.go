@@ -0,0 +1,49 @@
+package dag
+
+import (
+	"fmt"
+
+	"github.com/momiom/workflow/node"
+)
+
+// 出力ポートを指定したエッジをDAGに追加するメソッド
+// fromのノードはnode.NamedOutputerを実装している必要があり、toにはfromのNamedOutputsのうち
+// portの値だけが1つの入力として渡されます。fromがそのポートの出力を返さなかった場合、このエッジからは何も渡されません。
+// 同じノードの別のポートを別の依存先ノードに接続することで、出力を用途ごとに振り分けられます。
+func (dag *DAG) AddPortEdge(from NodeID, port string, to NodeID) error {
+	dag.log().Debug("Adding port edge", "from", from, "port", port, "to", to)
+
+	if n, ok := dag.nodeMap[from]; ok {
+		if _, ok := n.(node.NamedOutputer); !ok {
+			return fmt.Errorf("node %s does not provide named outputs", from)
+		}
+	}
+	if err := dag.AddEdge(from, to); err != nil {
+		return err
+	}
+	dag.edgeOutputs[edgeKey{from: from, to: to}] = port
+	return nil
+}
+
+// keyedOutputsOfはノードのキーごとの出力を返します。
+// node.NamedOutputerの名前付きの出力は、値を1つだけ持つキーごとの出力として扱います。
+// 両方を実装するノードでは、同じ名前はnode.KeyedOutputerの出力が優先されます。
+// どちらも実装しないノードの場合はnilを返します。
+func keyedOutputsOf(n node.Node) map[string][]string {
+	var keyed map[string][]string
+	if named, ok := n.(node.NamedOutputer); ok {
+		keyed = make(map[string][]string)
+		for port, output := range named.NamedOutputs() {
+			keyed[port] = []string{output}
+		}
+	}
+	if k, ok := n.(node.KeyedOutputer); ok {
+		if keyed == nil {
+			return k.GetKeyedOutputs()
+		}
+		for key, outputs := range k.GetKeyedOutputs() {
+			keyed[key] = outputs
+		}
+	}
+	return keyed
+}
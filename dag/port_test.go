@@ -0,0 +1,127 @@
+package dag_test
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+// reportNodeは入力から要約と詳細の2つのポートに出力するノードです。
+type reportNode struct {
+	inputs  []string
+	outputs []string
+	named   map[string]string
+}
+
+func (n *reportNode) Execute(ctx context.Context) error {
+	n.outputs = n.inputs
+	n.named = map[string]string{
+		"summary": n.inputs[0],
+		"detail":  strings.Join(n.inputs, "\n"),
+	}
+	return nil
+}
+
+func (n *reportNode) Name() string                    { return "report" }
+func (n *reportNode) SetInputs(inputs []string)       { n.inputs = inputs }
+func (n *reportNode) GetOutputs() []string            { return n.outputs }
+func (n *reportNode) Reset()                          { n.inputs, n.outputs, n.named = nil, nil, nil }
+func (n *reportNode) NamedOutputs() map[string]string { return n.named }
+
+func TestDAGAddPortEdge(t *testing.T) {
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("report", &reportNode{})
+	workflow.AddNode("title", node.NewIdentityNode("title"))
+	workflow.AddNode("body", node.NewIdentityNode("body"))
+	workflow.AddNode("all", node.NewIdentityNode("all"))
+	if err := workflow.AddPortEdge("report", "summary", "title"); err != nil {
+		t.Fatalf("failed to add port edge: %v", err)
+	}
+	if err := workflow.AddPortEdge("report", "detail", "body"); err != nil {
+		t.Fatalf("failed to add port edge: %v", err)
+	}
+	// ポートを指定しないエッジには従来どおりGetOutputsが渡される
+	if err := workflow.AddEdge("report", "all"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	drainChannels(workflow)
+	_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"report": {"headline", "line 2"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[dag.NodeID][]string{
+		"title": {"headline"},
+		"body":  {"headline\nline 2"},
+		"all":   {"headline", "line 2"},
+	}
+	for id, want := range expected {
+		if got := finalOutputs[id]; !slices.Equal(got, want) {
+			t.Fatalf("expected %v for %s, got %v", want, id, got)
+		}
+	}
+}
+
+func TestDAGAddPortEdgeErrors(t *testing.T) {
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("report", &reportNode{})
+	workflow.AddNode("plain", node.NewIdentityNode("plain"))
+
+	tests := []struct {
+		name          string
+		from, to      dag.NodeID
+		expectedError string
+	}{
+		{"Positional node", "plain", "report", "node plain does not provide named outputs"},
+		{"Unknown node", "missing", "plain", "node missing does not exist"},
+		{"Unknown successor", "report", "missing", "node missing does not exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := workflow.AddPortEdge(tt.from, "summary", tt.to); err == nil || err.Error() != tt.expectedError {
+				t.Fatalf("expected %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestDAGFanOutNode(t *testing.T) {
+	fanOut := node.NewFanOutNode("fanOut")
+	fanOut.AddBranch("shout", func(input string) (string, error) {
+		return strings.ToUpper(input) + "!", nil
+	})
+	fanOut.AddBranch("whisper", func(input string) (string, error) {
+		return strings.ToLower(input) + "...", nil
+	})
+
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("fanOut", fanOut)
+	workflow.AddNode("loud", node.NewIdentityNode("loud"))
+	workflow.AddNode("quiet", node.NewIdentityNode("quiet"))
+	if err := workflow.AddPortEdge("fanOut", "shout", "loud"); err != nil {
+		t.Fatalf("failed to add port edge: %v", err)
+	}
+	if err := workflow.AddPortEdge("fanOut", "whisper", "quiet"); err != nil {
+		t.Fatalf("failed to add port edge: %v", err)
+	}
+
+	drainChannels(workflow)
+	_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"fanOut": {"Hello"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[dag.NodeID][]string{
+		"loud":  {"HELLO!"},
+		"quiet": {"hello..."},
+	}
+	if !maps.EqualFunc(finalOutputs, expected, slices.Equal) {
+		t.Fatalf("expected %v, got %v", expected, finalOutputs)
+	}
+}
@@ -0,0 +1,105 @@
+package dag
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// statusColorsはグラフの描画でノードの状態ごとに使用する塗りつぶしの色です。
+var statusColors = map[NodeStatus]string{
+	Pending:   "#e5e7eb",
+	Ready:     "#bfdbfe",
+	Running:   "#fde68a",
+	Completed: "#bbf7d0",
+	Error:     "#fecaca",
+	Skipped:   "#f3f4f6",
+	Cached:    "#ddd6fe",
+}
+
+// renderGraphは描画の時点のノードとエッジの状態です。
+type renderGraph struct {
+	ids      []NodeID // NodeIDの昇順
+	statuses map[NodeID]NodeStatus
+	edges    []edgeKey // 依存元、依存先のNodeIDの昇順
+	optional map[edgeKey]bool
+}
+
+// snapshotGraphは描画するノードとエッジを、その時点の状態とともに返すメソッド
+// 実行中にSpawnerで追加したノードとエッジがDAGに反映されることがあるため、statusMuを保持して読み出します。
+func (dag *DAG) snapshotGraph() renderGraph {
+	dag.statusMu.Lock()
+	defer dag.statusMu.Unlock()
+
+	g := renderGraph{
+		ids:      make([]NodeID, 0, len(dag.nodes)),
+		statuses: make(map[NodeID]NodeStatus, len(dag.nodes)),
+		edges:    dag.sortedEdges(),
+		optional: make(map[edgeKey]bool),
+	}
+	for id := range dag.nodes {
+		g.ids = append(g.ids, id)
+		g.statuses[id] = dag.nodeStatus[id]
+	}
+	slices.Sort(g.ids)
+	for _, edge := range g.edges {
+		if dag.optionalEdges[edge] {
+			g.optional[edge] = true
+		}
+	}
+	return g
+}
+
+// DAGをMermaidのフローチャートとして返すメソッド
+// 各ノードはその時点の状態に応じて色分けされ、任意のエッジは破線で描かれます。
+// 実行中にも呼び出すことができ、呼び出し時点の状態が反映されます。
+func (dag *DAG) ToMermaid() string {
+	g := dag.snapshotGraph()
+	// NodeIDにはMermaidで使用できない文字が含まれる場合があるため、連番のIDにラベルとして付ける
+	mermaidIDs := make(map[NodeID]string, len(g.ids))
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	for i, id := range g.ids {
+		mermaidIDs[id] = fmt.Sprintf("n%d", i)
+		label := strings.ReplaceAll(string(id), `"`, "#quot;")
+		fmt.Fprintf(&b, "    %s[\"%s\"]\n", mermaidIDs[id], label)
+	}
+	for _, edge := range g.edges {
+		arrow := "-->"
+		if g.optional[edge] {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "    %s %s %s\n", mermaidIDs[edge.from], arrow, mermaidIDs[edge.to])
+	}
+	for _, id := range g.ids {
+		if color, ok := statusColors[g.statuses[id]]; ok {
+			fmt.Fprintf(&b, "    style %s fill:%s\n", mermaidIDs[id], color)
+		}
+	}
+	return b.String()
+}
+
+// DAGをGraphvizのDOT形式で返すメソッド
+// ToMermaidと同様に、各ノードはその時点の状態に応じて色分けされ、任意のエッジは破線で描かれます。
+func (dag *DAG) ToDOT() string {
+	g := dag.snapshotGraph()
+	var b strings.Builder
+	b.WriteString("digraph workflow {\n")
+	for _, id := range g.ids {
+		attrs := fmt.Sprintf("label=%s", strconv.Quote(string(id)))
+		if color, ok := statusColors[g.statuses[id]]; ok {
+			attrs += fmt.Sprintf(", style=filled, fillcolor=%s", strconv.Quote(color))
+		}
+		fmt.Fprintf(&b, "    %s [%s];\n", strconv.Quote(string(id)), attrs)
+	}
+	for _, edge := range g.edges {
+		attrs := ""
+		if g.optional[edge] {
+			attrs = " [style=dashed]"
+		}
+		fmt.Fprintf(&b, "    %s -> %s%s;\n", strconv.Quote(string(edge.from)), strconv.Quote(string(edge.to)), attrs)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,76 @@
+package dag_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGRender(t *testing.T) {
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+	workflow := dag.NewDAG(1)
+	for _, id := range []dag.NodeID{"b", "a", `say "hi"`} {
+		workflow.AddNode(id, node.NewTextNode(string(id), join))
+	}
+	if err := workflow.AddEdge("a", "b"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if err := workflow.AddOptionalEdge("a", `say "hi"`); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		render   func() string
+		expected string
+	}{
+		{
+			name:   "Mermaid",
+			render: workflow.ToMermaid,
+			expected: `flowchart TD
+    n0["a"]
+    n1["b"]
+    n2["say #quot;hi#quot;"]
+    n0 --> n1
+    n0 -.-> n2
+    style n0 fill:#e5e7eb
+    style n1 fill:#e5e7eb
+    style n2 fill:#e5e7eb
+`,
+		},
+		{
+			name:   "DOT",
+			render: workflow.ToDOT,
+			expected: `digraph workflow {
+    "a" [label="a", style=filled, fillcolor="#e5e7eb"];
+    "b" [label="b", style=filled, fillcolor="#e5e7eb"];
+    "say \"hi\"" [label="say \"hi\"", style=filled, fillcolor="#e5e7eb"];
+    "a" -> "b";
+    "a" -> "say \"hi\"" [style=dashed];
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.render(); got != tt.expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+
+	// 実行後は完了したノードの色で描かれる
+	drainChannels(workflow)
+	if _, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"a": {"x"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := workflow.ToMermaid(); !strings.Contains(got, "style n1 fill:#bbf7d0") {
+		t.Fatalf("expected b to be rendered as completed, got:\n%s", got)
+	}
+}
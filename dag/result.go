@@ -0,0 +1,51 @@
+package dag
+
+import (
+	"slices"
+	"strings"
+)
+
+// RunStatusはDAGの実行全体の終了状態です。
+type RunStatus string
+
+const (
+	RunCompleted RunStatus = "Completed" // 全ての実行可能なノードを実行した
+	RunStopped   RunStatus = "Stopped"   // 停止条件を満たしたため途中で終了した
+	RunShutdown  RunStatus = "Shutdown"  // Shutdownにより新しいノードを開始せずに終了した
+	RunFailed    RunStatus = "Failed"    // ノードのエラーにより途中で終了した
+	RunAborted   RunStatus = "Aborted"   // ノードがnode.ErrAbortを返したため途中で終了した
+)
+
+// ExecuteResultはDAGの実行結果です。
+type ExecuteResult struct {
+	Status       RunStatus           // 実行全体の終了状態
+	Outputs      map[NodeID][]string // 全ノードの出力
+	FinalOutputs map[NodeID][]string // 完了したリーフノードの出力
+	Aggregated   []string            // WithFinalAggregatorでまとめたリーフノードの出力
+	LimiterStats LimiterStats        // 同時実行数の制限による待機の統計
+
+	GroupLimiterStats map[string]LimiterStats   // リソースグループごとの待機の統計
+	Unreached         []NodeID                  // 実行可能にならずにPendingのまま残ったノード（NodeIDの昇順）
+	Metadata          map[NodeID]map[string]any // node.MetadataProviderを実装するノードが返したメタデータ
+}
+
+// JoinFinalはリーフノードの出力をNodeIDの昇順に並べ、sepで連結した文字列を返します。
+// 1つのノードが複数の出力を持つ場合は、その順序のまま連結されます。
+func (r *ExecuteResult) JoinFinal(sep string) string {
+	return strings.Join(r.flattenFinal(), sep)
+}
+
+// flattenFinalはリーフノードの出力をNodeIDの昇順に並べた1つのスライスを返します。
+func (r *ExecuteResult) flattenFinal() []string {
+	ids := make([]NodeID, 0, len(r.FinalOutputs))
+	for id := range r.FinalOutputs {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	var parts []string
+	for _, id := range ids {
+		parts = append(parts, r.FinalOutputs[id]...)
+	}
+	return parts
+}
@@ -0,0 +1,145 @@
+package dag_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestExecuteResultJoinFinal(t *testing.T) {
+	tests := []struct {
+		name     string
+		final    map[dag.NodeID][]string
+		sep      string
+		expected string
+	}{
+		{"Ordered by node ID", map[dag.NodeID][]string{"c": {"3"}, "a": {"1"}, "b": {"2"}}, ", ", "1, 2, 3"},
+		{"Multiple outputs per node", map[dag.NodeID][]string{"b": {"3"}, "a": {"1", "2"}}, "|", "1|2|3"},
+		{"Empty separator", map[dag.NodeID][]string{"b": {"y"}, "a": {"x"}}, "", "xy"},
+		{"No final outputs", nil, ", ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &dag.ExecuteResult{FinalOutputs: tt.final}
+			if got := result.JoinFinal(tt.sep); got != tt.expected {
+				t.Fatalf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRunJoinFinal(t *testing.T) {
+	upper := func(inputs []string) (string, error) {
+		return strings.ToUpper(strings.Join(inputs, " ")), nil
+	}
+
+	workflow := dag.NewDAG(3)
+	workflow.AddNode("root", node.NewTextNode("root", upper))
+	for _, id := range []dag.NodeID{"leaf2", "leaf1", "leaf3"} {
+		workflow.AddNode(id, node.NewTextNode(string(id), func(inputs []string) (string, error) {
+			return string(id) + ":" + strings.Join(inputs, " "), nil
+		}))
+		if err := workflow.AddEdge("root", id); err != nil {
+			t.Fatalf("failed to add edge: %v", err)
+		}
+	}
+
+	drainChannels(workflow)
+	result, err := workflow.Run(context.Background(), map[dag.NodeID][]string{"root": {"hi"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "leaf1:HI\nleaf2:HI\nleaf3:HI"
+	if got := result.JoinFinal("\n"); got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestDAGWithFinalAggregator(t *testing.T) {
+	// リーフノードの出力をID順に連結して1つの文字列にする
+	concat := func(finalOutputs map[dag.NodeID][]string) []string {
+		var ids []dag.NodeID
+		for id := range finalOutputs {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+		var b strings.Builder
+		for _, id := range ids {
+			b.WriteString(strings.Join(finalOutputs[id], ""))
+		}
+		return []string{b.String()}
+	}
+
+	tests := []struct {
+		name     string
+		opts     []dag.Option
+		expected []string
+	}{
+		{"Default flattens leaves in ID order", nil, []string{"a:HI", "b:HI"}},
+		{"Custom aggregator concatenates leaves", []dag.Option{dag.WithFinalAggregator(concat)}, []string{"a:HIb:HI"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := dag.NewDAG(2, tt.opts...)
+			workflow.AddNode("root", node.NewTextNode("root", func(inputs []string) (string, error) {
+				return strings.ToUpper(inputs[0]), nil
+			}))
+			for _, id := range []dag.NodeID{"b", "a"} {
+				workflow.AddNode(id, node.NewTextNode(string(id), func(inputs []string) (string, error) {
+					return string(id) + ":" + inputs[0], nil
+				}))
+				if err := workflow.AddEdge("root", id); err != nil {
+					t.Fatalf("failed to add edge: %v", err)
+				}
+			}
+
+			drainChannels(workflow)
+			aggregated, err := workflow.ExecuteAggregated(context.Background(), map[dag.NodeID][]string{"root": {"hi"}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(aggregated, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, aggregated)
+			}
+		})
+	}
+}
+
+func TestDAGExecuteAndReduce(t *testing.T) {
+	workflow := dag.NewDAG(3)
+	for _, id := range []dag.NodeID{"c", "a", "b"} {
+		workflow.AddNode(id, node.NewTextNode(string(id), func(inputs []string) (string, error) {
+			return string(id) + ":" + inputs[0], nil
+		}))
+	}
+	inputs := map[dag.NodeID][]string{"a": {"1"}, "b": {"2"}, "c": {"3"}}
+	concat := func(outputs []string) (string, error) {
+		return strings.Join(outputs, ","), nil
+	}
+
+	drainChannels(workflow)
+	reduced, err := workflow.ExecuteAndReduce(context.Background(), inputs, concat)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "a:1,b:2,c:3"; reduced != expected {
+		t.Fatalf("expected %q, got %q", expected, reduced)
+	}
+
+	errReduce := errors.New("reduce failed")
+	drainChannels(workflow)
+	_, err = workflow.ExecuteAndReduce(context.Background(), inputs, func([]string) (string, error) {
+		return "", errReduce
+	})
+	if !errors.Is(err, errReduce) {
+		t.Fatalf("expected %v, got %v", errReduce, err)
+	}
+}
@@ -0,0 +1,38 @@
+package dag
+
+import (
+	"context"
+	"time"
+
+	"github.com/momiom/workflow/node"
+)
+
+// WithRetryは失敗したノードを最大attempts回まで実行するよう設定します。
+// 再試行するのはnode.IsRetryableがtrueを返す一時的なエラーだけで、それ以外のエラーは
+// 1回目で失敗となります。再試行の前にはbackoffを起点に倍々に増える時間だけ待機します。
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(dag *DAG) {
+		dag.retryAttempts = attempts
+		dag.retryBackoff = backoff
+	}
+}
+
+// executeWithRetryはノードを実行し、一時的なエラーの場合は再試行の設定に従って再実行するメソッド
+func (dag *DAG) executeWithRetry(ctx context.Context, id NodeID, n node.Node) error {
+	attempts := max(dag.retryAttempts, 1)
+	backoff := dag.retryBackoff
+	for attempt := 1; ; attempt++ {
+		err := safeExecute(func() error { return dag.executeNode(ctx, id, n) })
+		if err == nil || attempt >= attempts || ctx.Err() != nil || !node.IsRetryable(err) {
+			return err
+		}
+
+		dag.nodeLogger(id).Debug("Retrying node", "attempt", attempt, "error", err, "backoff", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
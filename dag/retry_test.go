@@ -0,0 +1,58 @@
+package dag_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGWithRetry(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+	tests := []struct {
+		name             string
+		err              error
+		failures         int
+		expectedAttempts int
+		expectError      bool
+	}{
+		{"Temporary error is retried until success", node.Retryable(errUnavailable), 2, 3, false},
+		{"Temporary error gives up after max attempts", node.Retryable(errUnavailable), 5, 3, true},
+		{"Permanent error is not retried", errUnavailable, 2, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			workflow := dag.NewDAG(1, dag.WithRetry(3, time.Millisecond))
+			workflow.AddNode("flaky", node.NewTextNode("flaky", func(inputs []string) (string, error) {
+				attempts++
+				if attempts <= tt.failures {
+					return "", tt.err
+				}
+				return "ok", nil
+			}))
+			drainChannels(workflow)
+
+			_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"flaky": {"in"}})
+			if attempts != tt.expectedAttempts {
+				t.Fatalf("expected %d attempts, got %d", tt.expectedAttempts, attempts)
+			}
+			if tt.expectError {
+				if !errors.Is(err, errUnavailable) {
+					t.Fatalf("expected %v, got %v", errUnavailable, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := finalOutputs["flaky"]; len(got) != 1 || got[0] != "ok" {
+				t.Fatalf("expected [ok], got %v", got)
+			}
+		})
+	}
+}
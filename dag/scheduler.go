@@ -0,0 +1,107 @@
+package dag
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// readyItemは入力が揃い、実行枠の獲得を待っているノードです。
+type readyItem struct {
+	id       NodeID
+	priority int
+	seq      int       // 同じ優先度のノードを実行可能になった順に並べるための番号
+	readyAt  time.Time // 実行可能になった時刻
+	blocked  bool      // 実行枠に空きがなく見送られたことがあるか
+}
+
+// readyQueueは実行可能なノードを優先度の高い順に保持するキューです。
+// 同じ優先度のノードは実行可能になった順に並びます。
+type readyQueue struct {
+	items []*readyItem
+	seq   int
+}
+
+func compareReady(a, b *readyItem) int {
+	if c := cmp.Compare(b.priority, a.priority); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.seq, b.seq)
+}
+
+// pushはノードをキューに追加します。
+func (q *readyQueue) push(id NodeID, priority int) {
+	item := &readyItem{id: id, priority: priority, seq: q.seq, readyAt: time.Now()}
+	q.seq++
+	i, _ := slices.BinarySearchFunc(q.items, item, compareReady)
+	q.items = slices.Insert(q.items, i, item)
+}
+
+// popは実行枠を獲得できたノードのうち、最も優先度の高いものを取り出します。
+// 優先度の高いノードが所属するグループに空きがない場合でも、別の実行枠を使う後続のノードは取り出せます。
+// どのノードも実行枠を獲得できない場合はfalseを返します。
+func (q *readyQueue) pop(limiterFor func(NodeID) *limiter) (*readyItem, bool) {
+	for i, item := range q.items {
+		if limiterFor(item.id).tryAcquire() {
+			q.items = slices.Delete(q.items, i, i+1)
+			return item, true
+		}
+		item.blocked = true
+	}
+	return nil, false
+}
+
+func (q *readyQueue) len() int {
+	return len(q.items)
+}
+
+// UnschedulableErrorは入力次数とグラフの構造が一致せず、正しくスケジュールできないノードがあることを示すエラーです。
+// 依存元が全て終わっても入力次数が0にならないノードや、依存元が終わる前に実行可能になるノードが含まれます。
+type UnschedulableError struct {
+	Nodes []NodeID
+}
+
+func (e *UnschedulableError) Error() string {
+	ids := make([]string, len(e.Nodes))
+	for i, id := range e.Nodes {
+		ids[i] = string(id)
+	}
+	return fmt.Sprintf("nodes cannot be scheduled consistently: %s", strings.Join(ids, ", "))
+}
+
+// checkSchedulableはノードを起動する前に、入力次数に従ってスケジュールした場合に全てのノードが
+// 依存元の完了後にちょうど1回実行可能になるかを、トポロジカル順のsortedを使って確かめます。
+// そうならないノードがある場合は*UnschedulableErrorを返します。
+func (dag *DAG) checkSchedulable(sorted []graph.Node) error {
+	remaining := maps.Clone(dag.inDegree)
+	var invalid []NodeID
+	for _, n := range sorted {
+		id, ok := dag.nodeID(n.ID())
+		if !ok {
+			continue
+		}
+		// トポロジカル順では依存元が全て先に現れるため、この時点で入力次数は0になっているはず
+		if remaining[id] != 0 {
+			invalid = append(invalid, id)
+			if remaining[id] > 0 {
+				// 実行可能にならないノードの依存先も実行されない
+				continue
+			}
+		}
+		for _, to := range graph.NodesOf(dag.graph.From(n.ID())) {
+			toID, _ := dag.nodeID(to.ID())
+			if !dag.optionalEdges[edgeKey{from: id, to: toID}] {
+				remaining[toID]--
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		return &UnschedulableError{Nodes: invalid}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package dag
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+// 公開APIでは入力次数とグラフの構造を食い違わせることができないため、内部の状態を直接書き換えて検証する
+func TestRunDetectsInconsistentInDegree(t *testing.T) {
+	tests := []struct {
+		name     string
+		corrupt  func(dag *DAG)
+		expected []NodeID
+	}{
+		{"In-degree never reaches zero", func(dag *DAG) { dag.inDegree["b"]++ }, []NodeID{"b", "c"}},
+		{"Node would launch before its upstream", func(dag *DAG) { dag.inDegree["c"]-- }, []NodeID{"c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executions atomic.Int32
+			workflow := NewDAG(2)
+			for _, id := range []NodeID{"a", "b", "c"} {
+				workflow.AddNode(id, node.NewTextNode(string(id), func(inputs []string) (string, error) {
+					executions.Add(1)
+					return string(id), nil
+				}))
+			}
+			for _, edge := range [][2]NodeID{{"a", "b"}, {"b", "c"}} {
+				if err := workflow.AddEdge(edge[0], edge[1]); err != nil {
+					t.Fatalf("failed to add edge: %v", err)
+				}
+			}
+			tt.corrupt(workflow)
+
+			_, err := workflow.Run(context.Background(), map[NodeID][]string{"a": {"in"}})
+			var unschedulable *UnschedulableError
+			if !errors.As(err, &unschedulable) || !slices.Equal(unschedulable.Nodes, tt.expected) {
+				t.Fatalf("expected UnschedulableError for %v, got %v", tt.expected, err)
+			}
+			if n := executions.Load(); n != 0 {
+				t.Fatalf("expected no node to run, got %d executions", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package dag
+
+import "fmt"
+
+// OutputSelectionはエッジで依存元ノードの出力のうちどれを渡すかを表します。
+type OutputSelection string
+
+const (
+	SelectAll   OutputSelection = "all"   // 全ての出力を渡す（既定の動作）
+	SelectFirst OutputSelection = "first" // 最初の出力だけを渡す
+	SelectLast  OutputSelection = "last"  // 最後の出力だけを渡す
+)
+
+// エッジで渡す依存元ノードの出力を選択するメソッド
+// SelectFirstとSelectLastを指定したエッジには、fromの出力のうち最初または最後の1つだけが渡されます。
+// SetEdgeOutputでキーを指定したエッジではそのキーの出力から選択し、AddEdgeWithTransformの変換は選択した後の出力に適用されます。
+// fromが出力を持たない場合、このエッジからは何も渡されません。
+func (dag *DAG) SetEdgeSelection(from NodeID, to NodeID, selection OutputSelection) error {
+	dag.log().Debug("Setting edge selection", "from", from, "to", to, "selection", selection)
+
+	switch selection {
+	case SelectAll, SelectFirst, SelectLast:
+	default:
+		return fmt.Errorf("unknown output selection %q", selection)
+	}
+	fromNode, ok := dag.nodes[from]
+	if !ok {
+		return fmt.Errorf("node %s does not exist", from)
+	}
+	toNode, ok := dag.nodes[to]
+	if !ok {
+		return fmt.Errorf("node %s does not exist", to)
+	}
+	if !dag.graph.HasEdgeFromTo(fromNode.ID(), toNode.ID()) {
+		return fmt.Errorf("edge %s -> %s does not exist", from, to)
+	}
+
+	dag.edgeSelections[edgeKey{from: from, to: to}] = selection
+	return nil
+}
+
+// selectOutputsはselectionに従ってoutputから渡す出力を選択します。
+func selectOutputs(output []string, selection OutputSelection) []string {
+	if len(output) == 0 {
+		return output
+	}
+	switch selection {
+	case SelectFirst:
+		return output[:1]
+	case SelectLast:
+		return output[len(output)-1:]
+	default:
+		return output
+	}
+}
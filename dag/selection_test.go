@@ -0,0 +1,68 @@
+package dag_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGSetEdgeSelection(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection dag.OutputSelection
+		expected  []string
+	}{
+		{"Default delivers all outputs", "", []string{"attempt-1", "attempt-2", "attempt-3"}},
+		{"All", dag.SelectAll, []string{"attempt-1", "attempt-2", "attempt-3"}},
+		{"First", dag.SelectFirst, []string{"attempt-1"}},
+		{"Last", dag.SelectLast, []string{"attempt-3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := dag.NewDAG(1)
+			workflow.AddNode("history", node.NewMergeNode("history", func([]string) ([]string, error) {
+				return []string{"attempt-1", "attempt-2", "attempt-3"}, nil
+			}))
+			workflow.AddNode("sink", node.NewIdentityNode("sink"))
+			if err := workflow.AddEdge("history", "sink"); err != nil {
+				t.Fatalf("failed to add edge: %v", err)
+			}
+			if tt.selection != "" {
+				if err := workflow.SetEdgeSelection("history", "sink", tt.selection); err != nil {
+					t.Fatalf("failed to set edge selection: %v", err)
+				}
+			}
+
+			drainChannels(workflow)
+			_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"history": {"input"}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := finalOutputs["sink"]; !slices.Equal(got, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDAGSetEdgeSelectionErrors(t *testing.T) {
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("a", node.NewIdentityNode("a"))
+	workflow.AddNode("b", node.NewIdentityNode("b"))
+	if err := workflow.AddEdge("a", "b"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if err := workflow.SetEdgeSelection("a", "b", "middle"); err == nil {
+		t.Fatalf("expected error for unknown selection")
+	}
+	if err := workflow.SetEdgeSelection("b", "a", dag.SelectLast); err == nil {
+		t.Fatalf("expected error for missing edge")
+	}
+	if err := workflow.SetEdgeSelection("a", "missing", dag.SelectLast); err == nil {
+		t.Fatalf("expected error for missing node")
+	}
+}
@@ -0,0 +1,79 @@
+package dag
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// activeRunはShutdownから実行中の実行を制御するためのハンドルです。
+type activeRun struct {
+	done   chan struct{} // 実行が終了すると閉じられる
+	drain  func()        // 新しいノードの開始を止める
+	cancel func()        // 実行中のノードをキャンセルする
+	wake   func()        // 実行枠やキューの変化を待っているディスパッチャーを起こす
+}
+
+func (dag *DAG) setActiveRun(run *activeRun) {
+	dag.runMu.Lock()
+	defer dag.runMu.Unlock()
+	dag.activeRun = run
+}
+
+func (dag *DAG) clearActiveRun(run *activeRun) {
+	dag.runMu.Lock()
+	defer dag.runMu.Unlock()
+	if dag.activeRun == run {
+		dag.activeRun = nil
+	}
+	close(run.done)
+}
+
+// ShutdownErrorは猶予期間内に実行中のノードが終了しなかったことを示すエラーです。
+type ShutdownError struct {
+	Running []NodeID // 猶予期間の終了時に実行中だったノード
+	Err     error    // 猶予期間を終了させたコンテキストのエラー
+}
+
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("shutdown: nodes still running: %v: %v", e.Running, e.Err)
+}
+
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
+// 実行中の実行を正常に終了させるメソッド
+// 新しいノードの開始を止め、実行中のノードが終了するまでctxの期限まで待機します。
+// 実行はStatusがRunShutdownの結果を返し、開始されなかったノードはPendingまたはReadyのままとなります。
+// 期限までに終了しなかった場合は実行中のノードをキャンセルし、その時点で実行中だったノードを含む
+// *ShutdownErrorを返します。実行中でない場合は何もせずにnilを返します。
+func (dag *DAG) Shutdown(ctx context.Context) error {
+	dag.runMu.Lock()
+	run := dag.activeRun
+	dag.runMu.Unlock()
+	if run == nil {
+		return nil
+	}
+
+	dag.log().Debug("Shutting down DAG")
+	run.drain()
+	select {
+	case <-run.done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	var running []NodeID
+	dag.statusMu.Lock()
+	for id, status := range dag.nodeStatus {
+		if status == Running {
+			running = append(running, id)
+		}
+	}
+	dag.statusMu.Unlock()
+	slices.Sort(running)
+
+	run.cancel()
+	return &ShutdownError{Running: running, Err: ctx.Err()}
+}
@@ -0,0 +1,108 @@
+package dag_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/momiom/workflow/dag"
+)
+
+// startShutdownTestはfirst→secondのチェーンを実行し、firstの開始を待ってから結果を受け取るチャネルを返します。
+func startShutdownTest(t *testing.T, first *sleepNode) (*dag.DAG, <-chan *dag.ExecuteResult, <-chan error) {
+	t.Helper()
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("first", first)
+	workflow.AddNode("second", &sleepNode{name: "second"})
+	if err := workflow.AddEdge("first", "second"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	events := workflow.GetEvents()
+	started := make(chan struct{})
+	go func() {
+		for e := range events {
+			if e, ok := e.(dag.StatusEvent); ok && e.ID == "first" && e.Status == dag.Running {
+				close(started)
+			}
+		}
+	}()
+
+	results := make(chan *dag.ExecuteResult, 1)
+	errs := make(chan error, 1)
+	go func() {
+		result, err := workflow.Run(context.Background(), map[dag.NodeID][]string{"first": {"input"}})
+		results <- result
+		errs <- err
+	}()
+	<-started
+	return workflow, results, errs
+}
+
+func TestDAGShutdown(t *testing.T) {
+	first := &sleepNode{name: "first", duration: 100 * time.Millisecond}
+	workflow, results, errs := startShutdownTest(t, first)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := workflow.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+
+	result, err := <-results, <-errs
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != dag.RunShutdown {
+		t.Fatalf("expected %s, got %s", dag.RunShutdown, result.Status)
+	}
+	// 実行中だったノードは最後まで実行され、後続のノードは開始されない
+	if !first.completed.Load() {
+		t.Fatal("expected in-flight node to finish")
+	}
+	if _, exists := result.Outputs["second"]; exists {
+		t.Fatalf("expected second not to run, got %v", result.Outputs["second"])
+	}
+	if order := workflow.ExecutionOrder(); !slices.Equal(order, []dag.NodeID{"first"}) {
+		t.Fatalf("expected only first to start, got %v", order)
+	}
+}
+
+func TestDAGShutdownDeadline(t *testing.T) {
+	first := &sleepNode{name: "first", duration: time.Hour}
+	workflow, _, errs := startShutdownTest(t, first)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := workflow.Shutdown(ctx)
+
+	var shutdownErr *dag.ShutdownError
+	if !errors.As(err, &shutdownErr) {
+		t.Fatalf("expected *dag.ShutdownError, got %v", err)
+	}
+	if !slices.Equal(shutdownErr.Running, []dag.NodeID{"first"}) {
+		t.Fatalf("expected [first] still running, got %v", shutdownErr.Running)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// 期限を過ぎたノードはキャンセルされ、実行は終了する
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected %v, got %v", context.Canceled, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not finish after shutdown deadline")
+	}
+}
+
+func TestDAGShutdownIdle(t *testing.T) {
+	workflow := dag.NewDAG(1)
+	if err := workflow.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected nil for idle DAG, got %v", err)
+	}
+}
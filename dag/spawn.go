@@ -0,0 +1,135 @@
+package dag
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/momiom/workflow/node"
+)
+
+type spawnerKey struct{}
+
+// Spawnerは実行中のノードが自身の依存先として新しいノードを追加するためのハンドルです。
+// ノードのExecuteに渡されたコンテキストからSpawnerFromで取得します。
+// 追加を要求したノードとエッジは、要求したノードが成功した時点でDAGに追加され、その実行の中でスケジュールされます。
+// 追加したノードは実行の終了時にDAGから取り除かれるため、同じDAGを繰り返し実行しても毎回同じ構造から始まります。
+type Spawner struct {
+	parent NodeID
+	exists func(NodeID) bool // DAGに同じIDのノードが存在するか
+
+	mu    sync.Mutex
+	nodes []spawnedNode
+	edges []edgeKey
+}
+
+// spawnedNodeはSpawnerで追加を要求したノードです。
+type spawnedNode struct {
+	id NodeID
+	n  node.Node
+}
+
+// SpawnerFromは実行中のノードのSpawnerを返します。
+// DAGの外でノードを実行している場合など、コンテキストにSpawnerがない場合はfalseを返します。
+func SpawnerFrom(ctx context.Context) (*Spawner, bool) {
+	s, ok := ctx.Value(spawnerKey{}).(*Spawner)
+	return s, ok
+}
+
+// AddNodeは実行中のノードの依存先としてノードidを追加するよう要求します。
+// 追加したノードは実行中のノードの出力を入力として受け取ります。DAGに既に存在するIDは指定できません。
+// 再試行などで同じIDを再び指定した場合は、前に指定したノードを置き換えます。
+func (s *Spawner) AddNode(id NodeID, n node.Node) error {
+	if id == s.parent || s.exists(id) {
+		return fmt.Errorf("node %s already exists", id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, spawned := range s.nodes {
+		if spawned.id == id {
+			s.nodes[i].n = n
+			return nil
+		}
+	}
+	s.nodes = append(s.nodes, spawnedNode{id: id, n: n})
+	return nil
+}
+
+// AddEdgeは追加を要求したノードの間にエッジを追加するよう要求します。
+// toは追加を要求したノードで、fromは実行中のノードか、toより前に追加を要求したノードである必要があります。
+// エッジは常に先に追加したノードから後に追加したノードへ向かうため、循環することはありません。
+func (s *Spawner) AddEdge(from, to NodeID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	toIndex := s.indexOf(to)
+	if toIndex < 0 {
+		return fmt.Errorf("node %s was not added by %s", to, s.parent)
+	}
+	if from != s.parent {
+		fromIndex := s.indexOf(from)
+		if fromIndex < 0 {
+			return fmt.Errorf("node %s was not added by %s", from, s.parent)
+		}
+		if fromIndex >= toIndex {
+			return fmt.Errorf("edge %s -> %s would create a cycle: nodes can only depend on nodes added before them", from, to)
+		}
+	}
+	edge := edgeKey{from: from, to: to}
+	if !slices.Contains(s.edges, edge) {
+		s.edges = append(s.edges, edge)
+	}
+	return nil
+}
+
+// indexOfは追加を要求したノードidの位置を返します。要求していない場合は-1を返します。
+func (s *Spawner) indexOf(id NodeID) int {
+	return slices.IndexFunc(s.nodes, func(spawned spawnedNode) bool { return spawned.id == id })
+}
+
+// applySpawnedはSpawnerで要求されたノードとエッジをDAGに追加し、追加したノードのIDを返すメソッド
+// 実行中のノードから参照される構造を変更するため、呼び出し側で実行のロックを保持している必要があります。
+// inDegreeには追加したエッジの分の入力次数を加えます。
+func (dag *DAG) applySpawned(s *Spawner, inDegree map[NodeID]int) ([]NodeID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// 実行中にも描画などで構造が読み出されるため、構造の変更はstatusMuを保持して行う
+	dag.statusMu.Lock()
+	defer dag.statusMu.Unlock()
+
+	var added []NodeID
+	for _, spawned := range s.nodes {
+		if _, ok := dag.nodes[spawned.id]; ok {
+			return added, fmt.Errorf("node %s already exists", spawned.id)
+		}
+		dag.AddNode(spawned.id, spawned.n)
+		added = append(added, spawned.id)
+	}
+	edges := make([]edgeKey, 0, len(s.nodes)+len(s.edges))
+	for _, spawned := range s.nodes {
+		edges = append(edges, edgeKey{from: s.parent, to: spawned.id})
+	}
+	for _, edge := range s.edges {
+		if edge.from != s.parent {
+			edges = append(edges, edge)
+		}
+	}
+	for _, edge := range edges {
+		if err := dag.AddEdge(edge.from, edge.to); err != nil {
+			return added, err
+		}
+		inDegree[edge.to]++
+	}
+	return added, nil
+}
+
+// removeSpawnedは実行中に追加したノードを、追加した順と逆の順にDAGから取り除くメソッド
+func (dag *DAG) removeSpawned(ids []NodeID) {
+	dag.statusMu.Lock()
+	defer dag.statusMu.Unlock()
+	for i := len(ids) - 1; i >= 0; i-- {
+		if err := dag.RemoveNode(ids[i]); err != nil {
+			dag.log().Warn("Failed to remove spawned node", "node", ids[i], "error", err)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package dag_test
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+// ctxNodeはコンテキストを受け取る関数を実行するノードです。
+type ctxNode struct {
+	fn      func(ctx context.Context, inputs []string) ([]string, error)
+	inputs  []string
+	outputs []string
+}
+
+func (n *ctxNode) Execute(ctx context.Context) error {
+	outputs, err := n.fn(ctx, n.inputs)
+	if err != nil {
+		return err
+	}
+	n.outputs = outputs
+	return nil
+}
+
+func (n *ctxNode) Name() string              { return "ctx" }
+func (n *ctxNode) SetInputs(inputs []string) { n.inputs = inputs }
+func (n *ctxNode) GetOutputs() []string      { return n.outputs }
+func (n *ctxNode) Reset()                    { n.inputs, n.outputs = nil, nil }
+
+// newPlannerは入力の単語ごとにタスクのノードを追加し、タスクの出力をまとめるノードを追加するノードを返します。
+func newPlanner() node.Node {
+	return &ctxNode{fn: func(ctx context.Context, inputs []string) ([]string, error) {
+		spawner, ok := dag.SpawnerFrom(ctx)
+		if !ok {
+			return nil, errors.New("no spawner")
+		}
+		words := strings.Fields(inputs[0])
+		if err := spawner.AddNode("join", node.NewTextNode("join", func(inputs []string) (string, error) {
+			return strings.Join(inputs, "+"), nil
+		})); err != nil {
+			return nil, err
+		}
+		for _, word := range words {
+			id := dag.NodeID("task-" + word)
+			if err := spawner.AddNode(id, node.NewTextNode(string(id), func(inputs []string) (string, error) {
+				return strings.ToUpper(word), nil
+			})); err != nil {
+				return nil, err
+			}
+			// joinはタスクより先に追加したため、タスクからjoinへのエッジは循環のおそれがあるとして拒否される
+			if err := spawner.AddEdge(id, "join"); err == nil {
+				return nil, errors.New("expected an error for an edge to an earlier node")
+			}
+		}
+		// 後に追加したノードは、先に追加したタスクの出力を受け取れる
+		if err := spawner.AddNode("summary", node.NewTextNode("summary", func(inputs []string) (string, error) {
+			return strings.Join(inputs, " "), nil
+		})); err != nil {
+			return nil, err
+		}
+		for _, word := range words {
+			if err := spawner.AddEdge(dag.NodeID("task-"+word), "summary"); err != nil {
+				return nil, err
+			}
+		}
+		return words, nil
+	}}
+}
+
+func TestDAGSpawner(t *testing.T) {
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("planner", newPlanner())
+
+	for i := range 2 {
+		drainChannels(workflow)
+		result, err := workflow.Run(context.Background(), map[dag.NodeID][]string{"planner": {"a b"}})
+		if err != nil {
+			t.Fatalf("run %d: unexpected error: %v", i, err)
+		}
+		expected := map[dag.NodeID][]string{
+			"join":    {"a+b"},
+			"summary": {"a b A B"},
+		}
+		if !maps.EqualFunc(result.FinalOutputs, expected, slices.Equal) {
+			t.Fatalf("run %d: expected final outputs %v, got %v", i, expected, result.FinalOutputs)
+		}
+		// 追加したノードは実行の終了時に取り除かれる
+		if _, ok := workflow.GetNode("join"); ok {
+			t.Fatalf("run %d: expected spawned nodes to be removed after the run", i)
+		}
+		if edges := workflow.Edges(); len(edges) != 0 {
+			t.Fatalf("run %d: expected no edges after the run, got %v", i, edges)
+		}
+	}
+}
+
+func TestDAGSpawnerWithoutSubscribers(t *testing.T) {
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("planner", newPlanner())
+
+	// チャネルを購読しない場合も、追加したノードの通知で実行が止まらない
+	type runResult struct {
+		result *dag.ExecuteResult
+		err    error
+	}
+	done := make(chan runResult, 1)
+	go func() {
+		result, err := workflow.Run(context.Background(), map[dag.NodeID][]string{"planner": {"a b c"}})
+		done <- runResult{result, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			t.Fatalf("unexpected error: %v", r.err)
+		}
+		if got, expected := r.result.FinalOutputs["summary"], []string{"a b c A B C"}; !slices.Equal(got, expected) {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("run did not finish without subscribers")
+	}
+}
+
+func TestDAGSpawnerRenderDuringRun(t *testing.T) {
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("planner", newPlanner())
+
+	// 実行中にノードが追加、削除される間も描画できる
+	stop := make(chan struct{})
+	rendered := make(chan struct{})
+	go func() {
+		defer close(rendered)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				workflow.ToMermaid()
+				workflow.ToDOT()
+			}
+		}
+	}()
+
+	for i := range 5 {
+		drainChannels(workflow)
+		if _, err := workflow.Run(context.Background(), map[dag.NodeID][]string{"planner": {"a b c"}}); err != nil {
+			t.Fatalf("run %d: unexpected error: %v", i, err)
+		}
+	}
+	close(stop)
+	<-rendered
+}
+
+func TestDAGSpawnerSubscribeDuringRun(t *testing.T) {
+	started := make(chan struct{})
+	subscribed := make(chan struct{})
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("planner", &ctxNode{fn: func(ctx context.Context, inputs []string) ([]string, error) {
+		spawner, _ := dag.SpawnerFrom(ctx)
+		// 追加したノードは、ノードが増えた後に購読されるのを待つ
+		gate := &ctxNode{fn: func(ctx context.Context, inputs []string) ([]string, error) {
+			close(started)
+			<-subscribed
+			return inputs, nil
+		}}
+		if err := spawner.AddNode("gate", gate); err != nil {
+			return nil, err
+		}
+		if err := spawner.AddNode("after", node.NewIdentityNode("after")); err != nil {
+			return nil, err
+		}
+		return inputs, spawner.AddEdge("gate", "after")
+	}})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := workflow.Run(context.Background(), map[dag.NodeID][]string{"planner": {"a"}})
+		done <- err
+	}()
+
+	<-started
+	statusChan, ioChan := workflow.GetStatusChan(), workflow.GetIOChan()
+	close(subscribed)
+	go func() {
+		for range ioChan {
+		}
+	}()
+	statuses := make(map[dag.NodeID][]dag.NodeStatus)
+	for state := range statusChan {
+		statuses[state.ID] = append(statuses[state.ID], state.Status)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 実行中に購読してもチャネルは作り直されず、購読前にバッファに溜まった状態変更も受け取れる
+	if expected := []dag.NodeStatus{dag.Ready, dag.Running, dag.Completed}; !slices.Equal(statuses["planner"], expected) {
+		t.Fatalf("expected planner statuses %v, got %v", expected, statuses["planner"])
+	}
+	if got := statuses["after"]; len(got) == 0 || got[len(got)-1] != dag.Completed {
+		t.Fatalf("expected spawned after node to complete, got %v", got)
+	}
+}
+
+func TestDAGSpawnerRejectsExistingNode(t *testing.T) {
+	workflow := dag.NewDAG(1)
+	workflow.AddNode("spawn", &ctxNode{fn: func(ctx context.Context, inputs []string) ([]string, error) {
+		spawner, _ := dag.SpawnerFrom(ctx)
+		return inputs, spawner.AddNode("other", node.NewIdentityNode("other"))
+	}})
+	workflow.AddNode("other", node.NewIdentityNode("other"))
+
+	drainChannels(workflow)
+	if _, err := workflow.Run(context.Background(), map[dag.NodeID][]string{"spawn": {"a"}, "other": {"b"}}); err == nil {
+		t.Fatal("expected an error when spawning an existing node")
+	}
+	if _, ok := dag.SpawnerFrom(context.Background()); ok {
+		t.Fatal("expected no spawner outside of a run")
+	}
+}
@@ -0,0 +1,89 @@
+package dag
+
+import "sync"
+
+// Storeは1回の実行の間、ノードの出力を保持するストアです。
+// 出力が大きい場合に、メモリの代わりにディスクや外部のキーバリューストアに保持するために使用します。
+// 複数のノードから並行して呼び出される場合があるため、実装は並行に安全である必要があります。
+type Store interface {
+	// Putはノードidの出力を保存します。
+	Put(id NodeID, outputs []string)
+	// Getはノードidの出力を返します。保存されていない場合はfalseを返します。
+	Get(id NodeID) ([]string, bool)
+}
+
+// WithStoreはノードの出力を保持するストアを設定します。
+// newStoreは実行ごとに呼ばれ、前回の実行の出力を持たない新しいストアを返す必要があります。
+// 設定しない場合はNewMemoryStoreで作成したストアを使用します。
+// ExecuteResultのOutputsなど実行結果として返す出力は、実行の終了時にストアから読み出したものです。
+func WithStore(newStore func() Store) Option {
+	return func(dag *DAG) {
+		dag.storeFactory = newStore
+	}
+}
+
+// newStoreは実行に使用する新しいストアを返すメソッド
+func (dag *DAG) newStore() Store {
+	if dag.storeFactory == nil {
+		return NewMemoryStore()
+	}
+	return dag.storeFactory()
+}
+
+// MemoryStoreはノードの出力をメモリ上に保持するストアです。
+type MemoryStore struct {
+	mu      sync.Mutex
+	outputs map[NodeID][]string
+}
+
+// NewMemoryStoreは新しいMemoryStoreを作成します。
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{outputs: make(map[NodeID][]string)}
+}
+
+// Putはノードidの出力を保存します。
+func (s *MemoryStore) Put(id NodeID, outputs []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outputs[id] = outputs
+}
+
+// Getはノードidの出力を返します。
+func (s *MemoryStore) Get(id NodeID) ([]string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	outputs, ok := s.outputs[id]
+	return outputs, ok
+}
+
+// runOutputsは1回の実行でストアに保存したノードの出力を、保存したノードのIDとともに管理します。
+type runOutputs struct {
+	store Store
+	ids   []NodeID // 出力を保存したノード（保存した順）
+}
+
+func newRunOutputs(store Store) *runOutputs {
+	return &runOutputs{store: store}
+}
+
+// putはノードidの出力をストアに保存します。
+func (o *runOutputs) put(id NodeID, outputs []string) {
+	o.store.Put(id, outputs)
+	o.ids = append(o.ids, id)
+}
+
+// getはノードidの出力をストアから読み出します。
+func (o *runOutputs) get(id NodeID) ([]string, bool) {
+	return o.store.Get(id)
+}
+
+// snapshotは出力を保存した全てのノードの出力をストアから読み出したマップを返します。
+func (o *runOutputs) snapshot() map[NodeID][]string {
+	outputs := make(map[NodeID][]string, len(o.ids))
+	for _, id := range o.ids {
+		if output, ok := o.store.Get(id); ok {
+			outputs[id] = output
+		}
+	}
+	return outputs
+}
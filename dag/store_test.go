@@ -0,0 +1,95 @@
+package dag_test
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+// recordingStoreは読み書きしたノードを記録するメモリ上のストアです。
+type recordingStore struct {
+	mu      sync.Mutex
+	outputs map[dag.NodeID][]string
+	puts    []dag.NodeID
+	gets    []dag.NodeID
+}
+
+func (s *recordingStore) Put(id dag.NodeID, outputs []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outputs[id] = outputs
+	s.puts = append(s.puts, id)
+}
+
+func (s *recordingStore) Get(id dag.NodeID) ([]string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gets = append(s.gets, id)
+	outputs, ok := s.outputs[id]
+	return outputs, ok
+}
+
+func TestDAGWithStore(t *testing.T) {
+	var stores []*recordingStore
+	newStore := func() dag.Store {
+		s := &recordingStore{outputs: make(map[dag.NodeID][]string)}
+		stores = append(stores, s)
+		return s
+	}
+
+	workflow := dag.NewDAG(1, dag.WithStore(newStore))
+	workflow.AddNode("upper", node.NewTextNode("upper", func(inputs []string) (string, error) {
+		return strings.ToUpper(strings.Join(inputs, " ")), nil
+	}))
+	workflow.AddNode("exclaim", node.NewTextNode("exclaim", func(inputs []string) (string, error) {
+		return strings.Join(inputs, " ") + "!", nil
+	}))
+	if err := workflow.AddEdge("upper", "exclaim"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	for i, input := range []string{"hello", "bye"} {
+		drainChannels(workflow)
+		outputs, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"upper": {input}})
+		if err != nil {
+			t.Fatalf("run %d: unexpected error: %v", i, err)
+		}
+		if len(stores) != i+1 {
+			t.Fatalf("run %d: expected a new store per run, got %d stores", i, len(stores))
+		}
+
+		// 実行結果はストアに書き込まれた出力から作られる
+		store := stores[i]
+		if !maps.EqualFunc(outputs, store.outputs, slices.Equal) {
+			t.Fatalf("run %d: expected outputs %v from store, got %v", i, store.outputs, outputs)
+		}
+		expected := []string{strings.ToUpper(input) + "!"}
+		if !slices.Equal(finalOutputs["exclaim"], expected) {
+			t.Fatalf("run %d: expected %v, got %v", i, expected, finalOutputs["exclaim"])
+		}
+		if !slices.Equal(store.puts, []dag.NodeID{"upper", "exclaim"}) {
+			t.Fatalf("run %d: unexpected writes %v", i, store.puts)
+		}
+		// 依存先ノードの入力はストアから読み出される
+		if !slices.Contains(store.gets, "upper") {
+			t.Fatalf("run %d: expected the input of exclaim to be read from the store, got %v", i, store.gets)
+		}
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := dag.NewMemoryStore()
+	if _, ok := store.Get("missing"); ok {
+		t.Fatal("expected no outputs for a missing node")
+	}
+	store.Put("a", []string{"x", "y"})
+	if got, ok := store.Get("a"); !ok || !slices.Equal(got, []string{"x", "y"}) {
+		t.Fatalf("expected stored outputs, got %v (%v)", got, ok)
+	}
+}
@@ -0,0 +1,153 @@
+package dag
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/momiom/workflow/node"
+)
+
+// SubDAGNodeは入力ごとにネストしたワークフローを実行し、その結果をまとめるノードです。
+// ワークフローはbuildで実行ごとに新しく作成するため、複数の実行を並行させることができます。
+// ワークフローのルートノードには、入力ごとの実行ではその入力が、一括の実行では全ての入力が与えられます。
+// 出力は各実行のリーフノードの出力をNodeIDの昇順に並べたもの（ワークフローにWithFinalAggregatorが
+// 設定されている場合はその結果）を、入力の順に連結したものです。
+type SubDAGNode struct {
+	name        string
+	inputs      []string
+	outputs     []string
+	build       func() (*DAG, error)
+	parallelism int  // 同時に実行するワークフローの数
+	once        bool // 全ての入力で1回だけ実行するかどうか
+}
+
+// NewSubDAGNodeは新しいSubDAGNodeを作成します。
+// デフォルトでは入力ごとにワークフローを1つずつ順に実行します。
+func NewSubDAGNode(name string, build func() (*DAG, error)) *SubDAGNode {
+	return &SubDAGNode{name: name, build: build, parallelism: 1}
+}
+
+// SetParallelismは同時に実行するワークフローの数の上限を設定します。1未満の値は1として扱います。
+func (n *SubDAGNode) SetParallelism(parallelism int) {
+	n.parallelism = max(parallelism, 1)
+}
+
+// SetRunOnceは入力ごとではなく、全ての入力で1回だけワークフローを実行するかどうかを設定します。
+func (n *SubDAGNode) SetRunOnce(once bool) {
+	n.once = once
+}
+
+// Executeはワークフローを実行します。
+// いずれかの実行が失敗した場合は実行中のワークフローをキャンセルし、最初のエラーを返します。
+func (n *SubDAGNode) Execute(ctx context.Context) error {
+	batches := make([][]string, 0, len(n.inputs))
+	if n.once {
+		batches = append(batches, n.inputs)
+	} else {
+		for _, input := range n.inputs {
+			batches = append(batches, []string{input})
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]string, len(batches))
+	var mu sync.Mutex
+	var execErr error
+	sem := make(chan struct{}, max(n.parallelism, 1))
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			outputs, err := n.run(ctx, batch)
+			if err != nil {
+				mu.Lock()
+				if execErr == nil {
+					execErr = fmt.Errorf("sub DAG run %d: %w", i, err)
+				}
+				mu.Unlock()
+				cancel()
+				return
+			}
+			results[i] = outputs
+		}()
+	}
+	wg.Wait()
+
+	if execErr != nil {
+		return execErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var outputs []string
+	for _, result := range results {
+		outputs = append(outputs, result...)
+	}
+	n.outputs = outputs
+	return nil
+}
+
+// runはワークフローを作成し、ルートノードにinputsを与えて実行します。
+func (n *SubDAGNode) run(ctx context.Context, inputs []string) ([]string, error) {
+	sub, err := n.build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build sub DAG: %w", err)
+	}
+
+	rootInputs := make(map[NodeID][]string)
+	for _, id := range sub.GetRootNodes() {
+		rootInputs[id] = inputs
+	}
+
+	// イベントを購読して読み捨てることで、読み手のいない状態と入出力のチャネルで実行が止まらないようにする
+	events := sub.GetEvents()
+	go func() {
+		for range events {
+		}
+	}()
+
+	result, err := sub.Run(ctx, rootInputs)
+	if err != nil {
+		return nil, err
+	}
+	return result.Aggregated, nil
+}
+
+// Cloneは同じ設定の新しいSubDAGNodeを返します。
+func (n *SubDAGNode) Clone() node.Node {
+	return &SubDAGNode{name: n.name, build: n.build, parallelism: n.parallelism, once: n.once}
+}
+
+// Nameはノードの名前を返します。
+func (n *SubDAGNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *SubDAGNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *SubDAGNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力を消去します。
+func (n *SubDAGNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+}
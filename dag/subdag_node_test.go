@@ -0,0 +1,119 @@
+package dag_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+// buildShoutは入力を大文字にしてから感嘆符を付ける2ノードのワークフローを作成します。
+func buildShout() (*dag.DAG, error) {
+	sub := dag.NewDAG(2)
+	sub.AddNode("upper", node.NewTextNode("upper", func(inputs []string) (string, error) {
+		if slices.Contains(inputs, "fail") {
+			return "", errors.New("cannot shout fail")
+		}
+		return strings.ToUpper(strings.Join(inputs, " ")), nil
+	}))
+	sub.AddNode("exclaim", node.NewTextNode("exclaim", func(inputs []string) (string, error) {
+		return inputs[0] + "!", nil
+	}))
+	if err := sub.AddEdge("upper", "exclaim"); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func TestSubDAGNode(t *testing.T) {
+	tests := []struct {
+		name            string
+		inputs          []string
+		parallelism     int
+		once            bool
+		expectedOutputs []string
+		expectError     bool
+	}{
+		{"Run per input sequentially", []string{"a", "b", "c"}, 1, false, []string{"A!", "B!", "C!"}, false},
+		{"Run per input in parallel", []string{"a", "b", "c"}, 3, false, []string{"A!", "B!", "C!"}, false},
+		{"Run once with all inputs", []string{"a", "b", "c"}, 1, true, []string{"A B C!"}, false},
+		{"Failed sub run fails the node", []string{"a", "fail", "c"}, 3, false, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := dag.NewSubDAGNode("shout", buildShout)
+			n.SetParallelism(tt.parallelism)
+			n.SetRunOnce(tt.once)
+			n.SetInputs(tt.inputs)
+
+			err := n.Execute(context.Background())
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if outputs := n.GetOutputs(); !slices.Equal(outputs, tt.expectedOutputs) {
+				t.Fatalf("expected %v, got %v", tt.expectedOutputs, outputs)
+			}
+		})
+	}
+}
+
+func TestSubDAGNodeInWorkflow(t *testing.T) {
+	workflow := dag.NewDAG(2)
+	workflow.AddNode("split", node.NewMergeNode("split", func(inputs []string) ([]string, error) {
+		return strings.Fields(inputs[0]), nil
+	}))
+	workflow.AddNode("shout", dag.NewSubDAGNode("shout", buildShout))
+	if err := workflow.AddEdge("split", "shout"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+
+	drainChannels(workflow)
+	_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"split": {"x y z"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"X!", "Y!", "Z!"}
+	if !slices.Equal(finalOutputs["shout"], expected) {
+		t.Fatalf("expected %v, got %v", expected, finalOutputs["shout"])
+	}
+}
+
+func TestSubDAGNodeCancellation(t *testing.T) {
+	slow := &sleepNode{name: "slow", duration: time.Hour}
+	n := dag.NewSubDAGNode("nested", func() (*dag.DAG, error) {
+		sub := dag.NewDAG(1)
+		sub.AddNode("slow", slow)
+		return sub, nil
+	})
+	n.SetInputs([]string{"a"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- n.Execute(ctx) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("sub DAG did not stop after the parent context was cancelled")
+	}
+	if slow.completed.Load() {
+		t.Fatal("expected nested node to be cancelled")
+	}
+}
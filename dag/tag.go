@@ -0,0 +1,35 @@
+package dag
+
+import "strings"
+
+// InputTagSeparatorはWithTaggedInputsで依存元のIDと出力を区切る文字列です。
+const InputTagSeparator = ":"
+
+// WithTaggedInputsはノードidが依存元ノードの出力を "依存元のID:出力" の形で受け取るよう設定します。
+// 複数の依存元から入力を受け取るマージ用のノードが、入力の出所を区別するために使用します。
+// 外部から与えられた入力には前置されません。前置した入力はSplitTaggedInputで分解できます。
+func WithTaggedInputs(id NodeID) Option {
+	return func(dag *DAG) {
+		dag.taggedInputs[id] = true
+	}
+}
+
+// SplitTaggedInputはWithTaggedInputsで前置された入力を依存元のIDと出力に分解します。
+// 依存元のIDはInputTagSeparatorを含まないものとして、最初の区切りで分解します。
+// 区切りを含まない場合はfalseを返します。
+func SplitTaggedInput(input string) (NodeID, string, bool) {
+	from, output, ok := strings.Cut(input, InputTagSeparator)
+	if !ok {
+		return "", input, false
+	}
+	return NodeID(from), output, true
+}
+
+// tagInputsはoutputsの各要素にfromを前置した新しいスライスを返します。
+func tagInputs(from NodeID, outputs []string) []string {
+	tagged := make([]string, len(outputs))
+	for i, output := range outputs {
+		tagged[i] = string(from) + InputTagSeparator + output
+	}
+	return tagged
+}
@@ -0,0 +1,71 @@
+package dag_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/node"
+)
+
+func TestDAGWithTaggedInputs(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []dag.Option
+		expected []string
+	}{
+		{"Untagged delivery", nil, []string{"const", "a1", "a2", "b1"}},
+		{"Tagged delivery", []dag.Option{dag.WithTaggedInputs("merge")}, []string{"const", "llm:a1", "llm:a2", "text:b1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow := dag.NewDAG(2, tt.opts...)
+			workflow.AddNode("llm", node.NewMergeNode("llm", func([]string) ([]string, error) {
+				return []string{"a1", "a2"}, nil
+			}))
+			workflow.AddNode("text", node.NewMergeNode("text", func([]string) ([]string, error) {
+				return []string{"b1"}, nil
+			}))
+			workflow.AddNode("merge", node.NewIdentityNode("merge"))
+			for _, from := range []dag.NodeID{"llm", "text"} {
+				if err := workflow.AddEdge(from, "merge"); err != nil {
+					t.Fatalf("failed to add edge: %v", err)
+				}
+			}
+
+			drainChannels(workflow)
+			_, finalOutputs, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"merge": {"const"}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(finalOutputs["merge"], tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, finalOutputs["merge"])
+			}
+		})
+	}
+}
+
+func TestSplitTaggedInput(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedFrom   dag.NodeID
+		expectedOutput string
+		expectedOK     bool
+	}{
+		{"Tagged input", "llm:response text", "llm", "response text", true},
+		{"Output containing the separator", "llm:a:b", "llm", "a:b", true},
+		{"Untagged input", "untagged", "", "untagged", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, output, ok := dag.SplitTaggedInput(tt.input)
+			if from != tt.expectedFrom || output != tt.expectedOutput || ok != tt.expectedOK {
+				t.Fatalf("expected (%q, %q, %v), got (%q, %q, %v)", tt.expectedFrom, tt.expectedOutput, tt.expectedOK, from, output, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package grpcserver
+
+import (
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/grpcserver/workflowpb"
+	"github.com/momiom/workflow/registry"
+)
+
+// definitionFromProtoはprotoのワークフローの定義をregistry.Definitionに変換します。
+// パラメーターのリストは[]any、数値はfloat64として渡されます。
+func definitionFromProto(def *workflowpb.Definition) *registry.Definition {
+	out := &registry.Definition{MaxConcurrent: int(def.GetMaxConcurrent())}
+	for _, nd := range def.GetNodes() {
+		var params registry.Params
+		if nd.GetParams() != nil {
+			params = nd.GetParams().AsMap()
+		}
+		out.Nodes = append(out.Nodes, registry.NodeDefinition{ID: nd.GetId(), Type: nd.GetType(), Params: params})
+	}
+	for _, edge := range def.GetEdges() {
+		out.Edges = append(out.Edges, registry.EdgeDefinition{From: edge.GetFrom(), To: edge.GetTo()})
+	}
+	return out
+}
+
+// inputsFromProtoはprotoの外部入力をDAGの実行に渡す形式に変換します。
+func inputsFromProto(inputs map[string]*workflowpb.Strings) map[dag.NodeID][]string {
+	out := make(map[dag.NodeID][]string, len(inputs))
+	for id, values := range inputs {
+		out[dag.NodeID(id)] = values.GetValues()
+	}
+	return out
+}
+
+// stringsToProtoはノードごとの出力をprotoの形式に変換します。
+func stringsToProto(outputs map[dag.NodeID][]string) map[string]*workflowpb.Strings {
+	out := make(map[string]*workflowpb.Strings, len(outputs))
+	for id, values := range outputs {
+		out[string(id)] = &workflowpb.Strings{Values: values}
+	}
+	return out
+}
+
+// eventToProtoはDAGのイベントをストリームで送るメッセージに変換します。
+func eventToProto(e dag.Event) *workflowpb.RunWorkflowResponse {
+	switch e := e.(type) {
+	case dag.StatusEvent:
+		return &workflowpb.RunWorkflowResponse{
+			Event: &workflowpb.RunWorkflowResponse_State{State: &workflowpb.NodeState{Id: string(e.ID), Status: string(e.Status)}},
+		}
+	case dag.IOEvent:
+		return &workflowpb.RunWorkflowResponse{
+			Event: &workflowpb.RunWorkflowResponse_Io{Io: &workflowpb.NodeIO{Id: string(e.ID), Inputs: e.Inputs, Outputs: e.Outputs}},
+		}
+	default:
+		return &workflowpb.RunWorkflowResponse{}
+	}
+}
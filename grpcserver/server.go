@@ -0,0 +1,82 @@
+// gRPCでワークフローを実行するサービスを提供します。
+//
+// ワークフローはregistryのノードの種類で定義し、RunWorkflowで実行します。
+// 実行中はノードの状態変更と入出力がストリームで送られ、最後のメッセージで実行結果が返されます。
+// メッセージの定義はworkflowpb/workflow.protoにあります。
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/grpcserver/workflowpb"
+	"github.com/momiom/workflow/registry"
+)
+
+// ServerはWorkflowServiceの実装です。
+type Server struct {
+	workflowpb.UnimplementedWorkflowServiceServer
+
+	registry *registry.Registry
+	opts     []dag.Option
+}
+
+// NewはrのノードでワークフローをビルドするServerを作成します。
+// optsはリクエストごとに作成するDAGの設定に使用されます。
+func New(r *registry.Registry, opts ...dag.Option) *Server {
+	return &Server{registry: r, opts: opts}
+}
+
+// RunWorkflowはリクエストの定義からワークフローをビルドし、入力を与えて実行します。
+// 定義が不正な場合はInvalidArgument、実行が失敗した場合はコンテキストのエラーに応じた
+// CanceledやDeadlineExceeded、それ以外はUnknownのステータスを返します。
+func (s *Server) RunWorkflow(req *workflowpb.RunWorkflowRequest, stream workflowpb.WorkflowService_RunWorkflowServer) error {
+	def := definitionFromProto(req.GetDefinition())
+	workflow, err := s.registry.Build(def, s.opts...)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to build workflow: %v", err)
+	}
+
+	// 送信に失敗してもDAGの実行が止まらないよう、イベントは最後まで読む
+	events := workflow.GetEvents()
+	sent := make(chan error, 1)
+	go func() {
+		var sendErr error
+		for e := range events {
+			if sendErr == nil {
+				sendErr = stream.Send(eventToProto(e))
+			}
+		}
+		sent <- sendErr
+	}()
+
+	result, err := workflow.Run(stream.Context(), inputsFromProto(req.GetInputs()))
+	if sendErr := <-sent; sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		return runError(err)
+	}
+	return stream.Send(&workflowpb.RunWorkflowResponse{
+		Event: &workflowpb.RunWorkflowResponse_Result{Result: &workflowpb.RunResult{
+			Status:       string(result.Status),
+			FinalOutputs: stringsToProto(result.FinalOutputs),
+		}},
+	})
+}
+
+// runErrorは実行のエラーをgRPCのステータスに変換します。
+func runError(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Errorf(codes.Canceled, "workflow cancelled: %v", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Errorf(codes.DeadlineExceeded, "workflow deadline exceeded: %v", err)
+	default:
+		return status.Errorf(codes.Unknown, "failed to execute workflow: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package grpcserver_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/momiom/workflow/grpcserver"
+	"github.com/momiom/workflow/grpcserver/workflowpb"
+	"github.com/momiom/workflow/registry"
+)
+
+// newClientはプロセス内で起動したサーバーに接続するクライアントを返します。
+func newClient(t *testing.T) workflowpb.WorkflowServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	workflowpb.RegisterWorkflowServiceServer(server, grpcserver.New(registry.Default()))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return workflowpb.NewWorkflowServiceClient(conn)
+}
+
+func TestRunWorkflow(t *testing.T) {
+	client := newClient(t)
+
+	params, err := structpb.NewStruct(map[string]any{"sep": "+"})
+	if err != nil {
+		t.Fatalf("failed to build params: %v", err)
+	}
+	req := &workflowpb.RunWorkflowRequest{
+		Definition: &workflowpb.Definition{
+			Nodes: []*workflowpb.NodeDefinition{
+				{Id: "a", Type: "identity"},
+				{Id: "b", Type: "identity"},
+				{Id: "joined", Type: "join", Params: params},
+			},
+			Edges: []*workflowpb.EdgeDefinition{{From: "a", To: "joined"}, {From: "b", To: "joined"}},
+		},
+		Inputs: map[string]*workflowpb.Strings{
+			"a": {Values: []string{"hello"}},
+			"b": {Values: []string{"world"}},
+		},
+	}
+
+	stream, err := client.RunWorkflow(context.Background(), req)
+	if err != nil {
+		t.Fatalf("failed to start RunWorkflow: %v", err)
+	}
+
+	var statuses []string
+	var ios []*workflowpb.NodeIO
+	var result *workflowpb.RunResult
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		switch e := resp.GetEvent().(type) {
+		case *workflowpb.RunWorkflowResponse_State:
+			statuses = append(statuses, e.State.GetId()+":"+e.State.GetStatus())
+		case *workflowpb.RunWorkflowResponse_Io:
+			ios = append(ios, e.Io)
+		case *workflowpb.RunWorkflowResponse_Result:
+			result = e.Result
+		}
+	}
+
+	for _, want := range []string{"a:Running", "a:Completed", "b:Completed", "joined:Running", "joined:Completed"} {
+		if !slices.Contains(statuses, want) {
+			t.Fatalf("expected %s in streamed states, got %v", want, statuses)
+		}
+	}
+	if len(ios) != 3 {
+		t.Fatalf("expected 3 IO events, got %d", len(ios))
+	}
+	if last := ios[len(ios)-1]; last.GetId() != "joined" || !slices.Equal(last.GetInputs(), []string{"hello", "world"}) {
+		t.Fatalf("expected joined to receive [hello world], got %v", last)
+	}
+	if result == nil || result.GetStatus() != "Completed" {
+		t.Fatalf("expected a Completed result, got %v", result)
+	}
+	if got := result.GetFinalOutputs()["joined"].GetValues(); !slices.Equal(got, []string{"hello+world"}) {
+		t.Fatalf("expected [hello+world], got %v", got)
+	}
+}
+
+func TestRunWorkflowInvalidDefinition(t *testing.T) {
+	client := newClient(t)
+
+	stream, err := client.RunWorkflow(context.Background(), &workflowpb.RunWorkflowRequest{
+		Definition: &workflowpb.Definition{Nodes: []*workflowpb.NodeDefinition{{Id: "a", Type: "unknown"}}},
+	})
+	if err != nil {
+		t.Fatalf("failed to start RunWorkflow: %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,4 @@
+// WorkflowServiceのprotoメッセージとgRPCのコードです。workflow.protoから生成しています。
+package workflowpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative workflow.proto
@@ -0,0 +1,137 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: workflow.proto
+
+package workflowpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	WorkflowService_RunWorkflow_FullMethodName = "/workflow.v1.WorkflowService/RunWorkflow"
+)
+
+// WorkflowServiceClient is the client API for WorkflowService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type WorkflowServiceClient interface {
+	RunWorkflow(ctx context.Context, in *RunWorkflowRequest, opts ...grpc.CallOption) (WorkflowService_RunWorkflowClient, error)
+}
+
+type workflowServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWorkflowServiceClient(cc grpc.ClientConnInterface) WorkflowServiceClient {
+	return &workflowServiceClient{cc}
+}
+
+func (c *workflowServiceClient) RunWorkflow(ctx context.Context, in *RunWorkflowRequest, opts ...grpc.CallOption) (WorkflowService_RunWorkflowClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WorkflowService_ServiceDesc.Streams[0], WorkflowService_RunWorkflow_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &workflowServiceRunWorkflowClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type WorkflowService_RunWorkflowClient interface {
+	Recv() (*RunWorkflowResponse, error)
+	grpc.ClientStream
+}
+
+type workflowServiceRunWorkflowClient struct {
+	grpc.ClientStream
+}
+
+func (x *workflowServiceRunWorkflowClient) Recv() (*RunWorkflowResponse, error) {
+	m := new(RunWorkflowResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// WorkflowServiceServer is the server API for WorkflowService service.
+// All implementations must embed UnimplementedWorkflowServiceServer
+// for forward compatibility
+type WorkflowServiceServer interface {
+	RunWorkflow(*RunWorkflowRequest, WorkflowService_RunWorkflowServer) error
+	mustEmbedUnimplementedWorkflowServiceServer()
+}
+
+// UnimplementedWorkflowServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedWorkflowServiceServer struct {
+}
+
+func (UnimplementedWorkflowServiceServer) RunWorkflow(*RunWorkflowRequest, WorkflowService_RunWorkflowServer) error {
+	return status.Errorf(codes.Unimplemented, "method RunWorkflow not implemented")
+}
+func (UnimplementedWorkflowServiceServer) mustEmbedUnimplementedWorkflowServiceServer() {}
+
+// UnsafeWorkflowServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WorkflowServiceServer will
+// result in compilation errors.
+type UnsafeWorkflowServiceServer interface {
+	mustEmbedUnimplementedWorkflowServiceServer()
+}
+
+func RegisterWorkflowServiceServer(s grpc.ServiceRegistrar, srv WorkflowServiceServer) {
+	s.RegisterService(&WorkflowService_ServiceDesc, srv)
+}
+
+func _WorkflowService_RunWorkflow_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RunWorkflowRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WorkflowServiceServer).RunWorkflow(m, &workflowServiceRunWorkflowServer{ServerStream: stream})
+}
+
+type WorkflowService_RunWorkflowServer interface {
+	Send(*RunWorkflowResponse) error
+	grpc.ServerStream
+}
+
+type workflowServiceRunWorkflowServer struct {
+	grpc.ServerStream
+}
+
+func (x *workflowServiceRunWorkflowServer) Send(m *RunWorkflowResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// WorkflowService_ServiceDesc is the grpc.ServiceDesc for WorkflowService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WorkflowService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "workflow.v1.WorkflowService",
+	HandlerType: (*WorkflowServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "RunWorkflow",
+			Handler:       _WorkflowService_RunWorkflow_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "workflow.proto",
+}
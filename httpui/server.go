@@ -0,0 +1,198 @@
+// ワークフローの実行状況をブラウザで確認するためのHTTPハンドラーを提供します。
+//
+// Serverは次のパスを提供します。
+//
+//   - /       グラフを描画し、状態の変化に合わせて更新するHTMLページ
+//   - /graph  DAGをMermaidのフローチャート（format=dotを指定した場合はDOT形式）で返す
+//   - /events 状態の変化をServer-Sent Eventsで送る
+package httpui
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/momiom/workflow/dag"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// clientBufferはSSEのクライアントごとに溜めておける未送信のイベントの数です。
+// 読み込みの遅いクライアントがDAGの実行を止めないよう、溢れたイベントは破棄します。
+const clientBuffer = 64
+
+// statusMessageは/eventsで送る状態変化のメッセージです。
+type statusMessage struct {
+	Node   dag.NodeID     `json:"node"`
+	Status dag.NodeStatus `json:"status"`
+}
+
+// ServerはDAGの実行状況を提供するhttp.Handlerです。
+type Server struct {
+	dag *dag.DAG
+	mux *http.ServeMux
+
+	mu        sync.Mutex
+	clients   map[chan []byte]struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewはdのイベントをSSEのクライアントに中継するServerを作成します。
+// ServerはdのGetEventsを購読し、実行が終わるたびに次の実行のために購読し直します。
+// 同じDAGのGetEventsを他で使用しないでください。不要になったらCloseを呼び出してください。
+func New(d *dag.DAG) *Server {
+	s := &Server{
+		dag:     d,
+		mux:     http.NewServeMux(),
+		clients: make(map[chan []byte]struct{}),
+		done:    make(chan struct{}),
+	}
+	s.mux.HandleFunc("/", s.handleIndex)
+	s.mux.HandleFunc("/graph", s.handleGraph)
+	s.mux.HandleFunc("/events", s.handleEvents)
+
+	// 最初の実行のイベントを取りこぼさないよう、ゴルーチンを起動する前に購読する
+	events := d.GetEvents()
+	go s.watch(events)
+	return s
+}
+
+// ServeHTTPはリクエストのパスに応じたハンドラーを呼び出します。
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Closeはイベントの中継を止め、接続中のSSEのクライアントを切断します。
+// DAGの実行を止めないよう、購読中のイベントのチャネルはそれが閉じられるまで読み続けてから購読をやめます。
+func (s *Server) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.disconnectAll()
+	})
+}
+
+// watchはDAGのイベントを読み、状態の変化をクライアントに送るメソッド
+// 実行の終わりにチャネルが閉じられると、Closeされていなければ次の実行のために購読し直します。
+func (s *Server) watch(events <-chan dag.Event) {
+	for {
+		for e := range events {
+			status, ok := e.(dag.StatusEvent)
+			if !ok || s.closed() {
+				continue
+			}
+			data, err := json.Marshal(statusMessage{Node: status.ID, Status: status.Status})
+			if err != nil {
+				continue
+			}
+			s.broadcast(data)
+		}
+		if s.closed() {
+			return
+		}
+		events = s.dag.GetEvents()
+	}
+}
+
+// closedはCloseが呼び出されたかどうかを返すメソッド
+func (s *Server) closed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// broadcastは全てのクライアントにメッセージを送るメソッド
+func (s *Server) broadcast(data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for client := range s.clients {
+		select {
+		case client <- data:
+		default:
+		}
+	}
+}
+
+// disconnectAllは全てのクライアントのチャネルを閉じて切断するメソッド
+func (s *Server) disconnectAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for client := range s.clients {
+		close(client)
+		delete(s.clients, client)
+	}
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(indexHTML)
+}
+
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "mermaid":
+		fmt.Fprint(w, s.dag.ToMermaid())
+	case "dot":
+		fmt.Fprint(w, s.dag.ToDOT())
+	default:
+		http.Error(w, fmt.Sprintf("unknown format %q", format), http.StatusBadRequest)
+	}
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	client := make(chan []byte, clientBuffer)
+	s.mu.Lock()
+	if s.closed() {
+		s.mu.Unlock()
+		http.Error(w, "server is closed", http.StatusServiceUnavailable)
+		return
+	}
+	s.clients[client] = struct{}{}
+	s.mu.Unlock()
+	defer s.removeClient(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case data, ok := <-client:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// removeClientはクライアントの登録を解除するメソッド
+func (s *Server) removeClient(client chan []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.clients[client]; ok {
+		delete(s.clients, client)
+		close(client)
+	}
+}
@@ -0,0 +1,142 @@
+package httpui_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/dag"
+	"github.com/momiom/workflow/httpui"
+	"github.com/momiom/workflow/node"
+)
+
+func newWorkflow(t *testing.T) *dag.DAG {
+	t.Helper()
+	join := func(inputs []string) (string, error) {
+		return strings.Join(inputs, " "), nil
+	}
+	workflow := dag.NewDAG(1)
+	for _, id := range []dag.NodeID{"fetch", "summarize", "notify"} {
+		workflow.AddNode(id, node.NewTextNode(string(id), join))
+	}
+	if err := workflow.AddEdge("fetch", "summarize"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	if err := workflow.AddEdge("summarize", "notify"); err != nil {
+		t.Fatalf("failed to add edge: %v", err)
+	}
+	return workflow
+}
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	res, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("failed to get %s: %v", url, err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	return res.StatusCode, string(body)
+}
+
+func TestServerGraph(t *testing.T) {
+	workflow := newWorkflow(t)
+	ui := httpui.New(workflow)
+	defer ui.Close()
+	srv := httptest.NewServer(ui)
+	defer srv.Close()
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedParts  []string
+	}{
+		{"Mermaid", "", http.StatusOK, []string{"flowchart TD", `["fetch"]`, `["summarize"]`, `["notify"]`}},
+		{"DOT", "?format=dot", http.StatusOK, []string{"digraph workflow", `"fetch" -> "summarize";`, `"summarize" -> "notify";`}},
+		{"Unknown format", "?format=svg", http.StatusBadRequest, []string{"unknown format"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := get(t, srv.URL+"/graph"+tt.query)
+			if status != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, status)
+			}
+			for _, part := range tt.expectedParts {
+				if !strings.Contains(body, part) {
+					t.Fatalf("expected %q in body, got:\n%s", part, body)
+				}
+			}
+		})
+	}
+
+	if status, body := get(t, srv.URL+"/"); status != http.StatusOK || !strings.Contains(body, "EventSource") {
+		t.Fatalf("expected the HTML page, got %d:\n%s", status, body)
+	}
+}
+
+func TestServerEvents(t *testing.T) {
+	workflow := newWorkflow(t)
+	ui := httpui.New(workflow)
+	defer ui.Close()
+	srv := httptest.NewServer(ui)
+	defer srv.Close()
+
+	// ヘッダーを受け取った時点でクライアントは登録済み
+	res, err := http.Get(srv.URL + "/events")
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %s", ct)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := workflow.Execute(context.Background(), map[dag.NodeID][]string{"fetch": {"hello"}})
+		done <- err
+	}()
+
+	// notifyが完了するまでの状態の変化を受け取る
+	completed := make(map[dag.NodeID]bool)
+	scanner := bufio.NewScanner(res.Body)
+	for !completed["notify"] && scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var msg struct {
+			Node   dag.NodeID     `json:"node"`
+			Status dag.NodeStatus `json:"status"`
+		}
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			t.Fatalf("failed to decode event %q: %v", data, err)
+		}
+		if msg.Status == dag.Completed {
+			completed[msg.Node] = true
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, id := range []dag.NodeID{"fetch", "summarize", "notify"} {
+		if !completed[id] {
+			t.Fatalf("expected a completed event for %s, got %v", id, completed)
+		}
+	}
+
+	// 実行後の/graphには完了した状態が反映される
+	if _, body := get(t, srv.URL+"/graph"); !strings.Contains(body, "style n0 fill:#bbf7d0") {
+		t.Fatalf("expected completed nodes in the graph, got:\n%s", body)
+	}
+}
@@ -13,13 +13,13 @@ import (
 
 type MockLLMClient struct{}
 
-func (c *MockLLMClient) GenerateResponse(prompt string) (string, error) {
+func (c *MockLLMClient) GenerateResponse(ctx context.Context, prompt string) (string, error) {
 	return "Mock LLM output 1: Input prompt is \"" + prompt + "\"", nil
 }
 
 type MockLLMClient2 struct{}
 
-func (c *MockLLMClient2) GenerateResponse(prompt string) (string, error) {
+func (c *MockLLMClient2) GenerateResponse(ctx context.Context, prompt string) (string, error) {
 	return "Mock LLM output 2: Input prompt is \"" + prompt + "\"", nil
 }
 
@@ -92,15 +92,16 @@ func main() {
 	}
 
 	// 状態変更と入出力を監視するゴルーチンを起動
+	// 実行の開始前に購読する
+	events := workflow.GetEvents()
 	go func() {
-		for state := range workflow.GetStatusChan() {
-			fmt.Printf("Node %s is now %s\n", state.ID, state.Status)
-		}
-	}()
-
-	go func() {
-		for io := range workflow.GetIOChan() {
-			fmt.Printf("Node %s inputs: %v outputs: %v\n", io.ID, io.Inputs, io.Outputs)
+		for event := range events {
+			switch e := event.(type) {
+			case dag.StatusEvent:
+				fmt.Printf("Node %s is now %s\n", e.ID, e.Status)
+			case dag.IOEvent:
+				fmt.Printf("Node %s inputs: %v outputs: %v\n", e.ID, e.Inputs, e.Outputs)
+			}
 		}
 	}()
 
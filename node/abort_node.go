@@ -0,0 +1,56 @@
+package node
+
+import (
+	"context"
+	"slices"
+)
+
+// AbortNodeは入力が条件を満たした場合に実行全体を打ち切るノードです。
+// 入力をそのまま出力とし、conditionがtrueを返した場合はErrAbortを返します。
+// 処理を続けるべきでない入力を検知するガードレールとして、チェーンの途中に挟んで使用します。
+type AbortNode struct {
+	name      string
+	inputs    []string
+	outputs   []string
+	condition func([]string) bool
+}
+
+// NewAbortNodeは新しいAbortNodeを作成します。
+func NewAbortNode(name string, condition func([]string) bool) *AbortNode {
+	return &AbortNode{name: name, condition: condition}
+}
+
+// Executeは入力を出力とし、conditionを満たす場合はErrAbortを返します。
+func (n *AbortNode) Execute(ctx context.Context) error {
+	n.outputs = slices.Clone(n.inputs)
+	if n.condition(n.inputs) {
+		return ErrAbort
+	}
+	return nil
+}
+
+// Cloneは同じ設定の新しいAbortNodeを返します。
+func (n *AbortNode) Clone() Node {
+	return &AbortNode{name: n.name, condition: n.condition}
+}
+
+// Nameはノードの名前を返します。
+func (n *AbortNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *AbortNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *AbortNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力を消去します。
+func (n *AbortNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+}
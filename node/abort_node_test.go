@@ -0,0 +1,37 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+func TestAbortNode(t *testing.T) {
+	unsafe := func(inputs []string) bool { return slices.Contains(inputs, "unsafe") }
+
+	tests := []struct {
+		name          string
+		inputs        []string
+		expectedError error
+	}{
+		{"Pass through", []string{"hello", "world"}, nil},
+		{"Abort on condition", []string{"hello", "unsafe"}, node.ErrAbort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := node.NewAbortNode("guard", unsafe)
+			n.SetInputs(tt.inputs)
+
+			if err := n.Execute(context.Background()); !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected %v, got %v", tt.expectedError, err)
+			}
+			if outputs := n.GetOutputs(); !slices.Equal(outputs, tt.inputs) {
+				t.Fatalf("expected %v, got %v", tt.inputs, outputs)
+			}
+		})
+	}
+}
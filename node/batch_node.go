@@ -0,0 +1,124 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// BatchNodeは全ての入力にそれぞれprocessorを適用し、入力ごとに1つの出力を返すノードです。
+// 出力は入力と同じ順序で並びます。
+type BatchNode struct {
+	name          string
+	inputs        []string
+	outputs       []string
+	processor     func(string) (string, error)
+	parallelism   int     // 同時に処理する入力の数
+	collectErrors bool    // 失敗した入力があっても残りの入力の処理を続けるかどうか
+	errs          []error // 入力ごとのエラー（collectErrorsが有効な場合のみ）
+}
+
+// NewBatchNodeは新しいBatchNodeを作成します。
+// デフォルトでは入力を1つずつ順に処理し、いずれかの入力が失敗した時点でエラーを返します。
+func NewBatchNode(name string, processor func(string) (string, error)) *BatchNode {
+	return &BatchNode{name: name, processor: processor, parallelism: 1}
+}
+
+// SetParallelismは同時に処理する入力の数の上限を設定します。1未満の値は1として扱います。
+func (n *BatchNode) SetParallelism(parallelism int) {
+	n.parallelism = max(parallelism, 1)
+}
+
+// SetCollectErrorsは失敗した入力があっても処理を続けるかどうかを設定します。
+// 有効にした場合、Executeは入力の失敗ではエラーを返さず、失敗した入力の出力は空文字列になります。
+// 入力ごとのエラーはErrorsで取得できます。
+func (n *BatchNode) SetCollectErrors(collect bool) {
+	n.collectErrors = collect
+}
+
+// Executeは各入力にprocessorを適用します。
+// エラーを収集しない場合は、最初のエラーで未処理の入力の処理を中止してそのエラーを返します。
+func (n *BatchNode) Execute(ctx context.Context) error {
+	outputs := make([]string, len(n.inputs))
+	errs := make([]error, len(n.inputs))
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, max(n.parallelism, 1))
+	var wg sync.WaitGroup
+	for i, input := range n.inputs {
+		select {
+		case sem <- struct{}{}:
+		case <-runCtx.Done():
+		}
+		if runCtx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			output, err := n.processor(input)
+			if err != nil {
+				errs[i] = fmt.Errorf("item %d: %w", i, err)
+				if !n.collectErrors {
+					cancel()
+				}
+				return
+			}
+			outputs[i] = output
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !n.collectErrors {
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+	}
+	n.errs = errs
+	n.outputs = outputs
+	return nil
+}
+
+// Errorsは直前の実行での入力ごとのエラーを、入力と同じ順序で返します。成功した入力の要素はnilです。
+// エラーを収集しない設定の場合はnilを返します。
+func (n *BatchNode) Errors() []error {
+	if !n.collectErrors {
+		return nil
+	}
+	return n.errs
+}
+
+// Cloneは同じ設定の新しいBatchNodeを返します。
+func (n *BatchNode) Clone() Node {
+	return &BatchNode{name: n.name, processor: n.processor, parallelism: n.parallelism, collectErrors: n.collectErrors}
+}
+
+// Nameはノードの名前を返します。
+func (n *BatchNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *BatchNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *BatchNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力、入力ごとのエラーを消去します。
+func (n *BatchNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+	n.errs = nil
+}
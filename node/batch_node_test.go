@@ -0,0 +1,74 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+func TestBatchNode(t *testing.T) {
+	upper := func(input string) (string, error) {
+		return strings.ToUpper(input), nil
+	}
+	failMiddle := func(input string) (string, error) {
+		if input == "b" {
+			return "", errors.New("cannot process b")
+		}
+		return strings.ToUpper(input), nil
+	}
+
+	tests := []struct {
+		name            string
+		processor       func(string) (string, error)
+		parallelism     int
+		collectErrors   bool
+		expectedOutputs []string
+		expectError     bool
+		expectedErrors  []bool
+	}{
+		{"Uppercase sequentially", upper, 1, false, []string{"A", "B", "C"}, false, nil},
+		{"Uppercase in parallel", upper, 3, false, []string{"A", "B", "C"}, false, nil},
+		{"Middle item error aborts", failMiddle, 1, false, nil, true, nil},
+		{"Middle item error aborts in parallel", failMiddle, 3, false, nil, true, nil},
+		{"Middle item error is collected", failMiddle, 3, true, []string{"A", "", "C"}, false, []bool{false, true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := node.NewBatchNode("batchNode", tt.processor)
+			n.SetParallelism(tt.parallelism)
+			n.SetCollectErrors(tt.collectErrors)
+			n.SetInputs([]string{"a", "b", "c"})
+
+			err := n.Execute(context.Background())
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if outputs := n.GetOutputs(); !slices.Equal(outputs, tt.expectedOutputs) {
+				t.Fatalf("expected %v, got %v", tt.expectedOutputs, outputs)
+			}
+			if tt.expectedErrors != nil {
+				errs := n.Errors()
+				if len(errs) != len(tt.expectedErrors) {
+					t.Fatalf("expected %d errors, got %v", len(tt.expectedErrors), errs)
+				}
+				for i, expected := range tt.expectedErrors {
+					if (errs[i] != nil) != expected {
+						t.Fatalf("item %d: expected error %v, got %v", i, expected, errs[i])
+					}
+				}
+			}
+		})
+	}
+}
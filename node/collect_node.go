@@ -0,0 +1,61 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// CollectNodeは全ての入力を1つのJSON配列文字列にまとめるノードです。
+// ファンアウトした結果を集約する用途を想定しています。
+type CollectNode struct {
+	name    string
+	inputs  []string
+	outputs []string
+}
+
+// NewCollectNodeは新しいCollectNodeを作成します。
+func NewCollectNode(name string) *CollectNode {
+	return &CollectNode{name: name}
+}
+
+// Executeは入力を受け取った順序のままJSON配列に変換します。
+// 入力が空の場合は"[]"を出力します。
+func (n *CollectNode) Execute(ctx context.Context) error {
+	items := n.inputs
+	if items == nil {
+		items = []string{}
+	}
+	b, err := json.Marshal(items)
+	if err != nil {
+		return fmt.Errorf("failed to marshal inputs: %w", err)
+	}
+	n.outputs = []string{string(b)}
+	return nil
+}
+
+// Cloneは同じ設定の新しいCollectNodeを返します。
+func (n *CollectNode) Clone() Node {
+	return &CollectNode{name: n.name}
+}
+
+// Nameはノードの名前を返します。
+func (n *CollectNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *CollectNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *CollectNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力を消去します。
+func (n *CollectNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+}
@@ -0,0 +1,36 @@
+package node_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+func TestCollectNode(t *testing.T) {
+	tests := []struct {
+		name           string
+		inputs         []string
+		expectedOutput string
+	}{
+		{"Collect three branch outputs", []string{"branch1", "branch2", "branch3"}, `["branch1","branch2","branch3"]`},
+		{"Escape special characters", []string{`say "hi"`}, `["say \"hi\""]`},
+		{"Empty input", nil, `[]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := node.NewCollectNode("collectNode")
+			n.SetInputs(tt.inputs)
+
+			if err := n.Execute(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			outputs := n.GetOutputs()
+			if len(outputs) != 1 || outputs[0] != tt.expectedOutput {
+				t.Fatalf("expected %v, got %v", tt.expectedOutput, outputs)
+			}
+		})
+	}
+}
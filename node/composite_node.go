@@ -0,0 +1,77 @@
+package node
+
+import (
+	"context"
+	"fmt"
+)
+
+// CompositeNodeは複数のノードを順に実行し、1つのノードとして扱うノードです。
+// 各ノードの出力は次のノードの入力となり、最初のノードがCompositeNodeの入力を受け取り、
+// 最後のノードの出力がCompositeNodeの出力となります。
+type CompositeNode struct {
+	name    string
+	inputs  []string
+	outputs []string
+	nodes   []Node
+}
+
+// NewCompositeNodeは新しいCompositeNodeを作成します。
+// nodesを指定しない場合、CompositeNodeは入力をそのまま出力します。
+func NewCompositeNode(name string, nodes ...Node) *CompositeNode {
+	return &CompositeNode{name: name, nodes: nodes}
+}
+
+// Executeは内部のノードを順に実行します。
+// いずれかのノードがエラーを返した場合は、残りのノードを実行せずにそのエラーを返します。
+func (n *CompositeNode) Execute(ctx context.Context) error {
+	values := n.inputs
+	for i, child := range n.nodes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		child.SetInputs(values)
+		if err := child.Execute(ctx); err != nil {
+			return fmt.Errorf("step %d (%s): %w", i, child.Name(), err)
+		}
+		values = child.GetOutputs()
+	}
+	n.outputs = values
+	return nil
+}
+
+// Cloneは内部のノードを複製した新しいCompositeNodeを返します。
+// Clonerを実装していない内部のノードは複製元と共有されます。
+func (n *CompositeNode) Clone() Node {
+	nodes := make([]Node, len(n.nodes))
+	for i, child := range n.nodes {
+		if c, ok := child.(Cloner); ok {
+			child = c.Clone()
+		}
+		nodes[i] = child
+	}
+	return &CompositeNode{name: n.name, nodes: nodes}
+}
+
+// Nameはノードの名前を返します。
+func (n *CompositeNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *CompositeNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *CompositeNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力を消去し、内部のノードも初期状態に戻します。
+func (n *CompositeNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+	for _, child := range n.nodes {
+		child.Reset()
+	}
+}
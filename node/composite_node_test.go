@@ -0,0 +1,70 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+func TestCompositeNode(t *testing.T) {
+	prompt := func(inputs []string) (string, error) {
+		return "summarize: " + strings.Join(inputs, " "), nil
+	}
+	errBroken := errors.New("broken")
+	broken := func(inputs []string) (string, error) {
+		return "", errBroken
+	}
+
+	tests := []struct {
+		name            string
+		nodes           []node.Node
+		expectedOutputs []string
+		expectedErr     error
+	}{
+		{
+			name: "Text node then LLM node",
+			nodes: []node.Node{
+				node.NewTextNode("prompt", prompt),
+				node.NewLLMNode("llm", &MockLLMClient{}),
+			},
+			expectedOutputs: []string{"mock response: summarize: hello world"},
+		},
+		{
+			name: "Error propagates",
+			nodes: []node.Node{
+				node.NewTextNode("broken", broken),
+				node.NewLLMNode("llm", &MockLLMClient{}),
+			},
+			expectedErr: errBroken,
+		},
+		{
+			name:            "No nodes passes inputs through",
+			expectedOutputs: []string{"hello", "world"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := node.NewCompositeNode("composite", tt.nodes...)
+			n.SetInputs([]string{"hello", "world"})
+
+			err := n.Execute(context.Background())
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if outputs := n.GetOutputs(); !slices.Equal(outputs, tt.expectedOutputs) {
+				t.Fatalf("expected %v, got %v", tt.expectedOutputs, outputs)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package node
+
+import (
+	"context"
+	"fmt"
+)
+
+// FanOutNodeは1つの入力を分岐ごとの変換関数で変換し、分岐のラベルを名前とする出力ポートに出力するノードです。
+// DAGのAddPortEdgeで各ラベルを別々の依存先ノードに接続することで、分岐ごとに異なる値を渡せます。
+// GetOutputsは全ての分岐の出力を、分岐を追加した順に返します。
+type FanOutNode struct {
+	name     string
+	inputs   []string
+	outputs  []string
+	named    map[string]string
+	branches []fanOutBranch
+}
+
+// fanOutBranchはFanOutNodeの1つの分岐です。
+type fanOutBranch struct {
+	label     string
+	transform func(string) (string, error)
+}
+
+// NewFanOutNodeは新しいFanOutNodeを作成します。分岐はAddBranchで追加します。
+func NewFanOutNode(name string) *FanOutNode {
+	return &FanOutNode{name: name}
+}
+
+// AddBranchはlabelの出力ポートに入力をtransformで変換した値を出力する分岐を追加します。
+// 同じラベルの分岐を追加した場合は、前の分岐を置き換えます。
+func (n *FanOutNode) AddBranch(label string, transform func(string) (string, error)) {
+	for i, b := range n.branches {
+		if b.label == label {
+			n.branches[i].transform = transform
+			return
+		}
+	}
+	n.branches = append(n.branches, fanOutBranch{label: label, transform: transform})
+}
+
+// Executeは入力を各分岐の変換関数で変換します。
+// いずれかの分岐がエラーを返した場合は、どのポートにも出力せずにそのエラーを返します。
+func (n *FanOutNode) Execute(ctx context.Context) error {
+	if err := n.ValidateInputs(len(n.inputs)); err != nil {
+		return err
+	}
+
+	outputs := make([]string, 0, len(n.branches))
+	named := make(map[string]string, len(n.branches))
+	for _, b := range n.branches {
+		output, err := b.transform(n.inputs[0])
+		if err != nil {
+			return fmt.Errorf("branch %s: %w", b.label, err)
+		}
+		outputs = append(outputs, output)
+		named[b.label] = output
+	}
+	n.outputs = outputs
+	n.named = named
+	return nil
+}
+
+// ValidateInputsは入力がちょうど1つであることを検証します。
+func (n *FanOutNode) ValidateInputs(count int) error {
+	if count != 1 {
+		return fmt.Errorf("input must be exactly 1, got %d", count)
+	}
+	return nil
+}
+
+// InputSpecはFanOutNodeが受け取る入力の数の範囲を返します。入力はちょうど1つです。
+func (n *FanOutNode) InputSpec() (min, max int) {
+	return 1, 1
+}
+
+// NamedOutputsは分岐のラベルごとの出力を返します。
+func (n *FanOutNode) NamedOutputs() map[string]string {
+	return n.named
+}
+
+// Cloneは同じ分岐を持つ新しいFanOutNodeを返します。
+func (n *FanOutNode) Clone() Node {
+	branches := make([]fanOutBranch, len(n.branches))
+	copy(branches, n.branches)
+	return &FanOutNode{name: n.name, branches: branches}
+}
+
+// Nameはノードの名前を返します。
+func (n *FanOutNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *FanOutNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *FanOutNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力を消去します。
+func (n *FanOutNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+	n.named = nil
+}
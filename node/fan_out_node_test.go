@@ -0,0 +1,59 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+func TestFanOutNode(t *testing.T) {
+	errBranch := errors.New("cannot translate")
+
+	tests := []struct {
+		name            string
+		inputs          []string
+		failLower       bool
+		expectedOutputs []string
+		expectedNamed   map[string]string
+		expectError     bool
+	}{
+		{"Transform per branch", []string{"Hello"}, false, []string{"HELLO", "hello"}, map[string]string{"upper": "HELLO", "lower": "hello"}, false},
+		{"Branch error", []string{"Hello"}, true, nil, nil, true},
+		{"Multiple inputs", []string{"a", "b"}, false, nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := node.NewFanOutNode("fanOut")
+			n.AddBranch("upper", func(input string) (string, error) {
+				return strings.ToUpper(input), nil
+			})
+			n.AddBranch("lower", func(input string) (string, error) {
+				if tt.failLower {
+					return "", errBranch
+				}
+				return strings.ToLower(input), nil
+			})
+			n.SetInputs(tt.inputs)
+
+			err := n.Execute(context.Background())
+			if (err != nil) != tt.expectError {
+				t.Fatalf("expected error: %v, got: %v", tt.expectError, err)
+			}
+			if tt.failLower && !errors.Is(err, errBranch) {
+				t.Fatalf("expected branch error, got %v", err)
+			}
+			if !slices.Equal(n.GetOutputs(), tt.expectedOutputs) {
+				t.Fatalf("expected %v, got %v", tt.expectedOutputs, n.GetOutputs())
+			}
+			if !maps.Equal(n.NamedOutputs(), tt.expectedNamed) {
+				t.Fatalf("expected named outputs %v, got %v", tt.expectedNamed, n.NamedOutputs())
+			}
+		})
+	}
+}
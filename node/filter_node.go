@@ -0,0 +1,55 @@
+package node
+
+import "context"
+
+// FilterNodeは条件を満たす入力だけを出力するノードです。
+// 条件を満たす入力がない場合は出力が空になり、DAGは入力を必要とする後続ノードをスキップします。
+type FilterNode struct {
+	name      string
+	inputs    []string
+	outputs   []string
+	predicate func(string) bool
+}
+
+// NewFilterNodeは新しいFilterNodeを作成します。
+func NewFilterNode(name string, predicate func(string) bool) *FilterNode {
+	return &FilterNode{name: name, predicate: predicate}
+}
+
+// Executeは各入力にpredicateを適用し、trueとなった入力を順序を保って出力とします。
+func (n *FilterNode) Execute(ctx context.Context) error {
+	outputs := []string{}
+	for _, input := range n.inputs {
+		if n.predicate(input) {
+			outputs = append(outputs, input)
+		}
+	}
+	n.outputs = outputs
+	return nil
+}
+
+// Cloneは同じ設定の新しいFilterNodeを返します。
+func (n *FilterNode) Clone() Node {
+	return &FilterNode{name: n.name, predicate: n.predicate}
+}
+
+// Nameはノードの名前を返します。
+func (n *FilterNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *FilterNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *FilterNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力を消去します。
+func (n *FilterNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+}
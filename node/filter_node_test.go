@@ -0,0 +1,37 @@
+package node_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+func TestFilterNode(t *testing.T) {
+	tests := []struct {
+		name            string
+		predicate       func(string) bool
+		inputs          []string
+		expectedOutputs []string
+	}{
+		{"Keep all", func(string) bool { return true }, []string{"a", "b", "c"}, []string{"a", "b", "c"}},
+		{"Keep some", func(s string) bool { return strings.HasPrefix(s, "keep") }, []string{"keep1", "drop", "keep2"}, []string{"keep1", "keep2"}},
+		{"Keep none", func(string) bool { return false }, []string{"a", "b"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := node.NewFilterNode("filterNode", tt.predicate)
+			n.SetInputs(tt.inputs)
+
+			if err := n.Execute(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if outputs := n.GetOutputs(); !slices.Equal(outputs, tt.expectedOutputs) {
+				t.Fatalf("expected %v, got %v", tt.expectedOutputs, outputs)
+			}
+		})
+	}
+}
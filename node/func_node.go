@@ -0,0 +1,57 @@
+package node
+
+import "context"
+
+// NodeFuncは入力を受け取って出力を返す関数です。
+// NewFuncでノードにすることで、構造体を定義せずに簡単な処理をDAGに追加できます。
+type NodeFunc func(inputs []string) ([]string, error)
+
+// funcNodeはNodeFuncをNodeとして扱うためのノードです。
+type funcNode struct {
+	name    string
+	inputs  []string
+	outputs []string
+	fn      NodeFunc
+}
+
+// NewFuncはfnを実行するノードを作成します。
+// TextNodeと異なり、fnは複数の出力を返すことができます。
+func NewFunc(name string, fn NodeFunc) Node {
+	return &funcNode{name: name, fn: fn}
+}
+
+// Executeは入力を関数に渡し、返された出力を保持します。
+func (n *funcNode) Execute(ctx context.Context) error {
+	outputs, err := n.fn(n.inputs)
+	if err != nil {
+		return err
+	}
+	n.outputs = outputs
+	return nil
+}
+
+// Cloneは同じ設定の新しいノードを返します。
+func (n *funcNode) Clone() Node {
+	return &funcNode{name: n.name, fn: n.fn}
+}
+
+// Nameはノードの名前を返します。
+func (n *funcNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *funcNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *funcNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力を消去します。
+func (n *funcNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+}
@@ -0,0 +1,50 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+func TestNewFunc(t *testing.T) {
+	errFailed := errors.New("failed")
+	split := func(inputs []string) ([]string, error) {
+		if len(inputs) == 0 {
+			return nil, errFailed
+		}
+		return strings.Split(inputs[0], ","), nil
+	}
+
+	tests := []struct {
+		name           string
+		inputs         []string
+		expectedOutput []string
+		expectedError  error
+	}{
+		{"Multiple outputs", []string{"a,b,c"}, []string{"a", "b", "c"}, nil},
+		{"Single output", []string{"a"}, []string{"a"}, nil},
+		{"Function error", nil, nil, errFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := node.NewFunc("split", split)
+			if n.Name() != "split" {
+				t.Fatalf("expected name split, got %s", n.Name())
+			}
+			n.SetInputs(tt.inputs)
+
+			err := n.Execute(context.Background())
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if outputs := n.GetOutputs(); !slices.Equal(outputs, tt.expectedOutput) {
+				t.Fatalf("expected %v, got %v", tt.expectedOutput, outputs)
+			}
+		})
+	}
+}
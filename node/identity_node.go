@@ -0,0 +1,54 @@
+package node
+
+import (
+	"context"
+	"slices"
+)
+
+// IdentityNodeは入力をそのまま出力とするノードです。
+// ファンインした入力をまとめ直したり、ファンアウトの起点にしたりするなど、グラフの配線に使用します。
+type IdentityNode struct {
+	name    string
+	inputs  []string
+	outputs []string
+}
+
+// NewIdentityNodeは新しいIdentityNodeを作成します。
+func NewIdentityNode(name string) *IdentityNode {
+	return &IdentityNode{name: name}
+}
+
+// Executeは入力を出力にコピーします。入力が空の場合は出力も空になります。
+func (n *IdentityNode) Execute(ctx context.Context) error {
+	n.outputs = slices.Clone(n.inputs)
+	if n.outputs == nil {
+		n.outputs = []string{}
+	}
+	return nil
+}
+
+// Cloneは同じ設定の新しいIdentityNodeを返します。
+func (n *IdentityNode) Clone() Node {
+	return &IdentityNode{name: n.name}
+}
+
+// Nameはノードの名前を返します。
+func (n *IdentityNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *IdentityNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *IdentityNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力を消去します。
+func (n *IdentityNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+}
@@ -0,0 +1,36 @@
+package node_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+func TestIdentityNode(t *testing.T) {
+	tests := []struct {
+		name   string
+		inputs []string
+	}{
+		{"Forward multiple inputs", []string{"a", "b", "c"}},
+		{"Forward single input", []string{"hello"}},
+		{"Empty input", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := node.NewIdentityNode("identityNode")
+			n.SetInputs(tt.inputs)
+
+			if err := n.Execute(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			outputs := n.GetOutputs()
+			if outputs == nil || !slices.Equal(outputs, tt.inputs) {
+				t.Fatalf("expected %v, got %v", tt.inputs, outputs)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// contextReaderは読み込みのたびにコンテキストを確認するio.Readerです。
+// 大きなデータを分割して読み書きする途中でキャンセルを検知するために使用します。
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// ReaderNodeはio.Readerの内容を全て読み込み、1つの出力とするノードです。
+// DAGから渡される入力は無視されます。readerは最初の実行で読み切られるため、
+// 繰り返し実行する場合は2回目以降の出力が空になることに注意してください。
+type ReaderNode struct {
+	name    string
+	inputs  []string
+	outputs []string
+	reader  io.Reader
+}
+
+// NewReaderNodeはreaderから読み込む新しいReaderNodeを作成します。
+func NewReaderNode(name string, reader io.Reader) *ReaderNode {
+	return &ReaderNode{name: name, reader: reader}
+}
+
+// Executeはreaderの内容を全て読み込み、出力とします。
+// 読み込みの途中でctxがキャンセルされた場合はctxのエラーを返します。
+func (n *ReaderNode) Execute(ctx context.Context) error {
+	var output strings.Builder
+	if _, err := io.Copy(&output, &contextReader{ctx: ctx, r: n.reader}); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to read: %w", err)
+	}
+	n.outputs = []string{output.String()}
+	return nil
+}
+
+// Nameはノードの名前を返します。
+func (n *ReaderNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。入力は出力に影響しません。
+func (n *ReaderNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *ReaderNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力を消去します。
+func (n *ReaderNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+}
+
+// WriterNodeは入力を区切り文字で連結してio.Writerに書き込み、入力をそのまま出力とするノードです。
+type WriterNode struct {
+	name      string
+	inputs    []string
+	outputs   []string
+	writer    io.Writer
+	separator string // 入力を連結する区切り文字
+}
+
+// NewWriterNodeはwriterに書き込む新しいWriterNodeを作成します。
+// デフォルトでは入力を改行で連結します。
+func NewWriterNode(name string, writer io.Writer) *WriterNode {
+	return &WriterNode{name: name, writer: writer, separator: "\n"}
+}
+
+// SetSeparatorは書き込む際に入力を連結する区切り文字を設定します。
+func (n *WriterNode) SetSeparator(sep string) {
+	n.separator = sep
+}
+
+// Executeは連結した入力をwriterに書き込み、入力を出力とします。
+// 書き込みの途中でctxがキャンセルされた場合はctxのエラーを返します。
+func (n *WriterNode) Execute(ctx context.Context) error {
+	data := strings.NewReader(strings.Join(n.inputs, n.separator))
+	if _, err := io.Copy(n.writer, &contextReader{ctx: ctx, r: data}); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to write: %w", err)
+	}
+	n.outputs = slices.Clone(n.inputs)
+	return nil
+}
+
+// Nameはノードの名前を返します。
+func (n *WriterNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *WriterNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *WriterNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力を消去します。
+func (n *WriterNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+}
@@ -0,0 +1,81 @@
+package node_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+func TestReaderNode(t *testing.T) {
+	n := node.NewReaderNode("reader", strings.NewReader("line1\nline2"))
+	n.SetInputs([]string{"ignored"})
+
+	if err := n.Execute(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outputs := n.GetOutputs(); len(outputs) != 1 || outputs[0] != "line1\nline2" {
+		t.Fatalf("expected the whole reader as output, got %q", outputs)
+	}
+
+	t.Run("Cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		n := node.NewReaderNode("reader", strings.NewReader("data"))
+		if err := n.Execute(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected %v, got %v", context.Canceled, err)
+		}
+	})
+}
+
+func TestWriterNode(t *testing.T) {
+	tests := []struct {
+		name           string
+		separator      string
+		inputs         []string
+		expectedOutput string
+	}{
+		{"Default separator", "", []string{"hello", "world"}, "hello\nworld"},
+		{"Custom separator", ", ", []string{"a", "b", "c"}, "a, b, c"},
+		{"No inputs", "", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n := node.NewWriterNode("writer", &buf)
+			if tt.separator != "" {
+				n.SetSeparator(tt.separator)
+			}
+			n.SetInputs(tt.inputs)
+
+			if err := n.Execute(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tt.expectedOutput {
+				t.Fatalf("expected %q to be written, got %q", tt.expectedOutput, buf.String())
+			}
+			if outputs := n.GetOutputs(); !slices.Equal(outputs, tt.inputs) {
+				t.Fatalf("expected inputs to pass through, got %q", outputs)
+			}
+		})
+	}
+
+	t.Run("Cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var buf bytes.Buffer
+		n := node.NewWriterNode("writer", &buf)
+		n.SetInputs([]string{"data"})
+		if err := n.Execute(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected %v, got %v", context.Canceled, err)
+		}
+		if buf.Len() != 0 {
+			t.Fatalf("expected nothing to be written, got %q", buf.String())
+		}
+	})
+}
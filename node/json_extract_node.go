@@ -0,0 +1,125 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONExtractNodeはJSONの入力から指定したパスの値を取り出すノードです。
+// パスはgjsonと同様にドット区切りでキーと配列のインデックスを指定します（例: "user.tags.0"）。
+// キーにドットを含む場合は"\."でエスケープします。
+type JSONExtractNode struct {
+	name    string
+	inputs  []string
+	outputs []string
+	paths   []string
+}
+
+// NewJSONExtractNodeは新しいJSONExtractNodeを作成します。
+func NewJSONExtractNode(name string, paths ...string) *JSONExtractNode {
+	return &JSONExtractNode{name: name, paths: paths}
+}
+
+// Executeは1つのJSON入力から各パスの値を取り出し、パスの順に出力とします。
+// 文字列の値はそのまま、それ以外の値はJSONとして出力します。
+func (n *JSONExtractNode) Execute(ctx context.Context) error {
+	if len(n.inputs) != 1 {
+		return fmt.Errorf("input must be exactly 1, got %d", len(n.inputs))
+	}
+
+	var doc any
+	if err := json.Unmarshal([]byte(n.inputs[0]), &doc); err != nil {
+		return fmt.Errorf("invalid JSON input: %w", err)
+	}
+
+	outputs := make([]string, 0, len(n.paths))
+	for _, path := range n.paths {
+		val, err := extractJSONPath(doc, path)
+		if err != nil {
+			return err
+		}
+		if s, ok := val.(string); ok {
+			outputs = append(outputs, s)
+			continue
+		}
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Errorf("failed to marshal value at path %q: %w", path, err)
+		}
+		outputs = append(outputs, string(b))
+	}
+	n.outputs = outputs
+	return nil
+}
+
+// splitJSONPathはパスをドットで分割します。"\."はキーの一部として扱います。
+func splitJSONPath(path string) []string {
+	var parts []string
+	var current strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path) && path[i+1] == '.':
+			current.WriteByte('.')
+			i++
+		case path[i] == '.':
+			parts = append(parts, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(path[i])
+		}
+	}
+	return append(parts, current.String())
+}
+
+// extractJSONPathはデコード済みのJSONからパスの値を取り出します。
+func extractJSONPath(doc any, path string) (any, error) {
+	current := doc
+	for _, part := range splitJSONPath(path) {
+		switch v := current.(type) {
+		case map[string]any:
+			val, ok := v[part]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found: missing key %q", path, part)
+			}
+			current = val
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("path %q not found: invalid index %q", path, part)
+			}
+			current = v[i]
+		default:
+			return nil, fmt.Errorf("path %q not found: cannot descend into %q", path, part)
+		}
+	}
+	return current, nil
+}
+
+// Cloneは同じ設定の新しいJSONExtractNodeを返します。
+func (n *JSONExtractNode) Clone() Node {
+	return &JSONExtractNode{name: n.name, paths: n.paths}
+}
+
+// Nameはノードの名前を返します。
+func (n *JSONExtractNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *JSONExtractNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *JSONExtractNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力を消去します。
+func (n *JSONExtractNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+}
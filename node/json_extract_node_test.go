@@ -0,0 +1,47 @@
+package node_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+func TestJSONExtractNode(t *testing.T) {
+	doc := `{"user":{"name":"alice","age":30,"tags":["admin","dev"],"a.b":true},"items":[{"id":1},{"id":2}]}`
+
+	tests := []struct {
+		name            string
+		paths           []string
+		inputs          []string
+		expectedOutputs []string
+		expectError     bool
+	}{
+		{"Nested field", []string{"user.name"}, []string{doc}, []string{"alice"}, false},
+		{"Multiple paths", []string{"user.age", "user.tags.1", "items.1.id"}, []string{doc}, []string{"30", "dev", "2"}, false},
+		{"Object value", []string{"items.0"}, []string{doc}, []string{`{"id":1}`}, false},
+		{"Escaped dot", []string{`user.a\.b`}, []string{doc}, []string{"true"}, false},
+		{"Missing key", []string{"user.email"}, []string{doc}, nil, true},
+		{"Index out of range", []string{"items.5"}, []string{doc}, nil, true},
+		{"Malformed JSON", []string{"user.name"}, []string{`{"user":`}, nil, true},
+		{"Multiple inputs", []string{"user.name"}, []string{doc, doc}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := node.NewJSONExtractNode("jsonNode", tt.paths...)
+			n.SetInputs(tt.inputs)
+
+			err := n.Execute(context.Background())
+			if (err != nil) != tt.expectError {
+				t.Fatalf("expected error: %v, got: %v", tt.expectError, err)
+			}
+			if !tt.expectError {
+				if outputs := n.GetOutputs(); !slices.Equal(outputs, tt.expectedOutputs) {
+					t.Fatalf("expected %v, got %v", tt.expectedOutputs, outputs)
+				}
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package node
 
 import (
+	"context"
 	"fmt"
+	"strings"
 )
 
 // LLMNodeはLLM（大規模言語モデル）を利用するノードです。
@@ -10,11 +12,20 @@ type LLMNode struct {
 	inputs    []string
 	outputs   []string
 	llmClient LLMClient
+	joiner    *string        // 複数の入力を連結する区切り文字（nilの場合は入力を1つに限定する）
+	metadata  map[string]any // 直前の実行のプロンプトと応答の長さ
 }
 
 // LLMClientはLLMサービスと通信するためのインターフェースです。
 type LLMClient interface {
-	GenerateResponse(prompt string) (string, error)
+	GenerateResponse(ctx context.Context, prompt string) (string, error)
+}
+
+// StreamingLLMClientは応答をトークンなどの断片ごとに返すLLMクライアントのインターフェースです。
+// LLMNodeのクライアントがこのインターフェースを実装している場合、LLMNodeは応答をストリーミングします。
+type StreamingLLMClient interface {
+	// GenerateStreamは応答の断片を順に送信し、応答が終わるとチャネルを閉じます。
+	GenerateStream(ctx context.Context, prompt string) (<-chan string, error)
 }
 
 // NewLLMNodeは新しいLLMNodeを作成します。
@@ -23,23 +34,104 @@ func NewLLMNode(name string, client LLMClient) *LLMNode {
 }
 
 // ExecuteはLLMにテキストを送り、応答を受け取ります。
-func (n *LLMNode) Execute() error {
-	if len(n.inputs) != 1 {
-		return fmt.Errorf("input must be exactly 1, got %d", len(n.inputs))
+// クライアントがStreamingLLMClientを実装している場合は、応答の断片をEmitChunkで逐次送ります。
+func (n *LLMNode) Execute(ctx context.Context) error {
+	if err := n.ValidateInputs(len(n.inputs)); err != nil {
+		return err
+	}
+	prompt := n.inputs[0]
+	if n.joiner != nil {
+		prompt = strings.Join(n.inputs, *n.joiner)
 	}
-	if len(n.inputs[0]) == 0 {
+	if len(prompt) == 0 {
 		return fmt.Errorf("input must not be empty")
 	}
 
-	response, err := n.llmClient.GenerateResponse(n.inputs[0])
+	if streaming, ok := n.llmClient.(StreamingLLMClient); ok {
+		return n.executeStream(ctx, streaming, prompt)
+	}
+
+	response, err := n.llmClient.GenerateResponse(ctx, prompt)
 	if err != nil {
 		return err
 	}
 
+	n.setOutput(prompt, response)
+	return nil
+}
+
+// setOutputは応答を出力とし、プロンプトと応答の長さをメタデータに記録します。
+func (n *LLMNode) setOutput(prompt, response string) {
 	n.outputs = []string{response}
+	n.metadata = map[string]any{
+		"prompt_length":   len(prompt),
+		"response_length": len(response),
+	}
+}
+
+// Metadataは直前の実行のプロンプトと応答の長さ（バイト数）を"prompt_length"と"response_length"で返します。
+func (n *LLMNode) Metadata() map[string]any {
+	return n.metadata
+}
+
+// executeStreamは応答の断片をEmitChunkで送りつつ、全体を連結したものを出力とします。
+func (n *LLMNode) executeStream(ctx context.Context, client StreamingLLMClient, prompt string) error {
+	chunks, err := client.GenerateStream(ctx, prompt)
+	if err != nil {
+		return err
+	}
+
+	var response strings.Builder
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				n.setOutput(prompt, response.String())
+				return nil
+			}
+			response.WriteString(chunk)
+			EmitChunk(ctx, chunk)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// SetJoinerは複数の入力をsepで連結して1つのプロンプトにするよう設定します。
+// 設定しない場合、LLMNodeはちょうど1つの入力を必要とします。
+func (n *LLMNode) SetJoiner(sep string) {
+	n.joiner = &sep
+}
+
+// ValidateInputsは入力の数がLLMNodeの要件を満たすかを検証します。
+// 連結の区切り文字が設定されていない場合は入力がちょうど1つである必要があります。
+func (n *LLMNode) ValidateInputs(count int) error {
+	if n.joiner != nil {
+		if count == 0 {
+			return fmt.Errorf("input must be at least 1, got %d", count)
+		}
+		return nil
+	}
+	if count != 1 {
+		return fmt.Errorf("input must be exactly 1, got %d", count)
+	}
 	return nil
 }
 
+// InputSpecはLLMNodeが受け取る入力の数の範囲を返します。
+// 連結の区切り文字が設定されている場合は1つ以上、設定されていない場合はちょうど1つです。
+func (n *LLMNode) InputSpec() (min, max int) {
+	if n.joiner != nil {
+		return 1, -1
+	}
+	return 1, 1
+}
+
+// Cloneは同じ設定の新しいLLMNodeを返します。
+func (n *LLMNode) Clone() Node {
+	return &LLMNode{name: n.name, llmClient: n.llmClient, joiner: n.joiner}
+}
+
 // Nameはノードの名前を返します。
 func (n *LLMNode) Name() string {
 	return n.name
@@ -54,3 +146,10 @@ func (n *LLMNode) SetInputs(inputs []string) {
 func (n *LLMNode) GetOutputs() []string {
 	return n.outputs
 }
+
+// Resetはノードの入力と出力、メタデータを消去します。
+func (n *LLMNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+	n.metadata = nil
+}
@@ -1,6 +1,9 @@
 package node_test
 
 import (
+	"context"
+	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/momiom/workflow/node"
@@ -8,7 +11,7 @@ import (
 
 type MockLLMClient struct{}
 
-func (c *MockLLMClient) GenerateResponse(prompt string) (string, error) {
+func (c *MockLLMClient) GenerateResponse(ctx context.Context, prompt string) (string, error) {
 	return "mock response: " + prompt, nil
 }
 
@@ -31,7 +34,7 @@ func TestLLMNode(t *testing.T) {
 			n := node.NewLLMNode("llmNode", client)
 			n.SetInputs(tt.inputs)
 
-			err := n.Execute()
+			err := n.Execute(context.Background())
 			if (err != nil) != tt.expectError {
 				t.Fatalf("expected error: %v, got: %v", tt.expectError, err)
 			}
@@ -45,3 +48,113 @@ func TestLLMNode(t *testing.T) {
 		})
 	}
 }
+
+// MockStreamingLLMClientは応答を3つの断片に分けてストリーミングするクライアントです。
+type MockStreamingLLMClient struct{}
+
+func (c *MockStreamingLLMClient) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	return "", fmt.Errorf("GenerateResponse must not be called for streaming clients")
+}
+
+func (c *MockStreamingLLMClient) GenerateStream(ctx context.Context, prompt string) (<-chan string, error) {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for _, chunk := range []string{"mock ", "response: ", prompt} {
+			select {
+			case ch <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func TestLLMNodeStreaming(t *testing.T) {
+	var chunks []string
+	ctx := node.WithStream(context.Background(), func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+
+	n := node.NewLLMNode("llmNode", &MockStreamingLLMClient{})
+	n.SetInputs([]string{"hello"})
+	if err := n.Execute(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(chunks, []string{"mock ", "response: ", "hello"}) {
+		t.Fatalf("expected 3 chunks, got %q", chunks)
+	}
+	if outputs := n.GetOutputs(); len(outputs) != 1 || outputs[0] != "mock response: hello" {
+		t.Fatalf("expected assembled output, got %v", outputs)
+	}
+}
+
+func TestLLMNodeJoiner(t *testing.T) {
+	n := node.NewLLMNode("llmNode", &MockLLMClient{})
+	n.SetJoiner(" / ")
+	n.SetInputs([]string{"first", "second"})
+
+	if err := n.Execute(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outputs := n.GetOutputs(); len(outputs) != 1 || outputs[0] != "mock response: first / second" {
+		t.Fatalf("expected joined prompt, got %v", outputs)
+	}
+}
+
+func TestLLMNodeValidateInputs(t *testing.T) {
+	tests := []struct {
+		name        string
+		joiner      bool
+		count       int
+		expectError bool
+	}{
+		{"Single input", false, 1, false},
+		{"Multiple inputs without joiner", false, 2, true},
+		{"No input without joiner", false, 0, true},
+		{"Multiple inputs with joiner", true, 3, false},
+		{"No input with joiner", true, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := node.NewLLMNode("llmNode", &MockLLMClient{})
+			if tt.joiner {
+				n.SetJoiner("\n")
+			}
+			if err := n.ValidateInputs(tt.count); (err != nil) != tt.expectError {
+				t.Fatalf("expected error: %v, got: %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestLLMNodeInputSpec(t *testing.T) {
+	n := node.NewLLMNode("llmNode", &MockLLMClient{})
+	if min, max := n.InputSpec(); min != 1 || max != 1 {
+		t.Fatalf("expected (1, 1), got (%d, %d)", min, max)
+	}
+	n.SetJoiner("\n")
+	if min, max := n.InputSpec(); min != 1 || max != -1 {
+		t.Fatalf("expected (1, -1) with joiner, got (%d, %d)", min, max)
+	}
+}
+
+func TestLLMNodeMetadata(t *testing.T) {
+	n := node.NewLLMNode("llmNode", &MockLLMClient{})
+	n.SetInputs([]string{"hello"})
+	if err := n.Execute(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	metadata := n.Metadata()
+	if metadata["prompt_length"] != 5 || metadata["response_length"] != len("mock response: hello") {
+		t.Fatalf("unexpected metadata: %v", metadata)
+	}
+	n.Reset()
+	if n.Metadata() != nil {
+		t.Fatalf("expected metadata to be cleared, got %v", n.Metadata())
+	}
+}
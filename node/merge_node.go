@@ -0,0 +1,53 @@
+package node
+
+import "context"
+
+// MergeNodeはファンインした複数の入力をreducerで集約するノードです。
+// TextNodeと異なり、reducerは複数の出力を返すことができます。
+type MergeNode struct {
+	name    string
+	inputs  []string
+	outputs []string
+	reducer func([]string) ([]string, error)
+}
+
+// NewMergeNodeは新しいMergeNodeを作成します。
+func NewMergeNode(name string, reducer func([]string) ([]string, error)) *MergeNode {
+	return &MergeNode{name: name, reducer: reducer}
+}
+
+// Executeはreducerで全ての入力を集約し、その結果を出力とします。
+func (n *MergeNode) Execute(ctx context.Context) error {
+	outputs, err := n.reducer(n.inputs)
+	if err != nil {
+		return err
+	}
+	n.outputs = outputs
+	return nil
+}
+
+// Cloneは同じ設定の新しいMergeNodeを返します。
+func (n *MergeNode) Clone() Node {
+	return &MergeNode{name: n.name, reducer: n.reducer}
+}
+
+// Nameはノードの名前を返します。
+func (n *MergeNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *MergeNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *MergeNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力を消去します。
+func (n *MergeNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+}
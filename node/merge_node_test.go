@@ -0,0 +1,59 @@
+package node_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+func TestMergeNode(t *testing.T) {
+	summarize := func(inputs []string) ([]string, error) {
+		if len(inputs) == 0 {
+			return nil, fmt.Errorf("input must not be empty")
+		}
+		return []string{fmt.Sprintf("%d items: %s", len(inputs), strings.Join(inputs, ", "))}, nil
+	}
+	dedupe := func(inputs []string) ([]string, error) {
+		var outputs []string
+		for _, input := range inputs {
+			if !slices.Contains(outputs, input) {
+				outputs = append(outputs, input)
+			}
+		}
+		return outputs, nil
+	}
+
+	tests := []struct {
+		name            string
+		reducer         func([]string) ([]string, error)
+		inputs          []string
+		expectedOutputs []string
+		expectError     bool
+	}{
+		{"Merge into a single summary", summarize, []string{"a", "b", "c"}, []string{"3 items: a, b, c"}, false},
+		{"Merge into multiple outputs", dedupe, []string{"a", "b", "a"}, []string{"a", "b"}, false},
+		{"Reducer error", summarize, nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := node.NewMergeNode("mergeNode", tt.reducer)
+			n.SetInputs(tt.inputs)
+
+			err := n.Execute(context.Background())
+			if (err != nil) != tt.expectError {
+				t.Fatalf("expected error: %v, got: %v", tt.expectError, err)
+			}
+
+			if !tt.expectError {
+				if outputs := n.GetOutputs(); !slices.Equal(outputs, tt.expectedOutputs) {
+					t.Fatalf("expected %v, got %v", tt.expectedOutputs, outputs)
+				}
+			}
+		})
+	}
+}
@@ -1,10 +1,25 @@
 // ノードのインターフェースを定義します。
 package node
 
+import (
+	"context"
+	"errors"
+)
+
+// ErrSkipはノードが処理をスキップしたことを示すエラーです。
+// ExecuteがErrSkipを返したノードはSkipped状態となり、出力を持ちません。
+var ErrSkip = errors.New("node skipped")
+
+// ErrAbortはノードが実行全体を正常に打ち切ることを求めるエラーです。
+// ExecuteがErrAbortを返したノードは完了したものとして出力が記録され、DAGは以降のノードを開始せずに
+// 実行を終えます。実行はエラーとならず、RunはStatusがRunAbortedの結果を返します。
+var ErrAbort = errors.New("workflow aborted")
+
 // Nodeインターフェースは全てのノードが実装すべきメソッドを定義します。
+// 同じノードは実行ごとにResetを呼んだうえで繰り返し実行されるため、順に再利用できる必要があります。
 type Node interface {
 	// Executeはノードのメインの処理を実行します。
-	Execute() error
+	Execute(ctx context.Context) error
 
 	// Nameはノードの名前を返します。
 	Name() string
@@ -14,4 +29,59 @@ type Node interface {
 
 	// GetOutputsはノードの出力を返します。
 	GetOutputs() []string
+
+	// Resetは前回の実行の入力と出力を消去します。DAGは各実行の開始前に全てのノードで呼び出します。
+	Reset()
+}
+
+// KeyedOutputerはキーごとに分けた出力を返すノードが実装するインターフェースです。
+// DAGのSetEdgeOutputでキーを指定したエッジには、GetOutputsの代わりにそのキーの出力だけが渡されます。
+type KeyedOutputer interface {
+	// GetKeyedOutputsはキーごとの出力を返します。
+	GetKeyedOutputs() map[string][]string
+}
+
+// NamedOutputerは名前付きの出力ポートを持つノードが実装するインターフェースです。
+// DAGのAddPortEdgeでポートを指定したエッジには、GetOutputsの代わりにそのポートの値だけが渡されます。
+type NamedOutputer interface {
+	// NamedOutputsはポートの名前ごとの出力を返します。
+	NamedOutputs() map[string]string
+}
+
+// MetadataProviderは出力とは別に、実行についてのメタデータを返すノードが実装するインターフェースです。
+// DAGはノードの完了時にこれを呼び出し、ExecuteResultのMetadataと入出力のイベントに記録します。
+type MetadataProvider interface {
+	// Metadataは直前の実行のメタデータ（トークン数、HTTPのステータスコードなど）を返します。
+	Metadata() map[string]any
+}
+
+// InputValidatorは受け取る入力の数を検証できるノードが実装するインターフェースです。
+// DAGはValidateやDryRunの際に、グラフのファンインから見込まれる入力の数でこれを呼び出します。
+type InputValidator interface {
+	// ValidateInputsはcount個の入力を受け取った場合に処理できるかを検証します。
+	ValidateInputs(count int) error
+}
+
+// InputSpecは受け取る入力の数の範囲を宣言するノードが実装するインターフェースです。
+// DAGはValidateやDryRunの際に、グラフのファンインと外部入力の数の合計がこの範囲に収まるかを検証します。
+type InputSpec interface {
+	// InputSpecは入力の数の最小値と最大値を返します。maxが負の場合は上限がないことを表します。
+	InputSpec() (min, max int)
+}
+
+// Lifecycleは実行の開始と終了に合わせて準備と後片付けを行うノードが実装するインターフェースです。
+// DAGは各実行でノードを実行する前に全てのノードのStartを呼び出し、実行の終了時にStopを呼び出します。
+type Lifecycle interface {
+	// Startは実行の開始時に呼ばれます。エラーを返した場合はノードを実行せずに実行全体がエラーとなります。
+	Start(ctx context.Context) error
+
+	// Stopは実行の終了時に呼ばれます。Startが成功したノードだけで呼ばれます。
+	Stop()
+}
+
+// ClonerはDAGの複製（dag.DAG.Clone）の際に複製できるノードが実装するインターフェースです。
+// 実装していないノードは複製元のDAGと共有されます。
+type Cloner interface {
+	// Cloneは設定を引き継ぎ、入力と出力を持たない新しいノードを返します。
+	Clone() Node
 }
@@ -0,0 +1,41 @@
+package node
+
+import "errors"
+
+// RetryableErrorは一時的な失敗のため再試行してよいことを示すエラーです。
+// DAGの再試行（dag.WithRetry）は、Temporaryがtrueを返すエラーだけを再試行します。
+type RetryableError struct {
+	Err error
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// Temporaryは常にtrueを返します。
+func (e *RetryableError) Temporary() bool {
+	return true
+}
+
+// Retryableはerrを再試行してよいエラーとして包みます。errがnilの場合はnilを返します。
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableError{Err: err}
+}
+
+// IsRetryableはerrを再試行してよいかを返します。
+// errまたはそれが包むエラーのいずれかがTemporary() boolを実装し、trueを返す場合に再試行できます。
+// それ以外のエラーは入力の誤りなどの恒久的な失敗として扱います。
+func IsRetryable(err error) bool {
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) {
+		return temporary.Temporary()
+	}
+	return false
+}
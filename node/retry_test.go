@@ -0,0 +1,42 @@
+package node_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+// permanentErrorはTemporaryがfalseを返すエラーです。
+type permanentError struct{}
+
+func (permanentError) Error() string   { return "permanent" }
+func (permanentError) Temporary() bool { return false }
+
+func TestIsRetryable(t *testing.T) {
+	base := errors.New("unavailable")
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Plain error", base, false},
+		{"Retryable error", node.Retryable(base), true},
+		{"Wrapped retryable error", fmt.Errorf("call failed: %w", node.Retryable(base)), true},
+		{"Temporary false", permanentError{}, false},
+		{"Nil error", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := node.IsRetryable(tt.err); got != tt.expected {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if !errors.Is(node.Retryable(base), base) {
+		t.Fatal("expected retryable error to unwrap to the original error")
+	}
+}
@@ -0,0 +1,64 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RetryingLLMClientは失敗したGenerateResponseを再試行するLLMClientのデコレーターです。
+// DAGの再試行（dag.WithRetry）とは独立しているため、DAGの外でも同じ再試行の処理を利用できます。
+// ラップしたクライアントがStreamingLLMClientを実装していても、応答はストリーミングされません。
+type RetryingLLMClient struct {
+	client         LLMClient
+	attempts       int           // GenerateResponseを呼び出す最大回数
+	backoff        time.Duration // 最初の再試行までの待機時間
+	attemptTimeout time.Duration // 1回の呼び出しの制限時間（0以下は制限なし）
+}
+
+// NewRetryingLLMClientはclientを最大attempts回まで呼び出すRetryingLLMClientを作成します。
+// 再試行の前にはbackoffを起点に倍々に増える時間だけ待機します。attemptsが1未満の場合は1として扱います。
+func NewRetryingLLMClient(client LLMClient, attempts int, backoff time.Duration) *RetryingLLMClient {
+	return &RetryingLLMClient{client: client, attempts: max(attempts, 1), backoff: backoff}
+}
+
+// SetAttemptTimeoutは1回の呼び出しの制限時間を設定します。
+// 制限時間を超えた呼び出しは失敗として扱い、再試行します。0以下を指定すると制限はなくなります。
+func (c *RetryingLLMClient) SetAttemptTimeout(timeout time.Duration) {
+	c.attemptTimeout = timeout
+}
+
+// GenerateResponseはラップしたクライアントで応答を生成し、失敗した場合は再試行します。
+// ctxがキャンセルされた場合や、次の再試行までの待機中にctxの期限が来る場合は、再試行せずに直前のエラーを返します。
+func (c *RetryingLLMClient) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	backoff := c.backoff
+	for attempt := 1; ; attempt++ {
+		response, err := c.generate(ctx, prompt)
+		if err == nil {
+			return response, nil
+		}
+		if attempt >= c.attempts || ctx.Err() != nil {
+			return "", fmt.Errorf("failed after %d attempts: %w", attempt, err)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+			return "", fmt.Errorf("failed after %d attempts: %w", attempt, err)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return "", fmt.Errorf("failed after %d attempts: %w", attempt, err)
+		}
+		backoff *= 2
+	}
+}
+
+// generateは制限時間を適用してラップしたクライアントを1回呼び出します。
+func (c *RetryingLLMClient) generate(ctx context.Context, prompt string) (string, error) {
+	if c.attemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.attemptTimeout)
+		defer cancel()
+	}
+	return c.client.GenerateResponse(ctx, prompt)
+}
@@ -0,0 +1,93 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/momiom/workflow/node"
+)
+
+// flakyLLMClientは最初のfailures回の呼び出しを失敗させるLLMClientです。
+// hangがtrueの場合、失敗させる呼び出しはエラーを返す代わりにコンテキストの終了まで待機します。
+type flakyLLMClient struct {
+	failures int
+	hang     bool
+	attempts int
+}
+
+var errFlaky = errors.New("service unavailable")
+
+func (c *flakyLLMClient) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	c.attempts++
+	if c.attempts <= c.failures {
+		if c.hang {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "", errFlaky
+	}
+	return "response: " + prompt, nil
+}
+
+func TestRetryingLLMClient(t *testing.T) {
+	tests := []struct {
+		name             string
+		failures         int
+		attempts         int
+		hang             bool
+		expectedAttempts int
+		expectedError    error
+	}{
+		{"Succeeds first time", 0, 3, false, 1, nil},
+		{"Succeeds after failures", 2, 3, false, 3, nil},
+		{"Gives up after max attempts", 5, 3, false, 3, errFlaky},
+		{"Retries timed out attempts", 1, 2, true, 2, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &flakyLLMClient{failures: tt.failures, hang: tt.hang}
+			client := node.NewRetryingLLMClient(mock, tt.attempts, time.Millisecond)
+			client.SetAttemptTimeout(50 * time.Millisecond)
+
+			response, err := client.GenerateResponse(context.Background(), "hello")
+			if mock.attempts != tt.expectedAttempts {
+				t.Fatalf("expected %d attempts, got %d", tt.expectedAttempts, mock.attempts)
+			}
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Fatalf("expected %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if response != "response: hello" {
+				t.Fatalf("expected response: hello, got %s", response)
+			}
+		})
+	}
+}
+
+func TestRetryingLLMClientHonorsDeadline(t *testing.T) {
+	mock := &flakyLLMClient{failures: 10}
+	client := node.NewRetryingLLMClient(mock, 10, time.Second)
+
+	// 次の再試行までの待機が期限を超えるため、1回目の失敗で諦める
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.GenerateResponse(ctx, "hello")
+	if !errors.Is(err, errFlaky) {
+		t.Fatalf("expected %v, got %v", errFlaky, err)
+	}
+	if mock.attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", mock.attempts)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("expected to give up without waiting, took %v", elapsed)
+	}
+}
@@ -0,0 +1,78 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ScriptNodeは外部コマンドを実行するノードです。
+// 入力を区切り文字で連結して標準入力に書き込み、標準出力の内容を1つの出力とします。
+// コマンドはシェルを介さずに直接実行されます。
+type ScriptNode struct {
+	name      string
+	inputs    []string
+	outputs   []string
+	command   string
+	args      []string
+	separator string // 入力を連結する区切り文字
+}
+
+// NewScriptNodeはcommandをargsで実行する新しいScriptNodeを作成します。
+// デフォルトでは入力を改行で連結します。
+func NewScriptNode(name string, command string, args ...string) *ScriptNode {
+	return &ScriptNode{name: name, command: command, args: args, separator: "\n"}
+}
+
+// SetSeparatorは標準入力に書き込む際に入力を連結する区切り文字を設定します。
+func (n *ScriptNode) SetSeparator(sep string) {
+	n.separator = sep
+}
+
+// Executeはコマンドを実行し、標準出力の内容を出力とします。
+// ctxがキャンセルされた場合はコマンドを強制終了します。
+// コマンドが0以外の終了コードで終了した場合は、標準エラー出力の内容を含むエラーを返します。
+func (n *ScriptNode) Execute(ctx context.Context) error {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, n.command, n.args...)
+	cmd.Stdin = strings.NewReader(strings.Join(n.inputs, n.separator))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("command %s failed: %w: %s", n.command, err, strings.TrimSpace(stderr.String()))
+	}
+	n.outputs = []string{stdout.String()}
+	return nil
+}
+
+// Cloneは同じ設定の新しいScriptNodeを返します。
+func (n *ScriptNode) Clone() Node {
+	return &ScriptNode{name: n.name, command: n.command, args: n.args, separator: n.separator}
+}
+
+// Nameはノードの名前を返します。
+func (n *ScriptNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *ScriptNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *ScriptNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力を消去します。
+func (n *ScriptNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+}
@@ -0,0 +1,69 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/momiom/workflow/node"
+)
+
+func TestScriptNode(t *testing.T) {
+	for _, command := range []string{"cat", "sh", "sleep"} {
+		if _, err := exec.LookPath(command); err != nil {
+			t.Skipf("%s is not available: %v", command, err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		command        string
+		args           []string
+		inputs         []string
+		expectedOutput string
+		expectedError  string
+	}{
+		{"Echo inputs with cat", "cat", nil, []string{"hello", "world"}, "hello\nworld", ""},
+		{"Non-zero exit includes stderr", "sh", []string{"-c", "echo boom >&2; exit 3"}, []string{"ignored"}, "", "boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := node.NewScriptNode("script", tt.command, tt.args...)
+			n.SetInputs(tt.inputs)
+
+			err := n.Execute(context.Background())
+			if tt.expectedError != "" {
+				var exitErr *exec.ExitError
+				if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected exit code 3 with %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if outputs := n.GetOutputs(); len(outputs) != 1 || outputs[0] != tt.expectedOutput {
+				t.Fatalf("expected %q, got %q", tt.expectedOutput, outputs)
+			}
+		})
+	}
+
+	t.Run("Cancellation kills the command", func(t *testing.T) {
+		n := node.NewScriptNode("script", "sleep", "10")
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := n.Execute(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("expected the command to be killed, took %v", elapsed)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package node
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// redactedはログや文字列表現で秘密情報の値の代わりに表示する文字列です。
+const redacted = "REDACTED"
+
+type secretsKey struct{}
+
+// SecretsはAPIキーなど、実行時にノードへ渡す名前付きの秘密情報です。
+// ログや文字列表現では値を伏せ、名前だけを表示します。
+type Secrets map[string]string
+
+// Stringは値を伏せた秘密情報の文字列表現を返します。
+func (s Secrets) String() string {
+	var b strings.Builder
+	b.WriteString("Secrets{")
+	for i, name := range s.names() {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(name + ":" + redacted)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// LogValueはslogで出力する際に値を伏せるためのslog.LogValuerの実装です。
+func (s Secrets) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, len(s))
+	for _, name := range s.names() {
+		attrs = append(attrs, slog.String(name, redacted))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// namesは秘密情報の名前を昇順に返します。
+func (s Secrets) names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// WithSecretsはコンテキストに秘密情報を追加します。
+// 既に設定されている秘密情報は引き継がれ、同じ名前の値はsecretsの値で上書きされます。
+// 呼び出し側のマップは複製されるため、後から変更しても設定した値は変わりません。
+func WithSecrets(ctx context.Context, secrets Secrets) context.Context {
+	existing, _ := ctx.Value(secretsKey{}).(Secrets)
+	merged := make(Secrets, len(existing)+len(secrets))
+	for name, value := range existing {
+		merged[name] = value
+	}
+	for name, value := range secrets {
+		merged[name] = value
+	}
+	return context.WithValue(ctx, secretsKey{}, merged)
+}
+
+// Secretはコンテキストに設定された名前nameの秘密情報を返します。
+// ノードのExecuteの中で、コンストラクタに渡す代わりにAPIキーなどを取り出すために使用します。
+// 設定されていない場合はfalseを返します。
+func Secret(ctx context.Context, name string) (string, bool) {
+	secrets, _ := ctx.Value(secretsKey{}).(Secrets)
+	value, ok := secrets[name]
+	return value, ok
+}
@@ -0,0 +1,55 @@
+package node_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+func TestSecret(t *testing.T) {
+	ctx := node.WithSecrets(context.Background(), node.Secrets{"api_key": "first", "token": "kept"})
+	ctx = node.WithSecrets(ctx, node.Secrets{"api_key": "second"})
+
+	tests := []struct {
+		name          string
+		expectedValue string
+		expectedOK    bool
+	}{
+		{"api_key", "second", true},
+		{"token", "kept", true},
+		{"missing", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := node.Secret(ctx, tt.name)
+			if value != tt.expectedValue || ok != tt.expectedOK {
+				t.Fatalf("expected (%q, %v), got (%q, %v)", tt.expectedValue, tt.expectedOK, value, ok)
+			}
+		})
+	}
+
+	if _, ok := node.Secret(context.Background(), "api_key"); ok {
+		t.Fatal("expected no secret without WithSecrets")
+	}
+}
+
+func TestSecretsRedacted(t *testing.T) {
+	secrets := node.Secrets{"api_key": "sk-very-secret"}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("configured", "secrets", secrets)
+	for _, out := range []string{buf.String(), fmt.Sprint(secrets), fmt.Sprintf("%v", secrets)} {
+		if strings.Contains(out, "sk-very-secret") {
+			t.Fatalf("expected the secret value to be redacted, got %q", out)
+		}
+		if !strings.Contains(out, "api_key") {
+			t.Fatalf("expected the secret name to be shown, got %q", out)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrServiceNotStartedはServiceNodeが開始されていない状態で実行されたことを示すエラーです。
+var ErrServiceNotStarted = errors.New("service not started")
+
+// ServiceNodeは実行をまたいで使い続けるハンドラーに、チャネルを通じてリクエストを送るノードです。
+// Startで起動したワーカーのゴルーチンだけがハンドラーを呼び出すため、接続やセッションのような
+// 並行に使えない状態を持つリソースを、実行ごとに作り直さずに扱えます。
+// DAGは各実行の開始時にStartを、終了時にStopを呼び出します。
+type ServiceNode struct {
+	name    string
+	inputs  []string
+	outputs []string
+	handler func(ctx context.Context, inputs []string) ([]string, error)
+
+	mu       sync.Mutex
+	requests chan serviceRequest // ワーカーへのリクエスト（開始していない場合はnil）
+	done     chan struct{}       // ワーカーが終了すると閉じられる
+}
+
+// serviceRequestはワーカーに送る1回分のリクエストです。
+type serviceRequest struct {
+	ctx    context.Context
+	inputs []string
+	reply  chan serviceResponse
+}
+
+// serviceResponseはワーカーが返す1回分の応答です。
+type serviceResponse struct {
+	outputs []string
+	err     error
+}
+
+// NewServiceNodeは新しいServiceNodeを作成します。
+// handlerはExecuteのたびにワーカーのゴルーチンから呼ばれ、ノードの入力を受け取って出力を返します。
+func NewServiceNode(name string, handler func(ctx context.Context, inputs []string) ([]string, error)) *ServiceNode {
+	return &ServiceNode{name: name, handler: handler}
+}
+
+// Startはリクエストを処理するワーカーのゴルーチンを起動します。
+// ワーカーはStopを呼び出すか、ctxがキャンセルされると終了します。既に開始している場合は何もしません。
+func (n *ServiceNode) Start(ctx context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.requests != nil {
+		return nil
+	}
+	requests := make(chan serviceRequest)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case req, ok := <-requests:
+				if !ok {
+					return
+				}
+				outputs, err := n.handler(req.ctx, req.inputs)
+				req.reply <- serviceResponse{outputs: outputs, err: err}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	n.requests = requests
+	n.done = done
+	return nil
+}
+
+// Stopはワーカーのゴルーチンを終了させ、処理中のリクエストが終わるまで待ちます。開始していない場合は何もしません。
+func (n *ServiceNode) Stop() {
+	n.mu.Lock()
+	requests, done := n.requests, n.done
+	n.requests, n.done = nil, nil
+	n.mu.Unlock()
+	if requests == nil {
+		return
+	}
+	close(requests)
+	<-done
+}
+
+// Executeは入力をワーカーに送り、ハンドラーの応答を出力とします。
+// 開始していない場合はErrServiceNotStartedを返します。
+func (n *ServiceNode) Execute(ctx context.Context) error {
+	n.mu.Lock()
+	requests, done := n.requests, n.done
+	n.mu.Unlock()
+	if requests == nil {
+		return ErrServiceNotStarted
+	}
+
+	reply := make(chan serviceResponse, 1)
+	select {
+	case requests <- serviceRequest{ctx: ctx, inputs: n.inputs, reply: reply}:
+	case <-done:
+		return ErrServiceNotStarted
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case res := <-reply:
+		if res.err != nil {
+			return res.err
+		}
+		n.outputs = res.outputs
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Cloneは同じハンドラーを持つ、開始していない新しいServiceNodeを返します。
+// ハンドラーが持つ状態は複製元と共有されます。
+func (n *ServiceNode) Clone() Node {
+	return &ServiceNode{name: n.name, handler: n.handler}
+}
+
+// Nameはノードの名前を返します。
+func (n *ServiceNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *ServiceNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *ServiceNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力を消去します。ワーカーとハンドラーの状態はそのまま残ります。
+func (n *ServiceNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+}
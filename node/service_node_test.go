@@ -0,0 +1,49 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+func TestServiceNode(t *testing.T) {
+	// セッションを模したハンドラーは、受け取った入力を蓄積して返す
+	var session []string
+	n := node.NewServiceNode("session", func(ctx context.Context, inputs []string) ([]string, error) {
+		session = append(session, inputs...)
+		return []string{strings.Join(session, ",")}, nil
+	})
+
+	n.SetInputs([]string{"a"})
+	if err := n.Execute(context.Background()); !errors.Is(err, node.ErrServiceNotStarted) {
+		t.Fatalf("expected ErrServiceNotStarted before Start, got %v", err)
+	}
+
+	if err := n.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start: %v", err)
+	}
+	for _, tt := range []struct {
+		input    string
+		expected []string
+	}{
+		{"a", []string{"a"}},
+		{"b", []string{"a,b"}},
+	} {
+		n.SetInputs([]string{tt.input})
+		if err := n.Execute(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if outputs := n.GetOutputs(); !slices.Equal(outputs, tt.expected) {
+			t.Fatalf("expected %v, got %v", tt.expected, outputs)
+		}
+	}
+
+	n.Stop()
+	if err := n.Execute(context.Background()); !errors.Is(err, node.ErrServiceNotStarted) {
+		t.Fatalf("expected ErrServiceNotStarted after Stop, got %v", err)
+	}
+}
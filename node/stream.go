@@ -0,0 +1,18 @@
+package node
+
+import "context"
+
+type streamKey struct{}
+
+// WithStreamはノードが出力する部分的な結果（トークンなど）を受け取る関数をコンテキストに設定します。
+func WithStream(ctx context.Context, fn func(chunk string)) context.Context {
+	return context.WithValue(ctx, streamKey{}, fn)
+}
+
+// EmitChunkはコンテキストに設定された関数に部分的な結果を渡します。
+// 受け取る関数が設定されていない場合は何もしません。
+func EmitChunk(ctx context.Context, chunk string) {
+	if fn, ok := ctx.Value(streamKey{}).(func(chunk string)); ok {
+		fn(chunk)
+	}
+}
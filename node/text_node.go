@@ -1,28 +1,52 @@
 package node
 
+import "context"
+
 // TextNodeはテキストを処理するノードです。
 type TextNode struct {
 	name      string
 	inputs    []string
 	outputs   []string
-	processor func([]string) (string, error)
+	processor func([]string) ([]string, error)
 }
 
-// NewTextNodeは新しいTextNodeを作成します。
+// NewTextNodeは新しいTextNodeを作成します。processorの結果が1つの出力になります。
 func NewTextNode(name string, processor func([]string) (string, error)) *TextNode {
+	return &TextNode{name: name, processor: func(inputs []string) ([]string, error) {
+		output, err := processor(inputs)
+		if err != nil {
+			return nil, err
+		}
+		return []string{output}, nil
+	}}
+}
+
+// NewMultiTextNodeはprocessorが複数の出力を返すTextNodeを作成します。
+// 出力は全て依存先のノードに渡されます。
+func NewMultiTextNode(name string, processor func([]string) ([]string, error)) *TextNode {
 	return &TextNode{name: name, processor: processor}
 }
 
 // Executeは入力を処理する関数を使用してテキストを処理します。
-func (n *TextNode) Execute() error {
-	output, err := n.processor(n.inputs)
+func (n *TextNode) Execute(ctx context.Context) error {
+	outputs, err := n.processor(n.inputs)
 	if err != nil {
 		return err
 	}
-	n.outputs = []string{output}
+	n.outputs = outputs
 	return nil
 }
 
+// InputSpecはTextNodeが任意の数の入力を受け取れることを返します。
+func (n *TextNode) InputSpec() (min, max int) {
+	return 0, -1
+}
+
+// Cloneは同じ設定の新しいTextNodeを返します。
+func (n *TextNode) Clone() Node {
+	return &TextNode{name: n.name, processor: n.processor}
+}
+
 // Nameはノードの名前を返します。
 func (n *TextNode) Name() string {
 	return n.name
@@ -37,3 +61,9 @@ func (n *TextNode) SetInputs(inputs []string) {
 func (n *TextNode) GetOutputs() []string {
 	return n.outputs
 }
+
+// Resetはノードの入力と出力を消去します。
+func (n *TextNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+}
@@ -1,7 +1,10 @@
 package node_test
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/momiom/workflow/node"
@@ -31,7 +34,7 @@ func TestTextNode(t *testing.T) {
 			n := node.NewTextNode("textNode", processor)
 			n.SetInputs(tt.inputs)
 
-			err := n.Execute()
+			err := n.Execute(context.Background())
 			if (err != nil) != tt.expectError {
 				t.Fatalf("expected error: %v, got: %v", tt.expectError, err)
 			}
@@ -45,3 +48,49 @@ func TestTextNode(t *testing.T) {
 		})
 	}
 }
+
+func TestMultiTextNode(t *testing.T) {
+	n := node.NewMultiTextNode("split", func(inputs []string) ([]string, error) {
+		return strings.Split(inputs[0], ","), nil
+	})
+	n.SetInputs([]string{"a,b,c"})
+
+	if err := n.Execute(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outputs := n.GetOutputs(); !slices.Equal(outputs, []string{"a", "b", "c"}) {
+		t.Fatalf("expected [a b c], got %v", outputs)
+	}
+}
+
+func TestTextNodeInputSpec(t *testing.T) {
+	n := node.NewTextNode("textNode", func(inputs []string) (string, error) { return "", nil })
+	if min, max := n.InputSpec(); min != 0 || max != -1 {
+		t.Fatalf("expected (0, -1), got (%d, %d)", min, max)
+	}
+}
+
+func TestTextNodeClone(t *testing.T) {
+	n := node.NewTextNode("textNode", func(inputs []string) (string, error) {
+		return inputs[0] + "!", nil
+	})
+	n.SetInputs([]string{"hello"})
+	if err := n.Execute(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clone := n.Clone()
+	if clone.Name() != "textNode" || clone.GetOutputs() != nil {
+		t.Fatalf("expected a fresh node with the same name, got %s with outputs %v", clone.Name(), clone.GetOutputs())
+	}
+	clone.SetInputs([]string{"bye"})
+	if err := clone.Execute(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outputs := clone.GetOutputs(); len(outputs) != 1 || outputs[0] != "bye!" {
+		t.Fatalf("expected [bye!], got %v", outputs)
+	}
+	if outputs := n.GetOutputs(); len(outputs) != 1 || outputs[0] != "hello!" {
+		t.Fatalf("expected original outputs to be unchanged, got %v", outputs)
+	}
+}
@@ -0,0 +1,134 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMaxIterationsはToolLLMNodeがツールの呼び出しの上限回数までに最終的な応答を得られなかったことを示すエラーです。
+var ErrMaxIterations = errors.New("tool call limit reached without a final answer")
+
+// ToolCallはLLMがツールの呼び出しを要求する応答の形式です。
+// LLMの応答がtoolを含むJSONオブジェクトの場合、ToolLLMNodeはその応答をツールの呼び出しとして扱います。
+type ToolCall struct {
+	Tool  string `json:"tool"`  // 呼び出すツールの名前
+	Input string `json:"input"` // ツールに渡す入力
+}
+
+// ToolLLMNodeはLLMの要求に応じてツールとして登録したノードを呼び出し、その結果を踏まえて最終的な応答を返すノードです。
+// LLMの応答がToolCallの形式であればツールを実行してその結果をプロンプトに追加し、再びLLMを呼び出します。
+// ToolCallの形式でない応答が得られた時点で、その応答をノードの出力とします。
+type ToolLLMNode struct {
+	name          string
+	inputs        []string
+	outputs       []string
+	llmClient     LLMClient
+	tools         map[string]Node // ツールの名前ごとのノード
+	maxIterations int             // LLMを呼び出す最大回数
+}
+
+// NewToolLLMNodeは新しいToolLLMNodeを作成します。
+// maxIterationsはLLMを呼び出す最大回数で、1未満の値は1として扱います。
+func NewToolLLMNode(name string, client LLMClient, maxIterations int) *ToolLLMNode {
+	return &ToolLLMNode{
+		name:          name,
+		llmClient:     client,
+		tools:         make(map[string]Node),
+		maxIterations: max(maxIterations, 1),
+	}
+}
+
+// RegisterToolはLLMから名前で呼び出せるツールとしてノードを登録します。
+// ツールのノードはToolCallのInputを1つの入力として実行され、出力を改行で連結したものがLLMに返されます。
+func (n *ToolLLMNode) RegisterTool(name string, tool Node) {
+	n.tools[name] = tool
+}
+
+// Executeは入力を改行で連結したプロンプトでLLMを呼び出し、ツールの呼び出しを繰り返して最終的な応答を得ます。
+// 登録されていないツールが要求された場合や、ツールが失敗した場合はエラーを返します。
+// 上限回数までに最終的な応答が得られなかった場合はErrMaxIterationsを返します。
+func (n *ToolLLMNode) Execute(ctx context.Context) error {
+	if len(n.inputs) == 0 {
+		return fmt.Errorf("input must be at least 1, got 0")
+	}
+	prompt := strings.Join(n.inputs, "\n")
+
+	for range n.maxIterations {
+		response, err := n.llmClient.GenerateResponse(ctx, prompt)
+		if err != nil {
+			return err
+		}
+		call, ok := parseToolCall(response)
+		if !ok {
+			n.outputs = []string{response}
+			return nil
+		}
+
+		result, err := n.callTool(ctx, call)
+		if err != nil {
+			return err
+		}
+		prompt += fmt.Sprintf("\n\nTool %s returned:\n%s", call.Tool, result)
+	}
+	return fmt.Errorf("node %s: %w (%d)", n.name, ErrMaxIterations, n.maxIterations)
+}
+
+// callToolはToolCallで要求されたツールを実行し、その出力を改行で連結した文字列を返します。
+func (n *ToolLLMNode) callTool(ctx context.Context, call ToolCall) (string, error) {
+	tool, ok := n.tools[call.Tool]
+	if !ok {
+		return "", fmt.Errorf("unknown tool %q", call.Tool)
+	}
+	tool.Reset()
+	tool.SetInputs([]string{call.Input})
+	if err := tool.Execute(ctx); err != nil {
+		return "", fmt.Errorf("tool %s: %w", call.Tool, err)
+	}
+	return strings.Join(tool.GetOutputs(), "\n"), nil
+}
+
+// parseToolCallはLLMの応答がツールの呼び出しであればその内容を返します。
+func parseToolCall(response string) (ToolCall, bool) {
+	var call ToolCall
+	if err := json.Unmarshal([]byte(strings.TrimSpace(response)), &call); err != nil || call.Tool == "" {
+		return ToolCall{}, false
+	}
+	return call, true
+}
+
+// Cloneは同じ設定の新しいToolLLMNodeを返します。
+// Clonerを実装するツールのノードは複製し、実装していないノードは複製元と共有します。
+func (n *ToolLLMNode) Clone() Node {
+	tools := make(map[string]Node, len(n.tools))
+	for name, tool := range n.tools {
+		if c, ok := tool.(Cloner); ok {
+			tool = c.Clone()
+		}
+		tools[name] = tool
+	}
+	return &ToolLLMNode{name: n.name, llmClient: n.llmClient, tools: tools, maxIterations: n.maxIterations}
+}
+
+// Nameはノードの名前を返します。
+func (n *ToolLLMNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *ToolLLMNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *ToolLLMNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力を消去します。
+func (n *ToolLLMNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+}
@@ -0,0 +1,86 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+// MockToolLLMClientはtoolを指定した場合に最初の応答でそのツールを要求し、
+// ツールの結果を受け取った後はその結果を含む最終的な応答を返すクライアントです。
+type MockToolLLMClient struct {
+	tool    string
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (c *MockToolLLMClient) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prompts = append(c.prompts, prompt)
+	if c.tool == "loop" || (c.tool != "" && !strings.Contains(prompt, "returned:")) {
+		return `{"tool": "` + c.tool + `", "input": "tokyo"}`, nil
+	}
+	lines := strings.Split(prompt, "\n")
+	return "answer: " + lines[len(lines)-1], nil
+}
+
+func TestToolLLMNode(t *testing.T) {
+	weather := node.NewTextNode("weather", func(inputs []string) (string, error) {
+		return "sunny in " + inputs[0], nil
+	})
+	loop := node.NewIdentityNode("loop")
+	broken := node.NewTextNode("broken", func(inputs []string) (string, error) {
+		return "", errors.New("broken")
+	})
+
+	tests := []struct {
+		name            string
+		tool            string
+		expectedOutputs []string
+		expectedCalls   int
+		expectedErr     error
+	}{
+		{"Answer without tools", "", []string{"answer: what is the weather?"}, 1, nil},
+		{"Call a tool once then answer", "weather", []string{"answer: sunny in tokyo"}, 2, nil},
+		{"Stop at max iterations", "loop", nil, 3, node.ErrMaxIterations},
+		{"Unknown tool", "missing", nil, 1, nil},
+		{"Tool error propagates", "broken", nil, 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &MockToolLLMClient{tool: tt.tool}
+			n := node.NewToolLLMNode("agent", client, 3)
+			n.RegisterTool("weather", weather)
+			n.RegisterTool("loop", loop)
+			n.RegisterTool("broken", broken)
+			n.SetInputs([]string{"what is the weather?"})
+
+			err := n.Execute(context.Background())
+			if tt.expectedOutputs == nil {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if outputs := n.GetOutputs(); !slices.Equal(outputs, tt.expectedOutputs) {
+					t.Fatalf("expected %v, got %v", tt.expectedOutputs, outputs)
+				}
+			}
+			if len(client.prompts) != tt.expectedCalls {
+				t.Fatalf("expected %d LLM calls, got %d: %v", tt.expectedCalls, len(client.prompts), client.prompts)
+			}
+		})
+	}
+}
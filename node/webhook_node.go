@@ -0,0 +1,147 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+)
+
+// SignatureHeaderはWebhookNodeが送信するリクエストの署名を格納するヘッダーです。
+// 値は "sha256=" に続けて、リクエストボディのHMAC-SHA256を16進数で表したものです。
+const SignatureHeader = "X-Signature-256"
+
+// WebhookNodeは入力をWebhookにPOSTし、入力をそのまま出力とするノードです。
+// チェーンの途中に挟んで通知などの副作用を起こす用途を想定しています。
+type WebhookNode struct {
+	name       string
+	inputs     []string
+	outputs    []string
+	client     *http.Client
+	url        string
+	secret     []byte // 署名に使う秘密鍵（nilの場合は署名しない）
+	bestEffort bool   // 送信に失敗してもノードを失敗させないかどうか
+	err        error  // bestEffortの場合の直前の送信のエラー
+}
+
+// webhookPayloadはWebhookに送信するリクエストボディです。
+type webhookPayload struct {
+	Node   string   `json:"node"`
+	Inputs []string `json:"inputs"`
+}
+
+// NewWebhookNodeは新しいWebhookNodeを作成します。clientがnilの場合はhttp.DefaultClientを使用します。
+func NewWebhookNode(name string, client *http.Client, url string) *WebhookNode {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &WebhookNode{name: name, client: client, url: url}
+}
+
+// SetSecretはリクエストボディをsecretで署名し、SignatureHeaderに設定するよう設定します。
+func (n *WebhookNode) SetSecret(secret string) {
+	n.secret = []byte(secret)
+}
+
+// SetBestEffortは送信に失敗してもノードを失敗させないかどうかを設定します。
+// 有効にした場合、送信のエラーはErrで取得できます。
+func (n *WebhookNode) SetBestEffort(bestEffort bool) {
+	n.bestEffort = bestEffort
+}
+
+// Executeは入力をJSONとしてWebhookにPOSTします。
+// 2xx以外のステータスコードはエラーとして扱います。
+// 5xxと送信自体の失敗は再試行可能なエラー（IsRetryable）、それ以外は恒久的なエラーになります。
+func (n *WebhookNode) Execute(ctx context.Context) error {
+	n.err = nil
+	if err := n.post(ctx); err != nil {
+		if !n.bestEffort {
+			return err
+		}
+		n.err = err
+	}
+	n.outputs = slices.Clone(n.inputs)
+	return nil
+}
+
+func (n *WebhookNode) post(ctx context.Context) error {
+	inputs := n.inputs
+	if inputs == nil {
+		inputs = []string{}
+	}
+	body, err := json.Marshal(webhookPayload{Node: n.name, Inputs: inputs})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != nil {
+		req.Header.Set(SignatureHeader, "sha256="+WebhookSignature(n.secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// 接続の失敗などは一時的なものとして再試行を許す
+		return Retryable(fmt.Errorf("failed to send webhook: %w", err))
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 500 {
+		return Retryable(fmt.Errorf("webhook returned status %d", resp.StatusCode))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// WebhookSignatureはbodyをsecretで署名したHMAC-SHA256を16進数で返します。
+// Webhookの受信側で署名を検証する際に使用できます。
+func WebhookSignature(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Errは送信に失敗してもノードを失敗させない設定の場合に、直前の送信のエラーを返します。
+func (n *WebhookNode) Err() error {
+	return n.err
+}
+
+// Cloneは同じ設定の新しいWebhookNodeを返します。
+func (n *WebhookNode) Clone() Node {
+	return &WebhookNode{name: n.name, client: n.client, url: n.url, secret: n.secret, bestEffort: n.bestEffort}
+}
+
+// Nameはノードの名前を返します。
+func (n *WebhookNode) Name() string {
+	return n.name
+}
+
+// SetInputsはノードの入力を設定します。
+func (n *WebhookNode) SetInputs(inputs []string) {
+	n.inputs = inputs
+}
+
+// GetOutputsはノードの出力を返します。
+func (n *WebhookNode) GetOutputs() []string {
+	return n.outputs
+}
+
+// Resetはノードの入力と出力、直前の送信のエラーを消去します。
+func (n *WebhookNode) Reset() {
+	n.inputs = nil
+	n.outputs = nil
+	n.err = nil
+}
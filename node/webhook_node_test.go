@@ -0,0 +1,93 @@
+package node_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+)
+
+func TestWebhookNode(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		secret      string
+		bestEffort  bool
+		expectError bool
+		retryable   bool
+	}{
+		{"Unsigned request", http.StatusOK, "", false, false, false},
+		{"Signed request", http.StatusNoContent, "s3cret", false, false, false},
+		{"Server error is retryable", http.StatusInternalServerError, "", false, true, true},
+		{"Client error is permanent", http.StatusBadRequest, "", false, true, false},
+		{"Server error is ignored in best-effort mode", http.StatusInternalServerError, "", true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			var header http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				header = r.Header.Clone()
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			n := node.NewWebhookNode("notify", server.Client(), server.URL)
+			if tt.secret != "" {
+				n.SetSecret(tt.secret)
+			}
+			n.SetBestEffort(tt.bestEffort)
+			inputs := []string{"hello", "world"}
+			n.SetInputs(inputs)
+
+			err := n.Execute(context.Background())
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if got := node.IsRetryable(err); got != tt.retryable {
+					t.Fatalf("expected retryable %v, got %v (%v)", tt.retryable, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// 入力はそのまま出力される
+			if outputs := n.GetOutputs(); !slices.Equal(outputs, inputs) {
+				t.Fatalf("expected %v, got %v", inputs, outputs)
+			}
+			if tt.bestEffort && n.Err() == nil {
+				t.Fatal("expected recorded error in best-effort mode, got nil")
+			}
+
+			var payload struct {
+				Node   string   `json:"node"`
+				Inputs []string `json:"inputs"`
+			}
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Fatalf("failed to decode body %q: %v", body, err)
+			}
+			if payload.Node != "notify" || !slices.Equal(payload.Inputs, inputs) {
+				t.Fatalf("unexpected payload %+v", payload)
+			}
+
+			signature := header.Get(node.SignatureHeader)
+			if tt.secret == "" {
+				if signature != "" {
+					t.Fatalf("expected no signature, got %q", signature)
+				}
+			} else if expected := "sha256=" + node.WebhookSignature([]byte(tt.secret), body); signature != expected {
+				t.Fatalf("expected signature %q, got %q", expected, signature)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/momiom/workflow/dag"
+)
+
+// Definitionはワークフローの定義です。YAMLやJSONから読み込むことを想定しています。
+type Definition struct {
+	MaxConcurrent int                 `yaml:"max_concurrent" json:"max_concurrent,omitempty"` // 0以下の場合はノード数
+	Nodes         []NodeDefinition    `yaml:"nodes" json:"nodes"`
+	Edges         []EdgeDefinition    `yaml:"edges" json:"edges,omitempty"`
+	Inputs        map[string][]string `yaml:"inputs" json:"inputs,omitempty"` // ノードIDごとの外部入力
+}
+
+// NodeDefinitionはノードの定義です。TypeはRegistryに登録されたノードの種類です。
+type NodeDefinition struct {
+	ID     string `yaml:"id" json:"id"`
+	Type   string `yaml:"type" json:"type"`
+	Params Params `yaml:"params" json:"params,omitempty"`
+}
+
+// EdgeDefinitionはエッジの定義です。
+type EdgeDefinition struct {
+	From string `yaml:"from" json:"from"`
+	To   string `yaml:"to" json:"to"`
+}
+
+// Buildはdefのノードをrで生成し、DAGを構築します。
+// ノードは定義の順に追加され、IDを名前としてFactoryに渡します。optsはDAGの設定に使用されます。
+func (r *Registry) Build(def *Definition, opts ...dag.Option) (*dag.DAG, error) {
+	maxConcurrent := def.MaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = max(len(def.Nodes), 1)
+	}
+
+	workflow := dag.NewDAG(maxConcurrent, opts...)
+	seen := make(map[string]bool, len(def.Nodes))
+	for _, nd := range def.Nodes {
+		if nd.ID == "" {
+			return nil, fmt.Errorf("node of type %s has no id", nd.Type)
+		}
+		if seen[nd.ID] {
+			return nil, fmt.Errorf("node %s is defined more than once", nd.ID)
+		}
+		seen[nd.ID] = true
+
+		n, err := r.Create(nd.Type, nd.ID, nd.Params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create node %s: %w", nd.ID, err)
+		}
+		workflow.AddNode(dag.NodeID(nd.ID), n)
+	}
+	for _, edge := range def.Edges {
+		if err := workflow.AddEdge(dag.NodeID(edge.From), dag.NodeID(edge.To)); err != nil {
+			return nil, err
+		}
+	}
+	return workflow, nil
+}
+
+// DAGInputsは定義の外部入力をDAGの実行に渡す形式で返します。
+func (def *Definition) DAGInputs() map[dag.NodeID][]string {
+	inputs := make(map[dag.NodeID][]string, len(def.Inputs))
+	for id, input := range def.Inputs {
+		inputs[dag.NodeID(id)] = input
+	}
+	return inputs
+}
@@ -0,0 +1,143 @@
+// ノードの種類と生成関数を対応付け、定義からDAGを構築するパッケージ
+package registry
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/momiom/workflow/node"
+)
+
+// Factoryはノードの名前とパラメーターからノードを生成する関数です。
+type Factory func(name string, params Params) (node.Node, error)
+
+// Registryはノードの種類ごとのFactoryを保持します。並行に使用しても安全です。
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// Newは何も登録されていないRegistryを作成します。
+func New() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Defaultは組み込みのノードの種類を登録したRegistryを作成します。
+//
+//   - identity: 入力をそのまま出力する（node.IdentityNode）
+//   - collect: 入力をJSON配列にまとめる（node.CollectNode）
+//   - join: 入力をパラメーター "sep"（既定は空白）で連結する（node.TextNode）
+//   - json_extract: パラメーター "paths" のパスの値を取り出す（node.JSONExtractNode）
+func Default() *Registry {
+	r := New()
+	r.MustRegister("identity", func(name string, params Params) (node.Node, error) {
+		return node.NewIdentityNode(name), nil
+	})
+	r.MustRegister("collect", func(name string, params Params) (node.Node, error) {
+		return node.NewCollectNode(name), nil
+	})
+	r.MustRegister("join", func(name string, params Params) (node.Node, error) {
+		sep, err := params.String("sep", " ")
+		if err != nil {
+			return nil, err
+		}
+		return node.NewTextNode(name, func(inputs []string) (string, error) {
+			return strings.Join(inputs, sep), nil
+		}), nil
+	})
+	r.MustRegister("json_extract", func(name string, params Params) (node.Node, error) {
+		paths, err := params.Strings("paths")
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("param paths must not be empty")
+		}
+		return node.NewJSONExtractNode(name, paths...), nil
+	})
+	return r
+}
+
+// Registerはノードの種類typとそのFactoryを登録します。登録済みの種類の場合はエラーを返します。
+func (r *Registry) Register(typ string, factory Factory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.factories[typ]; exists {
+		return fmt.Errorf("node type %s is already registered", typ)
+	}
+	r.factories[typ] = factory
+	return nil
+}
+
+// MustRegisterはRegisterと同様ですが、エラーの場合はパニックします。初期化時の登録に使用します。
+func (r *Registry) MustRegister(typ string, factory Factory) {
+	if err := r.Register(typ, factory); err != nil {
+		panic(err)
+	}
+}
+
+// Createは種類typのノードを生成します。
+func (r *Registry) Create(typ string, name string, params Params) (node.Node, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[typ]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown node type %s", typ)
+	}
+	return factory(name, params)
+}
+
+// Typesは登録されているノードの種類を昇順に返します。
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]string, 0, len(r.factories))
+	for typ := range r.factories {
+		types = append(types, typ)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// Paramsはノードの生成に使用するパラメーターです。
+// YAMLやJSONから読み込んだ値をそのまま保持するため、取り出す際に型を検証します。
+type Params map[string]any
+
+// Stringはkeyの文字列の値を返します。keyがない場合はfallbackを返します。
+func (p Params) String(key string, fallback string) (string, error) {
+	v, ok := p[key]
+	if !ok {
+		return fallback, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("param %s must be a string, got %T", key, v)
+	}
+	return s, nil
+}
+
+// Stringsはkeyの文字列のリストの値を返します。keyがない場合はnilを返します。
+func (p Params) Strings(key string) ([]string, error) {
+	v, ok := p[key]
+	if !ok {
+		return nil, nil
+	}
+	switch v := v.(type) {
+	case []string:
+		return v, nil
+	case []any:
+		strs := make([]string, 0, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("param %s[%d] must be a string, got %T", key, i, item)
+			}
+			strs = append(strs, s)
+		}
+		return strs, nil
+	default:
+		return nil, fmt.Errorf("param %s must be a list of strings, got %T", key, v)
+	}
+}
@@ -0,0 +1,83 @@
+package registry_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/momiom/workflow/node"
+	"github.com/momiom/workflow/registry"
+)
+
+func TestRegistryBuild(t *testing.T) {
+	def := &registry.Definition{
+		Nodes: []registry.NodeDefinition{
+			{ID: "greet", Type: "join", Params: registry.Params{"sep": "-"}},
+			{ID: "pass", Type: "identity"},
+		},
+		Edges:  []registry.EdgeDefinition{{From: "greet", To: "pass"}},
+		Inputs: map[string][]string{"greet": {"a", "b"}},
+	}
+
+	workflow, err := registry.Default().Build(def)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	events := workflow.GetEvents()
+	go func() {
+		for range events {
+		}
+	}()
+
+	_, finalOutputs, err := workflow.Execute(context.Background(), def.DAGInputs())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(finalOutputs["pass"], []string{"a-b"}) {
+		t.Fatalf("expected [a-b], got %v", finalOutputs["pass"])
+	}
+}
+
+func TestRegistryBuildErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []registry.NodeDefinition
+		edges []registry.EdgeDefinition
+	}{
+		{"Unknown type", []registry.NodeDefinition{{ID: "a", Type: "unknown"}}, nil},
+		{"Missing id", []registry.NodeDefinition{{Type: "identity"}}, nil},
+		{"Duplicate id", []registry.NodeDefinition{{ID: "a", Type: "identity"}, {ID: "a", Type: "collect"}}, nil},
+		{"Invalid param type", []registry.NodeDefinition{{ID: "a", Type: "join", Params: registry.Params{"sep": 1}}}, nil},
+		{"Missing required param", []registry.NodeDefinition{{ID: "a", Type: "json_extract"}}, nil},
+		{"Edge to undefined node", []registry.NodeDefinition{{ID: "a", Type: "identity"}}, []registry.EdgeDefinition{{From: "a", To: "b"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := &registry.Definition{Nodes: tt.nodes, Edges: tt.edges}
+			if _, err := registry.Default().Build(def); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	r := registry.New()
+	factory := func(name string, params registry.Params) (node.Node, error) {
+		return node.NewIdentityNode(name), nil
+	}
+	if err := r.Register("custom", factory); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Register("custom", factory); err == nil {
+		t.Fatal("expected error for duplicate registration, got nil")
+	}
+	if types := r.Types(); !slices.Equal(types, []string{"custom"}) {
+		t.Fatalf("expected [custom], got %v", types)
+	}
+	n, err := r.Create("custom", "c", nil)
+	if err != nil || n.Name() != "c" {
+		t.Fatalf("expected node named c, got %v, %v", n, err)
+	}
+}